@@ -0,0 +1,29 @@
+package alert
+
+import (
+	"time"
+
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// Record 问题的生命周期记录，ID 与问题 ID 相同，跨扫描保持不变
+type Record struct {
+	ID          string           `json:"id" bson:"id"`
+	State       finding.State    `json:"state" bson:"state"`
+	Finding     *finding.Finding `json:"finding" bson:"finding"` // 最近一次检出时的快照
+	FirstSeen   time.Time        `json:"firstSeen" bson:"firstSeen"`
+	LastSeen    time.Time        `json:"lastSeen" bson:"lastSeen"`
+	ResolvedAt  time.Time        `json:"resolvedAt,omitempty" bson:"resolvedAt,omitempty"`
+	Occurrences int              `json:"occurrences" bson:"occurrences"`
+	AckedBy     string           `json:"ackedBy,omitempty" bson:"ackedBy,omitempty"`
+	AckedAt     time.Time        `json:"ackedAt,omitempty" bson:"ackedAt,omitempty"`
+	AckNote     string           `json:"ackNote,omitempty" bson:"ackNote,omitempty"`
+	UpdatedAt   time.Time        `json:"updatedAt" bson:"updatedAt"`
+}
+
+// snapshot 返回带当前状态的问题副本，用于通知
+func (r *Record) snapshot() *finding.Finding {
+	f := *r.Finding
+	f.State = r.State
+	return &f
+}
@@ -0,0 +1,87 @@
+package alert
+
+import (
+	"fmt"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/xidp/protocols"
+	"github.com/xid-protocol/xidp/xdb"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const PathAlert = "/protocols/external-attack-surface/alert"
+
+// 默认的告警状态集合
+const defaultCollection = "attack_surface_alert"
+
+// Store 生命周期记录存储
+type Store interface {
+	List() ([]*Record, error)
+	Get(id string) (*Record, error)
+	Put(r *Record) error
+}
+
+// MongoStore 每条记录为一个 XID，状态同时写入 metadata.extra
+type MongoStore struct {
+	*cloud.Store
+}
+
+// NewMongoStoreFromViper 集合名读取 Alert.collection
+func NewMongoStoreFromViper() *MongoStore {
+	return &MongoStore{Store: cloud.NewStore(cloud.FirstString(viper.GetString("Alert.collection"), defaultCollection))}
+}
+
+func (s *MongoStore) List() ([]*Record, error) {
+	records, err := s.ListByPath(PathAlert)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathAlert, err)
+	}
+	out := make([]*Record, 0, len(records))
+	for _, x := range records {
+		r, err := decode(cloud.PayloadOf(x))
+		if err != nil {
+			logx.Errorf("decode alert record error: %v", err)
+			continue
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// Get 不存在时返回 nil
+func (s *MongoStore) Get(id string) (*Record, error) {
+	x, err := s.DBClient.GetByXid(s.Ctx, PathAlert, protocols.GenerateXid(id))
+	if err == xdb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decode(cloud.PayloadOf(x))
+}
+
+func (s *MongoStore) Put(r *Record) error {
+	x := cloud.NewAttackSurfaceXID(r.ID, "alert", PathAlert, r)
+	x.Metadata.Extra = map[string]any{"state": string(r.State)}
+	return s.DBClient.Upsert(s.Ctx, x)
+}
+
+func decode(payload map[string]interface{}) (*Record, error) {
+	if payload == nil {
+		return nil, fmt.Errorf("empty payload")
+	}
+	b, err := bson.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var r Record
+	if err := bson.Unmarshal(b, &r); err != nil {
+		return nil, err
+	}
+	if r.Finding == nil {
+		return nil, fmt.Errorf("alert %s has no finding", r.ID)
+	}
+	return &r, nil
+}
@@ -0,0 +1,163 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/colin-404/logx"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+var (
+	ErrNotFound = errors.New("alert not found")
+	ErrResolved = errors.New("alert is already resolved")
+)
+
+// Tracker 对比本次检出与历史记录推进生命周期：
+// 首次出现为 new，再次出现转为 open（已确认的保持 acknowledged），
+// 本次未检出的标记为 resolved，已解决后再次出现为 reopened。
+// 已豁免的问题只刷新检出时间，不改变状态。
+type Tracker struct {
+	Store Store
+}
+
+// NewTrackerFromViper 使用 Alert.collection 中的记录
+func NewTrackerFromViper() *Tracker {
+	return &Tracker{Store: NewMongoStoreFromViper()}
+}
+
+// Coverage 本次扫描未完成的部分，对应的问题没有检出不代表已解决
+type Coverage struct {
+	// FailedProviders 采集失败的云厂商，其历史问题本次不标记为已解决
+	FailedProviders []string
+	// FailedStages 执行失败的分析阶段（如 geoip、policy），任一失败时本次不标记任何问题为已解决
+	FailedStages []string
+}
+
+// Complete 全部厂商与阶段均成功完成
+func (c Coverage) Complete() bool {
+	return len(c.FailedProviders) == 0 && len(c.FailedStages) == 0
+}
+
+// resolvable 记录是否可以因本次未检出而标记为已解决
+func (c Coverage) resolvable(r *Record) bool {
+	if len(c.FailedStages) > 0 {
+		return false
+	}
+	if r.Finding == nil {
+		return true
+	}
+	for _, p := range c.FailedProviders {
+		if p == r.Finding.Provider {
+			return false
+		}
+	}
+	return true
+}
+
+// Reconcile 更新生命周期记录并返回状态发生变化、需要通知的问题（new、reopened、resolved）。
+// 本次问题的 State 字段会被填充。coverage 中采集失败的厂商与阶段所涉及的记录保持原状态，
+// 避免漏采的问题被误判为已解决、下次扫描又重新打开。
+func (t *Tracker) Reconcile(findings []*finding.Finding, coverage Coverage, now time.Time) ([]*finding.Finding, error) {
+	records, err := t.Store.List()
+	if err != nil {
+		return nil, err
+	}
+	now = now.UTC()
+	byID := make(map[string]*Record, len(records))
+	for _, r := range records {
+		byID[r.ID] = r
+	}
+
+	var changed []*finding.Finding
+	seen := map[string]bool{}
+	for _, f := range findings {
+		if seen[f.ID] {
+			continue
+		}
+		seen[f.ID] = true
+		r := byID[f.ID]
+		if f.Status == finding.StatusWaived {
+			if r != nil && r.State != finding.StateResolved {
+				r.LastSeen = now
+				f.State = r.State
+				t.put(r, f, now)
+			}
+			continue
+		}
+		switch {
+		case r == nil:
+			r = &Record{ID: f.ID, State: finding.StateNew, FirstSeen: now}
+		case r.State == finding.StateResolved:
+			r.State = finding.StateReopened
+			r.ResolvedAt = time.Time{}
+			r.AckedBy, r.AckedAt, r.AckNote = "", time.Time{}, ""
+		case r.State == finding.StateNew || r.State == finding.StateReopened:
+			r.State = finding.StateOpen
+		}
+		r.LastSeen = now
+		r.Occurrences++
+		f.State = r.State
+		t.put(r, f, now)
+		if r.State == finding.StateNew || r.State == finding.StateReopened {
+			changed = append(changed, r.snapshot())
+		}
+	}
+
+	var skipped int
+	for _, r := range records {
+		if seen[r.ID] || r.State == finding.StateResolved {
+			continue
+		}
+		if !coverage.resolvable(r) {
+			skipped++
+			continue
+		}
+		r.State = finding.StateResolved
+		r.ResolvedAt = now
+		t.put(r, r.Finding, now)
+		changed = append(changed, r.snapshot())
+	}
+	counts := map[finding.State]int{}
+	for _, f := range changed {
+		counts[f.State]++
+	}
+	logx.Infof("alert lifecycle: new=%d, reopened=%d, resolved=%d, unresolved due to incomplete scan=%d",
+		counts[finding.StateNew], counts[finding.StateReopened], counts[finding.StateResolved], skipped)
+	if !coverage.Complete() {
+		logx.Warnf("incomplete scan: failed providers=%v, failed stages=%v", coverage.FailedProviders, coverage.FailedStages)
+	}
+	return changed, nil
+}
+
+// Acknowledge 人工确认问题，确认后再次检出不再转为 open，直到解决后重新出现
+func (t *Tracker) Acknowledge(id, by, note string) (*finding.Finding, error) {
+	r, err := t.Store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if r.State == finding.StateResolved {
+		return nil, fmt.Errorf("%w: %s", ErrResolved, id)
+	}
+	now := time.Now().UTC()
+	r.State = finding.StateAcknowledged
+	r.AckedBy, r.AckedAt, r.AckNote = by, now, note
+	r.UpdatedAt = now
+	if err := t.Store.Put(r); err != nil {
+		return nil, err
+	}
+	return r.snapshot(), nil
+}
+
+// put 保存记录，失败只记录日志，不影响本次其余问题
+func (t *Tracker) put(r *Record, f *finding.Finding, now time.Time) {
+	r.Finding = f
+	r.UpdatedAt = now
+	if err := t.Store.Put(r); err != nil {
+		logx.Errorf("save alert %s error: %v", r.ID, err)
+	}
+}
@@ -0,0 +1,127 @@
+package aliyun
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 各产品 RPC 接口版本
+const (
+	versionECS = "2014-05-26"
+	versionVPC = "2016-04-28"
+	versionSLB = "2014-05-15"
+)
+
+// Client 阿里云 RPC 风格 API 客户端，使用 HMAC-SHA1 签名
+type Client struct {
+	AccessKeyID     string
+	AccessKeySecret string
+	// Endpoints 产品 -> endpoint，支持 {region} 占位符，便于指向本地桩服务
+	Endpoints  map[string]string
+	HTTPClient *http.Client
+}
+
+// APIError 接口返回的错误信息
+type APIError struct {
+	Code      string `json:"Code"`
+	Message   string `json:"Message"`
+	RequestID string `json:"RequestId"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("aliyun api error: %s: %s (request %s)", e.Code, e.Message, e.RequestID)
+}
+
+// Call 调用 product 的 action，结果解析到 out
+func (c *Client) Call(product, version, action, region string, params map[string]string, out interface{}) error {
+	endpoint, ok := c.Endpoints[product]
+	if !ok {
+		return fmt.Errorf("aliyun endpoint for %s is not configured", product)
+	}
+	endpoint = strings.ReplaceAll(endpoint, "{region}", region)
+
+	query := map[string]string{
+		"Format":           "JSON",
+		"Version":          version,
+		"Action":           action,
+		"AccessKeyId":      c.AccessKeyID,
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureVersion": "1.0",
+		"SignatureNonce":   nonce(),
+		"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	if region != "" {
+		query["RegionId"] = region
+	}
+	for k, v := range params {
+		query[k] = v
+	}
+	query["Signature"] = Sign(http.MethodGet, query, c.AccessKeySecret)
+
+	values := url.Values{}
+	for k, v := range query {
+		values.Set(k, v)
+	}
+	resp, err := c.HTTPClient.Get(strings.TrimRight(endpoint, "/") + "/?" + values.Encode())
+	if err != nil {
+		return fmt.Errorf("aliyun %s %s: %w", product, action, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("aliyun %s %s: %w", product, action, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{}
+		if json.Unmarshal(body, apiErr) == nil && apiErr.Code != "" {
+			return apiErr
+		}
+		return fmt.Errorf("aliyun %s %s: http %d", product, action, resp.StatusCode)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// Sign 计算 RPC 签名：对排序后的参数做规范化编码，再以 AccessKeySecret& 为密钥 HMAC-SHA1
+func Sign(method string, params map[string]string, secret string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if k != "Signature" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, percentEncode(k)+"="+percentEncode(params[k]))
+	}
+	stringToSign := method + "&" + percentEncode("/") + "&" + percentEncode(strings.Join(pairs, "&"))
+
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// percentEncode RFC 3986 编码，空格为 %20、* 为 %2A、~ 不编码
+func percentEncode(s string) string {
+	s = url.QueryEscape(s)
+	s = strings.ReplaceAll(s, "+", "%20")
+	s = strings.ReplaceAll(s, "*", "%2A")
+	return strings.ReplaceAll(s, "%7E", "~")
+}
+
+func nonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
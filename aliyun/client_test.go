@@ -0,0 +1,147 @@
+package aliyun
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 阿里云 RPC 签名文档中 DescribeRegions 的示例
+func TestSignDocumentedExample(t *testing.T) {
+	params := map[string]string{
+		"AccessKeyId":      "testid",
+		"Action":           "DescribeRegions",
+		"Format":           "XML",
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureNonce":   "3ee8c1b8-83d3-44af-a94f-4e0ad82fd6cf",
+		"SignatureVersion": "1.0",
+		"Timestamp":        "2016-02-23T12:46:24Z",
+		"Version":          "2014-05-26",
+	}
+	if got, want := Sign(http.MethodGet, params, "testsecret"), "OLeaidS1JvxuMvnyHOwuJ+uX5qY="; got != want {
+		t.Fatalf("Sign() = %s, want %s", got, want)
+	}
+	// 已有的 Signature 参数不参与签名
+	params["Signature"] = "ignored"
+	if got := Sign(http.MethodGet, params, "testsecret"); got != "OLeaidS1JvxuMvnyHOwuJ+uX5qY=" {
+		t.Fatalf("Sign() with Signature param = %s", got)
+	}
+}
+
+func TestPercentEncode(t *testing.T) {
+	for in, want := range map[string]string{
+		"a b":                  "a%20b",
+		"a*b":                  "a%2Ab",
+		"a~b":                  "a~b",
+		"2016-02-23T12:46:24Z": "2016-02-23T12%3A46%3A24Z",
+	} {
+		if got := percentEncode(in); got != want {
+			t.Errorf("percentEncode(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// stub 校验签名并按 Action 分发的本地桩服务
+func stub(t *testing.T, handle func(action string, q map[string]string) (int, string)) *AliyunCloud {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := map[string]string{}
+		for k, v := range r.URL.Query() {
+			q[k] = v[0]
+		}
+		if want := Sign(http.MethodGet, q, "secret"); q["Signature"] != want {
+			t.Errorf("%s: signature %s, want %s", q["Action"], q["Signature"], want)
+		}
+		if q["AccessKeyId"] != "key" || q["RegionId"] != "cn-hangzhou" {
+			t.Errorf("%s: unexpected common params %v", q["Action"], q)
+		}
+		status, body := handle(q["Action"], q)
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return &AliyunCloud{
+		client: &Client{
+			AccessKeyID:     "key",
+			AccessKeySecret: "secret",
+			Endpoints:       map[string]string{"ecs": srv.URL, "vpc": srv.URL, "slb": srv.URL},
+			HTTPClient:      &http.Client{Timeout: 5 * time.Second},
+		},
+		regions: []string{"cn-hangzhou"},
+	}
+}
+
+func TestGetInstancesPaginates(t *testing.T) {
+	const total = 150
+	var pages []string
+	c := stub(t, func(action string, q map[string]string) (int, string) {
+		switch action {
+		case "DescribeInstances":
+			pages = append(pages, q["PageNumber"])
+			page, _ := strconv.Atoi(q["PageNumber"])
+			var items []string
+			for i := (page - 1) * pageSize; i < min(page*pageSize, total); i++ {
+				items = append(items, fmt.Sprintf(`{"InstanceId":"i-%d","PublicIpAddress":{"IpAddress":["1.2.3.%d"]},
+					"VpcAttributes":{"PrivateIpAddress":{"IpAddress":["10.0.0.%d"]}},"SecurityGroupIds":{"SecurityGroupId":["sg-1"]}}`, i, i%250, i%250))
+			}
+			return http.StatusOK, fmt.Sprintf(`{"TotalCount":%d,"Instances":{"Instance":[%s]}}`, total, strings.Join(items, ","))
+		case "DescribeSecurityGroupAttribute":
+			return http.StatusOK, `{"SecurityGroupId":"sg-1","Permissions":{"Permission":[
+				{"IpProtocol":"TCP","PortRange":"22/22","SourceCidrIp":"0.0.0.0/0","Policy":"Accept","Priority":"1","Direction":"ingress"}]}}`
+		}
+		t.Errorf("unexpected action %s", action)
+		return http.StatusBadRequest, `{}`
+	})
+
+	assets, err := c.GetInstances("cn-hangzhou")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != total {
+		t.Fatalf("got %d instances, want %d", len(assets), total)
+	}
+	if strings.Join(pages, ",") != "1,2" {
+		t.Fatalf("requested pages %v, want 1,2", pages)
+	}
+	a := assets[0]
+	if !a.Public || len(a.Rules) != 1 || a.Rules[0].FromPort != 22 || a.Rules[0].Protocol != "tcp" {
+		t.Fatalf("unexpected asset %+v", a)
+	}
+}
+
+func TestCallAPIError(t *testing.T) {
+	c := stub(t, func(string, map[string]string) (int, string) {
+		return http.StatusForbidden, `{"Code":"InvalidAccessKeyId.NotFound","Message":"Specified access key is not found.","RequestId":"req-1"}`
+	})
+	_, err := c.GetInstances("cn-hangzhou")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error %v is not an APIError", err)
+	}
+	if apiErr.Code != "InvalidAccessKeyId.NotFound" || apiErr.RequestID != "req-1" {
+		t.Fatalf("unexpected api error %+v", apiErr)
+	}
+}
+
+func TestCallHTTPError(t *testing.T) {
+	c := stub(t, func(string, map[string]string) (int, string) {
+		return http.StatusBadGateway, "bad gateway"
+	})
+	_, err := c.GetInstances("cn-hangzhou")
+	var apiErr *APIError
+	if err == nil || errors.As(err, &apiErr) || !strings.Contains(err.Error(), "http 502") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestCallUnknownProduct(t *testing.T) {
+	c := &Client{Endpoints: map[string]string{}}
+	if err := c.Call("rds", "2014-08-15", "DescribeDBInstances", "cn-hangzhou", nil, nil); err == nil {
+		t.Fatal("expected error for unconfigured endpoint")
+	}
+}
@@ -0,0 +1,110 @@
+package aliyun
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/xidp/protocols"
+)
+
+const ProviderName = "aliyun"
+
+const PathInstanceAttackSurface = "/protocols/external-attack-surface/aliyun-instance"
+
+// 默认使用中心 endpoint，RegionId 通过参数传递
+var defaultEndpoints = map[string]string{
+	"ecs": "https://ecs.aliyuncs.com",
+	"vpc": "https://vpc.aliyuncs.com",
+	"slb": "https://slb.aliyuncs.com",
+}
+
+type AliyunCloud struct {
+	client  *Client
+	regions []string
+}
+
+func init() {
+	cloud.Register(ProviderName, func() cloud.CloudProvider { return NewAliyunCloud() })
+}
+
+// NewAliyunCloud 从 Aliyun 配置段读取凭证、地域与 endpoint
+func NewAliyunCloud() *AliyunCloud {
+	endpoints := map[string]string{}
+	for product, endpoint := range defaultEndpoints {
+		endpoints[product] = endpoint
+		if v := viper.GetString("Aliyun.endpoints." + product); v != "" {
+			endpoints[product] = v
+		}
+	}
+	return &AliyunCloud{
+		client: &Client{
+			AccessKeyID:     viper.GetString("Aliyun.access_key_id"),
+			AccessKeySecret: viper.GetString("Aliyun.access_key_secret"),
+			Endpoints:       endpoints,
+			HTTPClient:      &http.Client{Timeout: 30 * time.Second},
+		},
+		regions: viper.GetStringSlice("Aliyun.regions"),
+	}
+}
+
+func (c *AliyunCloud) Name() string {
+	return ProviderName
+}
+
+// ListAssets 按地域返回 ECS 实例、SLB 与未绑定的 EIP
+func (c *AliyunCloud) ListAssets() ([]*cloud.Asset, error) {
+	var out []*cloud.Asset
+	for _, region := range c.regions {
+		instances, err := c.GetInstances(region)
+		if err != nil {
+			return nil, err
+		}
+		lbs, err := c.GetLoadBalancers(region)
+		if err != nil {
+			return nil, err
+		}
+		eips, err := c.GetUnboundEIPs(region)
+		if err != nil {
+			return nil, err
+		}
+		var exposed int
+		for _, group := range [][]*cloud.Asset{instances, lbs, eips} {
+			for _, a := range group {
+				if cloud.IsWideExposure(a.Exposure) {
+					exposed++
+				}
+			}
+			out = append(out, group...)
+		}
+		logx.Infof("aliyun attack surface summary: region=%s, instances=%d, slb=%d, eips=%d, exposed=%d",
+			region, len(instances), len(lbs), len(eips), exposed)
+	}
+	return out, nil
+}
+
+func (c *AliyunCloud) PublicIPs() (map[string][]string, error) {
+	assets, err := c.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.PublicIPsOf(assets), nil
+}
+
+func (c *AliyunCloud) FirewallRules() (map[string][]cloud.Rule, error) {
+	assets, err := c.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.RulesOf(assets), nil
+}
+
+func (c *AliyunCloud) Collect() ([]*protocols.XID, error) {
+	assets, err := c.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.AssetXIDs(assets, PathInstanceAttackSurface), nil
+}
@@ -0,0 +1,228 @@
+package aliyun
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+const pageSize = 100
+
+// flexInt 兼容接口中以字符串或数字返回的整数
+type flexInt int
+
+var _ json.Unmarshaler = (*flexInt)(nil)
+
+func (f *flexInt) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*f = 0
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*f = flexInt(n)
+	return nil
+}
+
+type ipList struct {
+	IpAddress []string `json:"IpAddress"`
+}
+
+type ecsInstance struct {
+	InstanceId      string `json:"InstanceId"`
+	InstanceName    string `json:"InstanceName"`
+	RegionId        string `json:"RegionId"`
+	Status          string `json:"Status"`
+	PublicIpAddress ipList `json:"PublicIpAddress"`
+	InnerIpAddress  ipList `json:"InnerIpAddress"`
+	EipAddress      struct {
+		IpAddress string `json:"IpAddress"`
+	} `json:"EipAddress"`
+	VpcAttributes struct {
+		VpcId            string `json:"VpcId"`
+		VSwitchId        string `json:"VSwitchId"`
+		PrivateIpAddress ipList `json:"PrivateIpAddress"`
+	} `json:"VpcAttributes"`
+	SecurityGroupIds struct {
+		SecurityGroupId []string `json:"SecurityGroupId"`
+	} `json:"SecurityGroupIds"`
+	Tags struct {
+		Tag []struct {
+			TagKey   string `json:"TagKey"`
+			TagValue string `json:"TagValue"`
+		} `json:"Tag"`
+	} `json:"Tags"`
+}
+
+type describeInstancesResponse struct {
+	TotalCount int `json:"TotalCount"`
+	Instances  struct {
+		Instance []ecsInstance `json:"Instance"`
+	} `json:"Instances"`
+}
+
+type permission struct {
+	IpProtocol       string  `json:"IpProtocol"`
+	PortRange        string  `json:"PortRange"`
+	SourceCidrIp     string  `json:"SourceCidrIp"`
+	Ipv6SourceCidrIp string  `json:"Ipv6SourceCidrIp"`
+	SourceGroupId    string  `json:"SourceGroupId"`
+	Policy           string  `json:"Policy"`
+	Priority         flexInt `json:"Priority"`
+	Direction        string  `json:"Direction"`
+}
+
+type describeSecurityGroupAttributeResponse struct {
+	SecurityGroupId string `json:"SecurityGroupId"`
+	Permissions     struct {
+		Permission []permission `json:"Permission"`
+	} `json:"Permissions"`
+}
+
+// GetInstances 拉取地域内的 ECS 实例并展开其安全组规则
+func (c *AliyunCloud) GetInstances(region string) ([]*cloud.Asset, error) {
+	var instances []ecsInstance
+	for page := 1; ; page++ {
+		var resp describeInstancesResponse
+		err := c.client.Call("ecs", versionECS, "DescribeInstances", region, map[string]string{
+			"PageNumber": strconv.Itoa(page),
+			"PageSize":   strconv.Itoa(pageSize),
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, resp.Instances.Instance...)
+		if len(resp.Instances.Instance) < pageSize || len(instances) >= resp.TotalCount {
+			break
+		}
+	}
+
+	// 安全组成员的内网地址，用于展开授权给安全组的规则
+	members := map[string][]string{}
+	for _, inst := range instances {
+		for _, sg := range inst.SecurityGroupIds.SecurityGroupId {
+			for _, ip := range privateIPs(inst) {
+				members[sg] = cloud.AppendUnique(members[sg], ip)
+			}
+		}
+	}
+
+	rules := map[string][]cloud.Rule{}
+	var out []*cloud.Asset
+	for _, inst := range instances {
+		as := &cloud.Asset{
+			Provider:     ProviderName,
+			InstanceID:   inst.InstanceId,
+			InstanceName: inst.InstanceName,
+			ResourceType: "ecs",
+			Region:       cloud.FirstString(inst.RegionId, region),
+			VpcID:        inst.VpcAttributes.VpcId,
+			SubnetID:     inst.VpcAttributes.VSwitchId,
+			Tags:         map[string]string{},
+			PrivateIPs:   privateIPs(inst),
+			GroupIDs:     inst.SecurityGroupIds.SecurityGroupId,
+		}
+		for _, t := range inst.Tags.Tag {
+			as.Tags[t.TagKey] = t.TagValue
+		}
+		for _, ip := range inst.PublicIpAddress.IpAddress {
+			as.PublicIPs = cloud.AppendUnique(as.PublicIPs, ip)
+		}
+		if ip := inst.EipAddress.IpAddress; ip != "" {
+			as.PublicIPs = cloud.AppendUnique(as.PublicIPs, ip)
+		}
+		sort.Strings(as.PublicIPs)
+		as.Public = len(as.PublicIPs) > 0
+
+		for _, sg := range as.GroupIDs {
+			if _, ok := rules[sg]; !ok {
+				r, err := c.securityGroupRules(region, sg, members)
+				if err != nil {
+					return nil, err
+				}
+				rules[sg] = r
+			}
+			as.Rules = append(as.Rules, rules[sg]...)
+		}
+		as.Evaluate()
+		out = append(out, as)
+	}
+	return out, nil
+}
+
+// securityGroupRules 解析入方向规则，优先级 1-100 数值越小越优先，同优先级拒绝优先
+func (c *AliyunCloud) securityGroupRules(region, groupID string, members map[string][]string) ([]cloud.Rule, error) {
+	var resp describeSecurityGroupAttributeResponse
+	err := c.client.Call("ecs", versionECS, "DescribeSecurityGroupAttribute", region, map[string]string{
+		"SecurityGroupId": groupID,
+		"Direction":       "ingress",
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []cloud.PolicyEntry
+	for _, p := range resp.Permissions.Permission {
+		if p.Direction != "" && !strings.EqualFold(p.Direction, "ingress") {
+			continue
+		}
+		from, to := parsePortRange(p.PortRange)
+		e := cloud.PolicyEntry{
+			Priority: int(p.Priority),
+			Allow:    !strings.EqualFold(p.Policy, "Drop"),
+			Protocol: normalizeProtocol(p.IpProtocol),
+			FromPort: from,
+			ToPort:   to,
+		}
+		for _, cidr := range []string{p.SourceCidrIp, p.Ipv6SourceCidrIp} {
+			if cidr != "" {
+				e.Iprange = append(e.Iprange, cidr)
+			}
+		}
+		if p.SourceGroupId != "" {
+			ips, ok := members[p.SourceGroupId]
+			e.SourceGroups = append(e.SourceGroups, cloud.GroupReference{
+				GroupID:  p.SourceGroupId,
+				Members:  []string{},
+				IPs:      append([]string{}, ips...),
+				Resolved: ok,
+			})
+		}
+		entries = append(entries, e)
+	}
+	return cloud.OrderedRules(groupID, entries), nil
+}
+
+func privateIPs(inst ecsInstance) []string {
+	var out []string
+	for _, ip := range append(inst.VpcAttributes.PrivateIpAddress.IpAddress, inst.InnerIpAddress.IpAddress...) {
+		out = cloud.AppendUnique(out, ip)
+	}
+	return out
+}
+
+// parsePortRange 解析 "22/22"，-1/-1 表示全部端口
+func parsePortRange(s string) (int, int) {
+	lo, hi, _ := strings.Cut(s, "/")
+	from, err1 := strconv.Atoi(lo)
+	to, err2 := strconv.Atoi(hi)
+	if err1 != nil || err2 != nil || from < 0 || to < 0 {
+		return 0, 65535
+	}
+	return from, to
+}
+
+func normalizeProtocol(p string) string {
+	switch strings.ToLower(p) {
+	case "", "all":
+		return "-1"
+	default:
+		return strings.ToLower(p)
+	}
+}
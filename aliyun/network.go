@@ -0,0 +1,148 @@
+package aliyun
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+type describeEipAddressesResponse struct {
+	TotalCount   int `json:"TotalCount"`
+	EipAddresses struct {
+		EipAddress []struct {
+			AllocationId string `json:"AllocationId"`
+			IpAddress    string `json:"IpAddress"`
+			Name         string `json:"Name"`
+			RegionId     string `json:"RegionId"`
+			Status       string `json:"Status"`
+			InstanceId   string `json:"InstanceId"`
+		} `json:"EipAddress"`
+	} `json:"EipAddresses"`
+}
+
+type slbLoadBalancer struct {
+	LoadBalancerId   string `json:"LoadBalancerId"`
+	LoadBalancerName string `json:"LoadBalancerName"`
+	Address          string `json:"Address"`
+	AddressType      string `json:"AddressType"`
+	VpcId            string `json:"VpcId"`
+	VSwitchId        string `json:"VSwitchId"`
+	RegionId         string `json:"RegionId"`
+}
+
+type describeLoadBalancersResponse struct {
+	TotalCount    int `json:"TotalCount"`
+	LoadBalancers struct {
+		LoadBalancer []slbLoadBalancer `json:"LoadBalancer"`
+	} `json:"LoadBalancers"`
+}
+
+type describeLoadBalancerAttributeResponse struct {
+	ListenerPortsAndProtocol struct {
+		ListenerPortAndProtocol []struct {
+			ListenerPort     flexInt `json:"ListenerPort"`
+			ListenerProtocol string  `json:"ListenerProtocol"`
+		} `json:"ListenerPortAndProtocol"`
+	} `json:"ListenerPortsAndProtocol"`
+}
+
+// GetUnboundEIPs 返回未绑定实例的 EIP，已绑定的地址随实例输出
+func (c *AliyunCloud) GetUnboundEIPs(region string) ([]*cloud.Asset, error) {
+	var out []*cloud.Asset
+	for page, total := 1, 0; ; page++ {
+		var resp describeEipAddressesResponse
+		err := c.client.Call("vpc", versionVPC, "DescribeEipAddresses", region, map[string]string{
+			"PageNumber": strconv.Itoa(page),
+			"PageSize":   strconv.Itoa(pageSize),
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		for _, eip := range resp.EipAddresses.EipAddress {
+			if !strings.EqualFold(eip.Status, "Available") || eip.InstanceId != "" {
+				continue
+			}
+			out = append(out, &cloud.Asset{
+				Provider:     ProviderName,
+				InstanceID:   eip.AllocationId,
+				InstanceName: eip.Name,
+				ResourceType: "eip",
+				Region:       cloud.FirstString(eip.RegionId, region),
+				Tags:         map[string]string{},
+				PublicIPs:    []string{eip.IpAddress},
+				Public:       true,
+				Exposure:     cloud.ExposureNone,
+			})
+		}
+		total += len(resp.EipAddresses.EipAddress)
+		if len(resp.EipAddresses.EipAddress) < pageSize || total >= resp.TotalCount {
+			break
+		}
+	}
+	return out, nil
+}
+
+// GetLoadBalancers 返回 SLB 实例，公网类型的监听端口对任意来源开放
+func (c *AliyunCloud) GetLoadBalancers(region string) ([]*cloud.Asset, error) {
+	var lbs []slbLoadBalancer
+	for page := 1; ; page++ {
+		var resp describeLoadBalancersResponse
+		err := c.client.Call("slb", versionSLB, "DescribeLoadBalancers", region, map[string]string{
+			"PageNumber": strconv.Itoa(page),
+			"PageSize":   strconv.Itoa(pageSize),
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		lbs = append(lbs, resp.LoadBalancers.LoadBalancer...)
+		if len(resp.LoadBalancers.LoadBalancer) < pageSize || len(lbs) >= resp.TotalCount {
+			break
+		}
+	}
+
+	out := make([]*cloud.Asset, 0, len(lbs))
+	for _, lb := range lbs {
+		as := &cloud.Asset{
+			Provider:     ProviderName,
+			InstanceID:   lb.LoadBalancerId,
+			InstanceName: lb.LoadBalancerName,
+			ResourceType: "slb",
+			Region:       cloud.FirstString(lb.RegionId, region),
+			VpcID:        lb.VpcId,
+			SubnetID:     lb.VSwitchId,
+			Tags:         map[string]string{},
+			Public:       strings.EqualFold(lb.AddressType, "internet"),
+		}
+		if as.Public {
+			as.PublicIPs = []string{lb.Address}
+		} else {
+			as.PrivateIPs = []string{lb.Address}
+		}
+
+		var attr describeLoadBalancerAttributeResponse
+		err := c.client.Call("slb", versionSLB, "DescribeLoadBalancerAttribute", region, map[string]string{
+			"LoadBalancerId": lb.LoadBalancerId,
+		}, &attr)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range attr.ListenerPortsAndProtocol.ListenerPortAndProtocol {
+			proto := "tcp"
+			if strings.EqualFold(l.ListenerProtocol, "udp") {
+				proto = "udp"
+			}
+			as.Rules = append(as.Rules, cloud.Rule{
+				GroupID:  lb.LoadBalancerId,
+				FromPort: int(l.ListenerPort),
+				ToPort:   int(l.ListenerPort),
+				Protocol: proto,
+				Iprange:  []string{cloud.ExposureIprange},
+				Exposure: cloud.ExposureInternet,
+			})
+		}
+		as.Evaluate()
+		out = append(out, as)
+	}
+	return out, nil
+}
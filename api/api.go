@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xid-protocol/attack-surface/alert"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+	"github.com/xid-protocol/attack-surface/query"
+)
+
+// Snapshot 最近一次扫描的资产与问题，扫描结束后整体替换
+type Snapshot struct {
+	mu        sync.RWMutex
+	assets    []*cloud.Asset
+	findings  []*finding.Finding
+	updatedAt time.Time
+}
+
+func (s *Snapshot) Set(assets []*cloud.Asset, findings []*finding.Finding) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.assets, s.findings, s.updatedAt = assets, findings, time.Now().UTC()
+}
+
+// RegisterRouter 注册查询接口，均支持 filter 与 group_by 参数，例如
+//
+//	GET /api/attack-surface/assets?filter=tag:env=prod AND team:payments
+//	GET /api/attack-surface/groups?filter=port:22&group_by=account
+func RegisterRouter(r gin.IRouter, s *Snapshot) {
+	g := r.Group("/api/attack-surface")
+	g.GET("/assets", s.handle(func(res *query.Result) interface{} { return res.Assets }, true))
+	g.GET("/findings", s.handle(func(res *query.Result) interface{} { return res.Findings }, true))
+	g.GET("/groups", s.handle(func(res *query.Result) interface{} { return res.Groups }, false))
+}
+
+// Acknowledger 人工确认告警，确认后的状态变化同样需要通知
+type Acknowledger interface {
+	Acknowledge(id, by, note string) (*finding.Finding, error)
+}
+
+// RegisterAlertRouter 注册告警确认接口，id 为问题 ID
+//
+//	POST /api/attack-surface/findings/:id/ack {"by": "alice", "note": "tracked in SEC-123"}
+func RegisterAlertRouter(r gin.IRouter, ack Acknowledger) {
+	r.POST("/api/attack-surface/findings/:id/ack", func(c *gin.Context) {
+		var req struct {
+			By   string `json:"by"`
+			Note string `json:"note"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || req.By == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "by is required"})
+			return
+		}
+		f, err := ack.Acknowledge(c.Param("id"), req.By, req.Note)
+		switch {
+		case errors.Is(err, alert.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, alert.ErrResolved):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusOK, f)
+		}
+	})
+}
+
+// handle 返回 pick 选出的列表，withGroups 时附带分组汇总
+func (s *Snapshot) handle(pick func(*query.Result) interface{}, withGroups bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		f, err := query.Parse(c.Query("filter"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		s.mu.RLock()
+		res, err := query.Run(f, c.Query("group_by"), s.assets, s.findings)
+		updatedAt := s.updatedAt
+		s.mu.RUnlock()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		body := gin.H{
+			"filter":    res.Filter,
+			"groupBy":   res.GroupBy,
+			"updatedAt": updatedAt,
+			"items":     pick(res),
+		}
+		if withGroups && res.GroupBy != "" {
+			body["groups"] = res.Groups
+		}
+		c.JSON(http.StatusOK, body)
+	}
+}
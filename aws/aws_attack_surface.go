@@ -1,148 +1,134 @@
 package aws
 
-// import (
-// 	"context"
-// 	"encoding/json"
-// 	"fmt"
-// 	"regexp"
-
-// 	"github.com/tidwall/gjson"
-// )
-
-// const ExposureIprange = "0.0.0.0/0"
-
-// type Rule struct {
-// 	FromPort int      `json:"fromPort"`
-// 	ToPort   int      `json:"toPort"`
-// 	Protocol string   `json:"protocol"`
-// 	Iprange  []string `json:"iprange"`
-// }
-
-// // path /protocols/external-attack-surface/aws-instance
-// type AWSAttackSurface struct {
-// 	InstanceID   string            `json:"instanceId"`
-// 	InstanceName string            `json:"instanceName"`
-// 	Tags         map[string]string `json:"tags"`
-// 	PublicIPs    []string          `json:"publicIps"`
-// 	PrivateIPs   []string          `json:"privateIps"`
-// 	Rules        []Rule            `json:"rules"`
-// }
-
-// func NewAWSAttackSurface(id string) *AWSAttackSurface {
-// 	var awsEas AWSAttackSurface
-// 	ctx := context.Background()
-
-// 	secgroupXID, err := repository.FindOneByXidAndPath(ctx, id, "/info/aws/secgroup")
-// 	if err != nil {
-// 		return nil
-// 	}
-
-// 	// logx.Infof("secgroupXID: %v", secgroupXID)
-
-// 	instanceXid, err := repository.FindOneByXidAndPath(ctx, id, "/info/aws/instance")
-// 	if err != nil {
-// 		return nil
-// 	}
-// 	jsonBytes, _ := json.Marshal(instanceXid.Payload)
-// 	jsonStr := string(jsonBytes)
-
-// 	// InstanceID
-// 	awsEas.InstanceID = gjson.Get(jsonStr, "#(Key==instanceid).Value").String()
-
-// 	// Tags
-// 	awsEas.Tags = make(map[string]string)
-// 	tagArr := gjson.Get(jsonStr, `#(Key=="tags").Value`).Array()
-// 	for _, tagKVList := range tagArr { // 每个 tagKVList 仍是一个数组
-// 		var k, v string
-// 		for _, kv := range tagKVList.Array() { // kv 形如 {"Key":"key","Value":"org"}
-// 			if kv.Get("Key").String() == "key" {
-// 				k = kv.Get("Value").String()
-// 			} else if kv.Get("Key").String() == "value" {
-// 				v = kv.Get("Value").String()
-// 			}
-// 		}
-// 		if k != "" { // 填入结果
-// 			awsEas.Tags[k] = v
-// 		}
-// 	}
-
-// 	// InstanceName
-// 	awsEas.InstanceName = gjson.Get(jsonStr, `#(Key=="Name").Value`).String()
-
-// 	// ---------- 正则提取公网 IP ----------
-// 	// 匹配 "Key":"publicip" ... "Value":"xxx"
-// 	re := regexp.MustCompile(`"Key"\s*:\s*"publicip"[\s\S]*?"Value"\s*:\s*"([^"]+)"`)
-// 	matches := re.FindAllStringSubmatch(jsonStr, -1)
-// 	seen := make(map[string]struct{})
-// 	for _, m := range matches {
-// 		if len(m) >= 2 {
-// 			ip := m[1]
-// 			if _, ok := seen[ip]; !ok {
-// 				awsEas.PublicIPs = append(awsEas.PublicIPs, ip)
-// 				seen[ip] = struct{}{}
-// 			}
-// 		}
-// 	}
-
-// 	// ---------- 正则提取私网 IP ----------
-// 	// 匹配 "Key":"privateip" ... "Value":"xxx"
-// 	re = regexp.MustCompile(`"Key"\s*:\s*"privateipaddress"[\s\S]*?"Value"\s*:\s*"([^"]+)"`)
-// 	matches = re.FindAllStringSubmatch(jsonStr, -1)
-// 	seen = make(map[string]struct{})
-// 	for _, m := range matches {
-// 		if len(m) >= 2 {
-// 			ip := m[1]
-// 			if _, ok := seen[ip]; !ok {
-// 				awsEas.PrivateIPs = append(awsEas.PrivateIPs, ip)
-// 				seen[ip] = struct{}{}
-// 			}
-// 		}
-// 	}
-
-// 	//---------------------secgroup---------------------
-
-// 	secgroupJsonBytes, err := json.Marshal(secgroupXID.Payload)
-// 	if err != nil {
-// 		fmt.Println(err)
-// 		return nil
-// 	}
-// 	secgroupJsonStr := string(secgroupJsonBytes)
-// 	// fmt.Println(secgroupJsonStr)
-
-// 	// ----------- 提取所有安全组规则 -------------
-// 	securitygroups := gjson.Get(secgroupJsonStr, `0.#(Key=="securitygroups").Value`).Array()
-// 	fmt.Println(securitygroups)
-// 	var allRules []Rule
-
-// 	for _, sg := range securitygroups {
-// 		// 每个 sg 仍是一个 Key-Value 数组，需要再次过滤
-// 		ipPerms := sg.Get(`#(Key=="ippermissions").Value`).Array()
-// 		for _, perm := range ipPerms {
-// 			from := int(perm.Get(`#(Key=="fromport").Value`).Int())
-// 			to := int(perm.Get(`#(Key=="toport").Value`).Int())
-// 			proto := perm.Get(`#(Key=="ipprotocol").Value`).String()
-
-// 			var cidrs []string
-// 			// ipranges 可能为 null
-// 			ipranges := perm.Get(`#(Key=="ipranges").Value`).Array()
-// 			for _, iprange := range ipranges {
-// 				iprangeStr := iprange.Get(`#(Key=="cidrip").Value`).String()
-// 				cidrs = append(cidrs, iprangeStr)
-// 			}
-// 			allRules = append(allRules, Rule{
-// 				FromPort: from,
-// 				ToPort:   to,
-// 				Protocol: proto,
-// 				Iprange:  cidrs,
-// 			})
-
-// 		}
-// 	}
-
-// 	awsEas.Rules = allRules
-
-// 	return &awsEas
-// }
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/colin-404/logx"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// 采集器写入 aws_info 的 EC2 资源路径
+const (
+	PathInstance = "/info/aws/instance"
+
+	PathInstanceAttackSurface = "/protocols/external-attack-surface/aws-instance"
+)
+
+const ResourceEC2 = "ec2"
+
+// GetInstanceAttackSurfaces 关联 EC2 实例与安全组，生成实例级暴露记录
+func (c *AWSCloud) GetInstanceAttackSurfaces() ([]*cloud.Asset, error) {
+	sgs, err := c.loadSecurityGroups()
+	if err != nil {
+		return nil, err
+	}
+	records, err := c.ListByPath(PathInstance)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathInstance, err)
+	}
+
+	network, err := c.loadNetworkIndex()
+	if err != nil {
+		return nil, err
+	}
+	if network.empty() {
+		logx.Warnf("no subnet/nacl/route table records, exposure is evaluated on security groups only")
+	}
+
+	out := make([]*cloud.Asset, 0, len(records))
+	for _, record := range records {
+		payload := cloud.PayloadOf(record)
+		if payload == nil {
+			continue
+		}
+		as := parseInstance(payload, sgs)
+		if as == nil {
+			continue
+		}
+		if !network.empty() {
+			as.Reachability = network.evaluate(as)
+			as.Evaluate()
+		}
+		out = append(out, as)
+	}
+	logx.Infof("instance attack surface summary: instances=%d, securityGroups=%d", len(out), sgs.Len())
+	return out, nil
+}
+
+func parseInstance(payload map[string]interface{}, sgs *SecurityGroups) *cloud.Asset {
+	id := cloud.String(payload, "instanceId")
+	if id == "" {
+		return nil
+	}
+	if strings.EqualFold(cloud.String(cloud.Map(payload, "state"), "name"), "terminated") {
+		return nil
+	}
+	as := &cloud.Asset{
+		InstanceID:   id,
+		Provider:     ProviderName,
+		ResourceType: ResourceEC2,
+		Region:       regionFromAZ(cloud.String(cloud.Map(payload, "placement"), "availabilityZone")),
+		VpcID:        cloud.String(payload, "vpcId"),
+		SubnetID:     cloud.String(payload, "subnetId"),
+		Endpoint:     cloud.String(payload, "publicDnsName"),
+		Tags:         cloud.Tags(cloud.AnyCase(payload, "tags")),
+	}
+	as.InstanceName = as.Tags["Name"]
+	as.Account = cloud.String(payload, "ownerId")
+	for _, nic := range cloud.Maps(payload, "networkInterfaces") {
+		as.Account = cloud.FirstString(as.Account, cloud.String(nic, "ownerId"))
+	}
+
+	as.PublicIPs = extractPublicIPs(bson.M(payload))
+	sort.Strings(as.PublicIPs)
+	as.Public = cloud.HasPublicAddress(as.PublicIPs)
+
+	if ip := cloud.String(payload, "privateIpAddress"); ip != "" {
+		as.PrivateIPs = cloud.AppendUnique(as.PrivateIPs, ip)
+	}
+	for _, g := range cloud.Maps(payload, "securityGroups") {
+		as.GroupIDs = cloud.AppendUnique(as.GroupIDs, cloud.String(g, "groupId"))
+	}
+	for _, nic := range cloud.Maps(payload, "networkInterfaces") {
+		for _, pi := range cloud.Maps(nic, "privateIpAddresses") {
+			if ip := cloud.String(pi, "privateIpAddress"); ip != "" {
+				as.PrivateIPs = cloud.AppendUnique(as.PrivateIPs, ip)
+			}
+		}
+		for _, v6 := range cloud.Maps(nic, "ipv6Addresses") {
+			if ip := cloud.String(v6, "ipv6Address"); ip != "" {
+				as.IPv6s = cloud.AppendUnique(as.IPv6s, ip)
+			}
+		}
+		for _, g := range cloud.Maps(nic, "groups") {
+			as.GroupIDs = cloud.AppendUnique(as.GroupIDs, cloud.String(g, "groupId"))
+		}
+	}
+
+	as.Rules = sgs.Rules(as.GroupIDs, nil)
+	as.Evaluate()
+	return as
+}
+
+// regionFromARN 从 arn:aws:service:region:account:resource 中取出区域
+func regionFromARN(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return ""
+	}
+	return parts[3]
+}
+
+// regionFromAZ 从可用区（如 us-east-1a）推出区域
+func regionFromAZ(az string) string {
+	if len(az) > 1 && az[len(az)-1] >= 'a' && az[len(az)-1] <= 'z' {
+		return az[:len(az)-1]
+	}
+	return az
+}
 
 // func GetSgByID(securityGroupID *string, ec2Cli *ec2.EC2) *ec2.DescribeSecurityGroupsOutput {
 
@@ -0,0 +1,324 @@
+package aws
+
+import (
+	"fmt"
+	"math"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/query"
+)
+
+// CIS AWS Foundations Benchmark 网络相关控制项
+const (
+	CISNACLAdminPorts   = "5.1"
+	CISSGAdminPortsIPv4 = "5.2"
+	CISSGAdminPortsIPv6 = "5.3"
+	CISDefaultSG        = "5.4"
+	CISIMDSv2           = "5.6"
+)
+
+// 检查结果
+const (
+	CISPass          = "PASS"
+	CISFail          = "FAIL"
+	CISNotApplicable = "N/A"
+)
+
+// 默认的远程管理端口
+var defaultAdminPorts = []int{22, 3389}
+
+type CISControl struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+var cisControls = []CISControl{
+	{CISNACLAdminPorts, "Ensure no Network ACLs allow ingress from 0.0.0.0/0 to remote server administration ports"},
+	{CISSGAdminPortsIPv4, "Ensure no security groups allow ingress from 0.0.0.0/0 to remote server administration ports"},
+	{CISSGAdminPortsIPv6, "Ensure no security groups allow ingress from ::/0 to remote server administration ports"},
+	{CISDefaultSG, "Ensure the default security group of every VPC restricts all traffic"},
+	{CISIMDSv2, "Ensure that EC2 Metadata Service only allows IMDSv2"},
+}
+
+// CISResult 单个资源在单个控制项上的结果
+type CISResult struct {
+	Control      string `json:"control"`
+	Account      string `json:"account"`
+	ResourceID   string `json:"resourceId"`
+	ResourceType string `json:"resourceType"`
+	VpcID        string `json:"vpcId,omitempty"`
+	Status       string `json:"status"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// CISControlSummary 控制项在账号内的通过情况，有任一资源失败即为 FAIL
+type CISControlSummary struct {
+	ID     string `json:"id"`
+	Passed int    `json:"passed"`
+	Failed int    `json:"failed"`
+	Status string `json:"status"`
+}
+
+// CISAccountSummary 账号级合规汇总，Compliance 为通过的控制项占比
+type CISAccountSummary struct {
+	Account    string              `json:"account"`
+	Passed     int                 `json:"passed"`
+	Failed     int                 `json:"failed"`
+	Compliance float64             `json:"compliance"`
+	Controls   []CISControlSummary `json:"controls"`
+}
+
+// CISReport 网络控制项报告
+type CISReport struct {
+	Benchmark   string              `json:"benchmark"`
+	AdminPorts  []int               `json:"adminPorts"`
+	Query       string              `json:"query,omitempty"`
+	GeneratedAt time.Time           `json:"generatedAt"`
+	Controls    []CISControl        `json:"controls"`
+	Accounts    []CISAccountSummary `json:"accounts"`
+	Results     []CISResult         `json:"results"`
+}
+
+// CISNetworkReport 基于已采集的安全组、NACL 与实例评估 CIS 5.x 网络控制项
+// 管理端口读取 CIS.admin_ports，默认 22、3389
+func (c *AWSCloud) CISNetworkReport() (*CISReport, error) {
+	ports := viper.GetIntSlice("CIS.admin_ports")
+	if len(ports) == 0 {
+		ports = defaultAdminPorts
+	}
+	sgs, err := c.loadSecurityGroups()
+	if err != nil {
+		return nil, err
+	}
+	nacls, err := c.ListByPath(PathNACL)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathNACL, err)
+	}
+	instances, err := c.ListByPath(PathInstance)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathInstance, err)
+	}
+
+	report := &CISReport{
+		Benchmark:   "CIS Amazon Web Services Foundations Benchmark - Networking",
+		AdminPorts:  ports,
+		GeneratedAt: time.Now().UTC(),
+		Controls:    cisControls,
+	}
+	for _, record := range nacls {
+		if acl := cloud.PayloadOf(record); cloud.String(acl, "networkAclId") != "" {
+			report.Results = append(report.Results, checkNACL(acl, ports))
+		}
+	}
+	for _, id := range sortedGroupIDs(sgs) {
+		report.Results = append(report.Results, checkSecurityGroup(sgs, id, ports)...)
+	}
+	for _, record := range instances {
+		if r, ok := checkIMDS(cloud.PayloadOf(record)); ok {
+			report.Results = append(report.Results, r)
+		}
+	}
+	report.Accounts = summarize(report.Results)
+
+	var failed int
+	for _, r := range report.Results {
+		if r.Status == CISFail {
+			failed++
+		}
+	}
+	logx.Infof("cis network summary: accounts=%d, results=%d, failed=%d", len(report.Accounts), len(report.Results), failed)
+	return report, nil
+}
+
+// checkNACL 5.1 入方向允许 0.0.0.0/0 或 ::/0 访问管理端口，且未被更小规则号的 deny 屏蔽
+func checkNACL(acl map[string]interface{}, ports []int) CISResult {
+	r := CISResult{
+		Control:      CISNACLAdminPorts,
+		Account:      cloud.FirstString(cloud.String(acl, "ownerId"), "unknown"),
+		ResourceID:   cloud.String(acl, "networkAclId"),
+		ResourceType: "nacl",
+		VpcID:        cloud.String(acl, "vpcId"),
+		Status:       CISPass,
+	}
+	inbound, _ := naclEntries(acl)
+	var open []string
+	for _, cidr := range []string{cloud.ExposureIprange, cloud.ExposureIpv6Iprange} {
+		src := netip.MustParsePrefix(cidr)
+		for _, p := range ports {
+			for _, allowed := range naclAllowed(inbound, "6", p, p, src) {
+				if allowed.Bits() == 0 {
+					open = cloud.AppendUnique(open, fmt.Sprintf("%d from %s", p, cidr))
+				}
+			}
+		}
+	}
+	if len(open) > 0 {
+		r.Status = CISFail
+		r.Reason = "inbound allows " + strings.Join(open, ", ")
+	}
+	return r
+}
+
+// checkSecurityGroup 5.2/5.3 管理端口对 0.0.0.0/0、::/0 开放；默认安全组额外检查 5.4
+func checkSecurityGroup(sgs *SecurityGroups, id string, ports []int) []CISResult {
+	sg := sgs.groups[id]
+	base := CISResult{
+		Account:      cloud.FirstString(cloud.String(sg, "ownerId"), "unknown"),
+		ResourceID:   id,
+		ResourceType: "security-group",
+		VpcID:        cloud.String(sg, "vpcId"),
+		Status:       CISPass,
+	}
+	v4, v6 := base, base
+	v4.Control, v6.Control = CISSGAdminPortsIPv4, CISSGAdminPortsIPv6
+	var open4, open6 []string
+	for _, rule := range sgs.IngressRules(id) {
+		if p := strings.ToLower(rule.Protocol); p != "-1" && p != "tcp" && p != "6" {
+			continue
+		}
+		for _, port := range ports {
+			if !rule.Covers(port) {
+				continue
+			}
+			for _, cidr := range rule.Iprange {
+				switch cidr {
+				case cloud.ExposureIprange:
+					open4 = cloud.AppendUnique(open4, fmt.Sprint(port))
+				case cloud.ExposureIpv6Iprange:
+					open6 = cloud.AppendUnique(open6, fmt.Sprint(port))
+				}
+			}
+		}
+	}
+	if len(open4) > 0 {
+		v4.Status, v4.Reason = CISFail, "ports "+strings.Join(open4, ",")+" open to 0.0.0.0/0"
+	}
+	if len(open6) > 0 {
+		v6.Status, v6.Reason = CISFail, "ports "+strings.Join(open6, ",")+" open to ::/0"
+	}
+	out := []CISResult{v4, v6}
+
+	if cloud.String(sg, "groupName") == "default" {
+		d := base
+		d.Control = CISDefaultSG
+		in, eg := len(cloud.Maps(sg, "ipPermissions")), len(cloud.Maps(sg, "ipPermissionsEgress"))
+		if in > 0 || eg > 0 {
+			d.Status, d.Reason = CISFail, fmt.Sprintf("default security group has %d inbound and %d outbound rules", in, eg)
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// checkIMDS 5.6 运行中的实例需要 httpTokens=required
+func checkIMDS(payload map[string]interface{}) (CISResult, bool) {
+	id := cloud.String(payload, "instanceId")
+	if id == "" || strings.EqualFold(cloud.String(cloud.Map(payload, "state"), "name"), "terminated") {
+		return CISResult{}, false
+	}
+	account := cloud.String(payload, "ownerId")
+	for _, nic := range cloud.Maps(payload, "networkInterfaces") {
+		account = cloud.FirstString(account, cloud.String(nic, "ownerId"))
+	}
+	r := CISResult{
+		Control:      CISIMDSv2,
+		Account:      cloud.FirstString(account, "unknown"),
+		ResourceID:   id,
+		ResourceType: ResourceEC2,
+		VpcID:        cloud.String(payload, "vpcId"),
+		Status:       CISPass,
+	}
+	opts := cloud.Map(payload, "metadataOptions")
+	if cloud.String(opts, "httpEndpoint") == "disabled" {
+		return r, true
+	}
+	if tokens := cloud.String(opts, "httpTokens"); tokens != "required" {
+		r.Status, r.Reason = CISFail, "httpTokens is "+cloud.FirstString(tokens, "not set")
+	}
+	return r, true
+}
+
+// Filter 只保留满足表达式的结果并重新汇总，可用字段为 account、vpc、id、type、status（PASS/FAIL）与 rule（控制项编号）
+func (r *CISReport) Filter(f *query.Filter) {
+	if f.String() == "" {
+		return
+	}
+	var kept []CISResult
+	for _, res := range r.Results {
+		rec := &query.Record{
+			Provider:     ProviderName,
+			Account:      res.Account,
+			VpcID:        res.VpcID,
+			ID:           res.ResourceID,
+			ResourceType: res.ResourceType,
+			Rule:         res.Control,
+			Status:       res.Status,
+		}
+		if f.Match(rec) {
+			kept = append(kept, res)
+		}
+	}
+	r.Query, r.Results, r.Accounts = f.String(), kept, summarize(kept)
+}
+
+// summarize 按账号汇总各控制项
+func summarize(results []CISResult) []CISAccountSummary {
+	byAccount := map[string]map[string]*CISControlSummary{}
+	for _, r := range results {
+		controls, ok := byAccount[r.Account]
+		if !ok {
+			controls = map[string]*CISControlSummary{}
+			byAccount[r.Account] = controls
+		}
+		cs, ok := controls[r.Control]
+		if !ok {
+			cs = &CISControlSummary{ID: r.Control, Status: CISPass}
+			controls[r.Control] = cs
+		}
+		if r.Status == CISFail {
+			cs.Failed++
+			cs.Status = CISFail
+		} else {
+			cs.Passed++
+		}
+	}
+	var out []CISAccountSummary
+	for account, controls := range byAccount {
+		s := CISAccountSummary{Account: account}
+		for _, c := range cisControls {
+			cs, ok := controls[c.ID]
+			if !ok {
+				// 账号下没有该控制项对应的资源
+				s.Controls = append(s.Controls, CISControlSummary{ID: c.ID, Status: CISNotApplicable})
+				continue
+			}
+			s.Controls = append(s.Controls, *cs)
+			if cs.Status == CISPass {
+				s.Passed++
+			} else {
+				s.Failed++
+			}
+		}
+		if total := s.Passed + s.Failed; total > 0 {
+			s.Compliance = math.Round(float64(s.Passed)*10000/float64(total)) / 100
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Account < out[j].Account })
+	return out
+}
+
+func sortedGroupIDs(sgs *SecurityGroups) []string {
+	ids := make([]string, 0, len(sgs.groups))
+	for id := range sgs.groups {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var cisTemplate = template.Must(template.New("cis").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Benchmark}}</title>
+<style>
+body { font-family: -apple-system, Helvetica, Arial, sans-serif; margin: 24px; color: #222; }
+table { border-collapse: collapse; margin-bottom: 24px; }
+th, td { border: 1px solid #ddd; padding: 4px 10px; text-align: left; font-size: 13px; }
+th { background: #f4f4f4; }
+.PASS { color: #1a7f37; font-weight: bold; }
+.FAIL { color: #cf222e; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>{{.Benchmark}}</h1>
+<p>Generated at {{.GeneratedAt.Format "2006-01-02 15:04:05 MST"}}, administration ports {{range $i, $p := .AdminPorts}}{{if $i}}, {{end}}{{$p}}{{end}}{{if .Query}}, filter <code>{{.Query}}</code>{{end}}</p>
+<h2>Controls</h2>
+<table>
+<tr><th>ID</th><th>Title</th></tr>
+{{range .Controls}}<tr><td>{{.ID}}</td><td>{{.Title}}</td></tr>
+{{end}}</table>
+<h2>Accounts</h2>
+<table>
+<tr><th>Account</th><th>Compliance</th><th>Passed controls</th><th>Failed controls</th>{{range .Controls}}<th>{{.ID}}</th>{{end}}</tr>
+{{range .Accounts}}<tr><td>{{.Account}}</td><td>{{printf "%.2f" .Compliance}}%</td><td>{{.Passed}}</td><td>{{.Failed}}</td>{{range .Controls}}<td class="{{.Status}}">{{.Status}} ({{.Failed}}/{{.Passed}})</td>{{end}}</tr>
+{{end}}</table>
+<h2>Results</h2>
+<table>
+<tr><th>Control</th><th>Account</th><th>Resource</th><th>Type</th><th>VPC</th><th>Status</th><th>Reason</th></tr>
+{{range .Results}}<tr><td>{{.Control}}</td><td>{{.Account}}</td><td>{{.ResourceID}}</td><td>{{.ResourceType}}</td><td>{{.VpcID}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{.Reason}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// WriteJSON 输出 JSON 报告
+func (r *CISReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteHTML 输出 HTML 报告
+func (r *CISReport) WriteHTML(w io.Writer) error {
+	return cisTemplate.Execute(w, r)
+}
+
+// Export 在目录下写入 cis-aws-network.json 与 cis-aws-network.html
+func (r *CISReport) Export(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for name, write := range map[string]func(io.Writer) error{
+		"cis-aws-network.json": r.WriteJSON,
+		"cis-aws-network.html": r.WriteHTML,
+	} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		err = write(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
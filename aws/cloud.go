@@ -1,41 +1,29 @@
 package aws
 
 import (
-	"context"
 	"log"
-	"time"
 
-	"github.com/spf13/viper"
+	"github.com/colin-404/logx"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
 	"github.com/xid-protocol/xidp/protocols"
 	"github.com/xid-protocol/xidp/xdb"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const ProviderName = "aws"
+
 type AWSCloud struct {
-	Ctx      context.Context
-	DBClient *xdb.Client
+	*cloud.Store
 }
 
-func NewAWSCloud() *AWSCloud {
-	ctx := context.Background()
-
-	// 1) 连接 Mongo 并获取集合
-	mongoURI := viper.GetString("mongodb.uri")
-	mc, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
-	if err != nil {
-		log.Fatal(err)
-	}
-	db := mc.Database(viper.GetString("mongodb.database"))
-	col := db.Collection("aws_info")
+func init() {
+	cloud.Register(ProviderName, func() cloud.CloudProvider { return NewAWSCloud() })
+}
 
-	client := xdb.NewClientWithMongo(col, &xdb.ClientOptions{
-		EnableIdempotency: true,
-		DefaultTimeout:    2 * time.Second,
-	})
+func NewAWSCloud() *AWSCloud {
+	// 连接 Mongo 并获取 aws_info 集合
 	return &AWSCloud{
-		Ctx:      ctx,
-		DBClient: client,
+		Store: cloud.NewStore("aws_info"),
 	}
 }
 
@@ -71,3 +59,68 @@ func (c *AWSCloud) GetAllEC2Info() []*protocols.XID {
 	return result
 
 }
+
+func (c *AWSCloud) Name() string {
+	return ProviderName
+}
+
+// ListAssets 返回 EC2 实例与托管服务的暴露记录
+func (c *AWSCloud) ListAssets() ([]*cloud.Asset, error) {
+	instances, err := c.GetInstanceAttackSurfaces()
+	if err != nil {
+		return nil, err
+	}
+	managed, err := c.GetManagedEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	return append(instances, managed...), nil
+}
+
+func (c *AWSCloud) PublicIPs() (map[string][]string, error) {
+	assets, err := c.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.PublicIPsOf(assets), nil
+}
+
+func (c *AWSCloud) FirewallRules() (map[string][]cloud.Rule, error) {
+	assets, err := c.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.RulesOf(assets), nil
+}
+
+// Collect 汇总实例、托管服务、serverless 入口与 CloudFront 分发的攻击面 XID，
+// 未鉴权入口、可绕过 CDN 的源站等采集阶段即可判定的问题以问题 XID 一并返回
+func (c *AWSCloud) Collect() ([]*protocols.XID, error) {
+	instances, err := c.GetInstanceAttackSurfaces()
+	if err != nil {
+		return nil, err
+	}
+	managed, err := c.GetManagedEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := c.GetServerlessEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	cdns, err := c.GetCloudFrontSurfaces(instances)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*protocols.XID
+	out = append(out, cloud.AssetXIDs(instances, PathInstanceAttackSurface)...)
+	out = append(out, cloud.AssetXIDs(managed, PathManagedAttackSurface)...)
+	out = append(out, cloud.AssetXIDs(EndpointAssets(endpoints), PathEndpointAttackSurface)...)
+	out = append(out, finding.XIDs(EndpointFindings(endpoints))...)
+	out = append(out, cloud.AssetXIDs(CDNAssets(cdns), PathCDNAttackSurface)...)
+	out = append(out, finding.XIDs(CDNFindings(cdns))...)
+	logx.Infof("aws attack surface: instances=%d, managed=%d, endpoints=%d, cloudfront=%d",
+		len(instances), len(managed), len(endpoints), len(cdns))
+	return out, nil
+}
@@ -0,0 +1,383 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/colin-404/logx"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// 采集器写入 aws_info 的 CDN 及源站路径
+const (
+	PathCloudFront = "/info/aws/cloudfront"
+	PathELB        = "/info/aws/elb"
+	PathS3         = "/info/aws/s3"
+
+	PathCDNAttackSurface = "/protocols/external-attack-surface/aws-cloudfront"
+)
+
+// 源站类型
+const (
+	OriginS3     = "s3"
+	OriginEC2    = "ec2"
+	OriginELB    = "elb"
+	OriginCustom = "custom"
+)
+
+const ResourceCloudFront = "cloudfront"
+
+// RuleCDNOriginBypass 源站可绕过 CloudFront 直接从公网访问
+const RuleCDNOriginBypass = "cdn-origin-bypass"
+
+// CDNAttackSurface CloudFront 分发及源站的解析结果，转为 Asset 后写入
+// path /protocols/external-attack-surface/aws-cloudfront
+type CDNAttackSurface struct {
+	DistributionID       string      `json:"distributionId"`
+	ARN                  string      `json:"arn"`
+	DomainName           string      `json:"domainName"`
+	Aliases              []string    `json:"aliases"`
+	Enabled              bool        `json:"enabled"`
+	ViewerProtocolPolicy string      `json:"viewerProtocolPolicy"`
+	WebACLID             string      `json:"webAclId"`
+	WAFEnabled           bool        `json:"wafEnabled"`
+	Origins              []CDNOrigin `json:"origins"`
+	// BypassableOrigins 可绕过 CDN 直接访问的源站数量
+	BypassableOrigins int `json:"bypassableOrigins"`
+}
+
+type CDNOrigin struct {
+	ID              string         `json:"id"`
+	DomainName      string         `json:"domainName"`
+	OriginType      string         `json:"originType"`
+	ResourceID      string         `json:"resourceId,omitempty"`
+	ProtocolPolicy  string         `json:"protocolPolicy,omitempty"`
+	AccessControl   string         `json:"accessControl,omitempty"`
+	DirectlyExposed bool           `json:"directlyExposed"`
+	Exposure        cloud.Exposure `json:"exposure"`
+	Reason          string         `json:"reason,omitempty"`
+}
+
+// loadBalancer 源站匹配所需的 ELB 信息
+type loadBalancer struct {
+	arn      string
+	dnsName  string
+	public   bool
+	exposure cloud.Exposure
+}
+
+// bucket 源站匹配所需的 S3 信息
+type bucket struct {
+	name   string
+	public bool
+	reason string
+}
+
+// GetCloudFrontSurfaces 采集 CloudFront 分发并与 EC2/ELB/S3 记录交叉比对，标记可绕过 CDN 的源站
+func (c *AWSCloud) GetCloudFrontSurfaces(instances []*cloud.Asset) ([]*CDNAttackSurface, error) {
+	sgs, err := c.loadSecurityGroups()
+	if err != nil {
+		return nil, err
+	}
+	elbRecords, err := c.ListByPath(PathELB)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathELB, err)
+	}
+	s3Records, err := c.ListByPath(PathS3)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathS3, err)
+	}
+	distRecords, err := c.ListByPath(PathCloudFront)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathCloudFront, err)
+	}
+
+	lbs := map[string]*loadBalancer{}
+	for _, record := range elbRecords {
+		if lb := parseLoadBalancer(cloud.PayloadOf(record), sgs); lb != nil {
+			lbs[strings.ToLower(lb.dnsName)] = lb
+		}
+	}
+	buckets := map[string]*bucket{}
+	for _, record := range s3Records {
+		if b := parseBucket(cloud.PayloadOf(record)); b != nil {
+			buckets[b.name] = b
+		}
+	}
+	hosts := map[string]*cloud.Asset{}
+	for _, as := range instances {
+		if as.Endpoint != "" {
+			hosts[strings.ToLower(as.Endpoint)] = as
+		}
+		for _, ip := range as.PublicIPs {
+			hosts[ip] = as
+		}
+	}
+
+	var out []*CDNAttackSurface
+	var bypass int
+	for _, record := range distRecords {
+		payload := cloud.PayloadOf(record)
+		if m := cloud.Map(payload, "distributionConfig"); m != nil {
+			// 完整的 Distribution 结构，配置在 distributionConfig 下
+			for k, v := range m {
+				payload[k] = v
+			}
+		}
+		id := cloud.String(payload, "id")
+		if id == "" {
+			continue
+		}
+		cdn := &CDNAttackSurface{
+			DistributionID:       id,
+			ARN:                  cloud.String(payload, "arn"),
+			DomainName:           cloud.String(payload, "domainName"),
+			Aliases:              cloud.Strings(cloud.Map(payload, "aliases"), "items"),
+			Enabled:              cloud.Bool(payload, "enabled"),
+			ViewerProtocolPolicy: cloud.String(cloud.Map(payload, "defaultCacheBehavior"), "viewerProtocolPolicy"),
+			WebACLID:             cloud.String(payload, "webACLId"),
+		}
+		cdn.WAFEnabled = cdn.WebACLID != ""
+
+		for _, o := range cloud.Maps(cloud.Map(payload, "origins"), "items") {
+			origin := classifyOrigin(o, hosts, lbs, buckets)
+			if origin.DirectlyExposed {
+				cdn.BypassableOrigins++
+			}
+			cdn.Origins = append(cdn.Origins, origin)
+		}
+		bypass += cdn.BypassableOrigins
+		out = append(out, cdn)
+	}
+	logx.Infof("cloudfront summary: distributions=%d, bypassable origins=%d", len(out), bypass)
+	return out, nil
+}
+
+// Asset 转为统一的资产记录，启用的分发在 80/443 上对任意来源开放
+func (cdn *CDNAttackSurface) Asset() *cloud.Asset {
+	as := &cloud.Asset{
+		Provider:     ProviderName,
+		InstanceID:   cdn.DistributionID,
+		InstanceName: cloud.FirstString(strings.Join(cdn.Aliases, ","), cdn.DomainName),
+		ResourceType: ResourceCloudFront,
+		Region:       "global",
+		Endpoint:     cdn.DomainName,
+		Tags:         map[string]string{},
+		Public:       cdn.Enabled,
+	}
+	if cdn.Enabled {
+		for _, p := range []int{80, 443} {
+			as.Rules = append(as.Rules, endpointRule(p, []string{cloud.ExposureIprange}))
+		}
+	}
+	as.Evaluate()
+	return as
+}
+
+// CDNAssets 将 CloudFront 分发转为资产记录
+func CDNAssets(items []*CDNAttackSurface) []*cloud.Asset {
+	out := make([]*cloud.Asset, 0, len(items))
+	for _, cdn := range items {
+		out = append(out, cdn.Asset())
+	}
+	return out
+}
+
+// CDNFindings 每个可绕过 CDN 的源站产生一条问题，分发启用 WAF 时绕过即绕过 WAF，等级更高
+func CDNFindings(items []*CDNAttackSurface) []*finding.Finding {
+	var out []*finding.Finding
+	for _, cdn := range items {
+		if !cdn.Enabled {
+			continue
+		}
+		as := cdn.Asset()
+		sev := finding.SeverityMedium
+		if cdn.WAFEnabled {
+			sev = finding.SeverityHigh
+		}
+		for _, o := range cdn.Origins {
+			if !o.DirectlyExposed {
+				continue
+			}
+			f := finding.New(RuleCDNOriginBypass, sev, as, cdn.DistributionID+" origin:"+o.ID, "CloudFront origin reachable without the CDN",
+				fmt.Sprintf("%s origin %s: %s", o.OriginType, o.DomainName, o.Reason))
+			f.Evidence["origin"] = o.DomainName
+			f.Evidence["originType"] = o.OriginType
+			f.Evidence["exposure"] = string(o.Exposure)
+			if o.ResourceID != "" {
+				f.Evidence["originResource"] = o.ResourceID
+			}
+			if cdn.WAFEnabled {
+				f.Evidence["webAclId"] = cdn.WebACLID
+			}
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func classifyOrigin(o map[string]interface{}, hosts map[string]*cloud.Asset, lbs map[string]*loadBalancer, buckets map[string]*bucket) CDNOrigin {
+	domain := strings.ToLower(cloud.String(o, "domainName"))
+	origin := CDNOrigin{
+		ID:         cloud.String(o, "id"),
+		DomainName: domain,
+		OriginType: OriginCustom,
+		Exposure:   cloud.ExposureNone,
+	}
+	if id := cloud.String(o, "originAccessControlId"); id != "" {
+		origin.AccessControl = "OAC:" + id
+	} else if oai := cloud.String(cloud.Map(o, "s3OriginConfig"), "originAccessIdentity"); oai != "" {
+		origin.AccessControl = "OAI:" + oai
+	}
+
+	// S3 源站：REST endpoint 或静态网站 endpoint
+	if name, website, ok := bucketFromDomain(domain); ok {
+		origin.OriginType = OriginS3
+		origin.ResourceID = name
+		// 静态网站 endpoint 同样受桶策略与 ACL 约束，非公开的桶直接访问返回 403
+		switch b, found := buckets[name]; {
+		case !found:
+			origin.Reason = "bucket not in inventory"
+		case b.public && website:
+			origin.DirectlyExposed = true
+			origin.Reason = "static website endpoint serves a public bucket: " + b.reason
+		case b.public:
+			origin.DirectlyExposed = true
+			origin.Reason = b.reason
+		}
+		if origin.DirectlyExposed {
+			origin.Exposure = cloud.ExposureInternet
+		}
+		return origin
+	}
+
+	custom := cloud.Map(o, "customOriginConfig")
+	origin.ProtocolPolicy = cloud.String(custom, "originProtocolPolicy")
+	ports := originPorts(custom)
+
+	if lb, ok := lbs[strings.TrimPrefix(domain, "dualstack.")]; ok {
+		origin.OriginType = OriginELB
+		origin.ResourceID = lb.arn
+		origin.Exposure = lb.exposure
+		if lb.public && cloud.IsWideExposure(lb.exposure) {
+			origin.DirectlyExposed = true
+			origin.Reason = "internet-facing load balancer reachable without CDN"
+		}
+		return origin
+	}
+
+	if as, ok := hosts[domain]; ok {
+		origin.OriginType = OriginEC2
+		origin.ResourceID = as.InstanceID
+		exposure := cloud.ExposureNone
+		for _, r := range as.Rules {
+			for _, p := range ports {
+				if r.Covers(p) {
+					exposure = cloud.MaxExposure(exposure, r.Exposure)
+				}
+			}
+		}
+		if !as.Public {
+			exposure = cloud.ExposureNone
+		}
+		origin.Exposure = exposure
+		if cloud.IsWideExposure(exposure) {
+			origin.DirectlyExposed = true
+			origin.Reason = fmt.Sprintf("instance security groups allow origin ports %v from %s", ports, exposure)
+		}
+		return origin
+	}
+	origin.Reason = "origin not in inventory"
+	return origin
+}
+
+// bucketFromDomain 识别 S3 源站域名，返回桶名以及是否为静态网站 endpoint
+func bucketFromDomain(domain string) (string, bool, bool) {
+	idx := strings.Index(domain, ".s3")
+	if idx <= 0 || !strings.HasSuffix(domain, ".amazonaws.com") {
+		return "", false, false
+	}
+	rest := domain[idx+1:]
+	website := strings.HasPrefix(rest, "s3-website")
+	return domain[:idx], website, true
+}
+
+func originPorts(custom map[string]interface{}) []int {
+	httpPort, ok := cloud.Int(custom, "httpPort")
+	if !ok {
+		httpPort = 80
+	}
+	httpsPort, ok := cloud.Int(custom, "httpsPort")
+	if !ok {
+		httpsPort = 443
+	}
+	switch cloud.String(custom, "originProtocolPolicy") {
+	case "http-only":
+		return []int{httpPort}
+	case "https-only":
+		return []int{httpsPort}
+	default:
+		return []int{httpPort, httpsPort}
+	}
+}
+
+func parseLoadBalancer(payload map[string]interface{}, sgs *SecurityGroups) *loadBalancer {
+	dns := cloud.String(payload, "dnsName")
+	if dns == "" {
+		return nil
+	}
+	lb := &loadBalancer{
+		arn:     cloud.FirstString(cloud.String(payload, "loadBalancerArn"), cloud.String(payload, "loadBalancerName")),
+		dnsName: dns,
+		public:  strings.EqualFold(cloud.String(payload, "scheme"), "internet-facing"),
+	}
+	groups := cloud.Strings(payload, "securityGroups")
+	if len(groups) == 0 {
+		// 未绑定安全组的 NLB 对所有来源开放
+		lb.exposure = cloud.ExposureInternet
+		return lb
+	}
+	var ports []int
+	for _, l := range cloud.Maps(payload, "listeners") {
+		if p, ok := cloud.Int(l, "port"); ok {
+			ports = append(ports, p)
+		}
+	}
+	lb.exposure = cloud.ExposureNone
+	for _, r := range sgs.Rules(groups, ports) {
+		lb.exposure = cloud.MaxExposure(lb.exposure, r.Exposure)
+	}
+	return lb
+}
+
+func parseBucket(payload map[string]interface{}) *bucket {
+	name := cloud.String(payload, "name")
+	if name == "" {
+		return nil
+	}
+	b := &bucket{name: name}
+	block := cloud.Map(payload, "publicAccessBlock")
+	if cloud.Bool(block, "restrictPublicBuckets") {
+		return b
+	}
+	for _, cidr := range policySourceIPs(cloud.String(payload, "policy")) {
+		if cidr == cloud.ExposureIprange {
+			b.public = true
+			b.reason = "bucket policy allows anonymous access"
+			return b
+		}
+	}
+	if cloud.Bool(block, "ignorePublicAcls") {
+		return b
+	}
+	for _, g := range cloud.Maps(cloud.Map(payload, "acl"), "grants") {
+		uri := cloud.String(cloud.Map(g, "grantee"), "uri")
+		if strings.HasSuffix(uri, "/AllUsers") || strings.HasSuffix(uri, "/AuthenticatedUsers") {
+			b.public = true
+			b.reason = "bucket ACL grants " + uri[strings.LastIndex(uri, "/")+1:]
+			return b
+		}
+	}
+	return b
+}
@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/colin-404/logx"
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+const PathManagedAttackSurface = "/protocols/external-attack-surface/aws-managed"
+
+// ManagedEndpointCollector 将托管服务的 endpoint 配置转换为与 EC2 相同的暴露记录
+type ManagedEndpointCollector interface {
+	// ResourceType 资源类型，如 eks、opensearch
+	ResourceType() string
+	// Path 采集器写入 aws_info 的路径
+	Path() string
+	// Parse 解析单条资源，sgs 用于关联资源绑定的安全组规则
+	Parse(payload map[string]interface{}, sgs *SecurityGroups) *cloud.Asset
+}
+
+var managedCollectors []ManagedEndpointCollector
+
+// RegisterManagedCollector 注册托管服务采集器，重复的资源类型会被替换
+func RegisterManagedCollector(c ManagedEndpointCollector) {
+	for i, existing := range managedCollectors {
+		if existing.ResourceType() == c.ResourceType() {
+			managedCollectors[i] = c
+			return
+		}
+	}
+	managedCollectors = append(managedCollectors, c)
+}
+
+func init() {
+	RegisterManagedCollector(eksCollector{})
+	RegisterManagedCollector(openSearchCollector{})
+	RegisterManagedCollector(redshiftCollector{})
+	RegisterManagedCollector(elastiCacheCollector{})
+	RegisterManagedCollector(mskCollector{})
+	RegisterManagedCollector(docDBCollector{})
+}
+
+// GetManagedEndpoints 依次运行已注册的托管服务采集器
+func (c *AWSCloud) GetManagedEndpoints() ([]*cloud.Asset, error) {
+	sgs, err := c.loadSecurityGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*cloud.Asset
+	for _, col := range managedCollectors {
+		records, err := c.ListByPath(col.Path())
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", col.Path(), err)
+		}
+		var count, exposed int
+		for _, record := range records {
+			payload := cloud.PayloadOf(record)
+			if payload == nil {
+				continue
+			}
+			as := col.Parse(payload, sgs)
+			if as == nil {
+				continue
+			}
+			as.Provider = ProviderName
+			as.ResourceType = col.ResourceType()
+			as.Evaluate()
+			out = append(out, as)
+			count++
+			if cloud.IsWideExposure(as.Exposure) {
+				exposed++
+			}
+		}
+		logx.Infof("managed endpoints summary: type=%s, total=%d, exposed=%d", col.ResourceType(), count, exposed)
+	}
+	return out, nil
+}
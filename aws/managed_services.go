@@ -0,0 +1,301 @@
+package aws
+
+import (
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// 采集器写入 aws_info 的托管服务路径
+const (
+	PathEKS         = "/info/aws/eks"
+	PathOpenSearch  = "/info/aws/opensearch"
+	PathRedshift    = "/info/aws/redshift"
+	PathElastiCache = "/info/aws/elasticache"
+	PathMSK         = "/info/aws/msk"
+	PathDocDB       = "/info/aws/docdb"
+)
+
+// MSK 公网访问开启后 broker 对外监听的端口（TLS / SASL-SCRAM / IAM）
+var mskPublicPorts = []int{9194, 9196, 9198}
+
+// ---------------------- EKS ----------------------
+
+type eksCollector struct{}
+
+func (eksCollector) ResourceType() string { return "eks" }
+func (eksCollector) Path() string         { return PathEKS }
+
+func (eksCollector) Parse(payload map[string]interface{}, sgs *SecurityGroups) *cloud.Asset {
+	if m := cloud.Map(payload, "cluster"); m != nil {
+		payload = m
+	}
+	name := cloud.String(payload, "name")
+	if name == "" {
+		return nil
+	}
+	vpc := cloud.Map(payload, "resourcesVpcConfig")
+	as := &cloud.Asset{
+		InstanceID:   cloud.String(payload, "arn"),
+		InstanceName: name,
+		Region:       regionFromARN(cloud.String(payload, "arn")),
+		VpcID:        cloud.String(vpc, "vpcId"),
+		Endpoint:     cloud.String(payload, "endpoint"),
+		Tags:         cloud.Tags(cloud.AnyCase(payload, "tags")),
+		GroupIDs:     cloud.Strings(vpc, "securityGroupIds"),
+		Public:       cloud.Bool(vpc, "endpointPublicAccess"),
+	}
+	if as.InstanceID == "" {
+		as.InstanceID = name
+	}
+	if id := cloud.String(vpc, "clusterSecurityGroupId"); id != "" {
+		as.GroupIDs = cloud.AppendUnique(as.GroupIDs, id)
+	}
+
+	// 公网 endpoint 的访问控制由 publicAccessCidrs 决定，与安全组无关
+	if as.Public {
+		cidrs := cloud.Strings(vpc, "publicAccessCidrs")
+		if len(cidrs) == 0 {
+			cidrs = []string{cloud.ExposureIprange}
+		}
+		as.Rules = append(as.Rules, endpointRule(443, cidrs))
+	}
+	// 仅私有访问时安全组规则只影响 VPC 内部，保留作为参考
+	if !as.Public && cloud.Bool(vpc, "endpointPrivateAccess") {
+		as.Rules = append(as.Rules, sgs.Rules(as.GroupIDs, []int{443})...)
+	}
+	return as
+}
+
+// ---------------------- OpenSearch ----------------------
+
+type openSearchCollector struct{}
+
+func (openSearchCollector) ResourceType() string { return "opensearch" }
+func (openSearchCollector) Path() string         { return PathOpenSearch }
+
+func (openSearchCollector) Parse(payload map[string]interface{}, sgs *SecurityGroups) *cloud.Asset {
+	if m := cloud.Map(payload, "domainStatus"); m != nil {
+		payload = m
+	}
+	name := cloud.String(payload, "domainName")
+	if name == "" {
+		return nil
+	}
+	as := &cloud.Asset{
+		InstanceID:   cloud.String(payload, "arn"),
+		InstanceName: name,
+		Region:       regionFromARN(cloud.String(payload, "arn")),
+		Tags:         cloud.Tags(cloud.FirstNonNil(cloud.AnyCase(payload, "tags"), cloud.AnyCase(payload, "tagList"))),
+	}
+	if as.InstanceID == "" {
+		as.InstanceID = name
+	}
+	ports := []int{443}
+	if !cloud.Bool(cloud.Map(payload, "domainEndpointOptions"), "enforceHTTPS") {
+		ports = append(ports, 80)
+	}
+
+	// VPC 内的域只能通过安全组访问，公网域由访问策略中的 aws:SourceIp 决定
+	if vpc := cloud.Map(payload, "vpcOptions"); cloud.String(vpc, "vpcId") != "" {
+		as.VpcID = cloud.String(vpc, "vpcId")
+		as.GroupIDs = cloud.Strings(vpc, "securityGroupIds")
+		as.Endpoint = cloud.String(cloud.Map(payload, "endpoints"), "vpc")
+		as.Rules = sgs.Rules(as.GroupIDs, ports)
+		return as
+	}
+
+	as.Public = true
+	as.Endpoint = cloud.String(payload, "endpoint")
+	cidrs := policySourceIPs(cloud.String(payload, "accessPolicies"))
+	for _, p := range ports {
+		as.Rules = append(as.Rules, endpointRule(p, cidrs))
+	}
+	return as
+}
+
+// ---------------------- Redshift ----------------------
+
+type redshiftCollector struct{}
+
+func (redshiftCollector) ResourceType() string { return "redshift" }
+func (redshiftCollector) Path() string         { return PathRedshift }
+
+func (redshiftCollector) Parse(payload map[string]interface{}, sgs *SecurityGroups) *cloud.Asset {
+	id := cloud.String(payload, "clusterIdentifier")
+	if id == "" {
+		return nil
+	}
+	endpoint := cloud.Map(payload, "endpoint")
+	as := &cloud.Asset{
+		InstanceID:   id,
+		InstanceName: id,
+		Region:       regionFromAZ(cloud.String(payload, "availabilityZone")),
+		VpcID:        cloud.String(payload, "vpcId"),
+		Endpoint:     cloud.String(endpoint, "address"),
+		Tags:         cloud.Tags(cloud.AnyCase(payload, "tags")),
+		GroupIDs:     vpcSecurityGroupIDs(payload),
+		Public:       cloud.Bool(payload, "publiclyAccessible"),
+	}
+	for _, node := range cloud.Maps(payload, "clusterNodes") {
+		if ip := cloud.String(node, "publicIPAddress"); ip != "" {
+			as.PublicIPs = cloud.AppendUnique(as.PublicIPs, ip)
+		}
+		if ip := cloud.String(node, "privateIPAddress"); ip != "" {
+			as.PrivateIPs = cloud.AppendUnique(as.PrivateIPs, ip)
+		}
+	}
+	as.Rules = sgs.Rules(as.GroupIDs, endpointPorts(endpoint, 5439))
+	return as
+}
+
+// ---------------------- ElastiCache ----------------------
+
+type elastiCacheCollector struct{}
+
+func (elastiCacheCollector) ResourceType() string { return "elasticache" }
+func (elastiCacheCollector) Path() string         { return PathElastiCache }
+
+func (elastiCacheCollector) Parse(payload map[string]interface{}, sgs *SecurityGroups) *cloud.Asset {
+	id := cloud.String(payload, "cacheClusterId")
+	if id == "" {
+		return nil
+	}
+	as := &cloud.Asset{
+		InstanceID:   cloud.FirstString(cloud.String(payload, "arn"), id),
+		InstanceName: id,
+		Region:       regionFromAZ(cloud.String(payload, "preferredAvailabilityZone")),
+		Tags:         cloud.Tags(cloud.AnyCase(payload, "tags")),
+	}
+	for _, g := range cloud.Maps(payload, "securityGroups") {
+		as.GroupIDs = cloud.AppendUnique(as.GroupIDs, cloud.String(g, "securityGroupId"))
+	}
+
+	defaultPort := 6379
+	if strings.EqualFold(cloud.String(payload, "engine"), "memcached") {
+		defaultPort = 11211
+	}
+	endpoint := cloud.Map(payload, "configurationEndpoint")
+	if nodes := cloud.Maps(payload, "cacheNodes"); endpoint == nil && len(nodes) > 0 {
+		endpoint = cloud.Map(nodes[0], "endpoint")
+	}
+	as.Endpoint = cloud.String(endpoint, "address")
+	// ElastiCache 只能部署在 VPC 内，没有公网入口，规则仅作为参考
+	as.Rules = sgs.Rules(as.GroupIDs, endpointPorts(endpoint, defaultPort))
+	return as
+}
+
+// ---------------------- MSK ----------------------
+
+type mskCollector struct{}
+
+func (mskCollector) ResourceType() string { return "msk" }
+func (mskCollector) Path() string         { return PathMSK }
+
+func (mskCollector) Parse(payload map[string]interface{}, sgs *SecurityGroups) *cloud.Asset {
+	if m := cloud.Map(payload, "clusterInfo"); m != nil {
+		payload = m
+	}
+	arn := cloud.String(payload, "clusterArn")
+	if arn == "" {
+		return nil
+	}
+	broker := cloud.Map(payload, "brokerNodeGroupInfo")
+	publicAccess := cloud.Map(cloud.Map(broker, "connectivityInfo"), "publicAccess")
+	as := &cloud.Asset{
+		InstanceID:   arn,
+		InstanceName: cloud.String(payload, "clusterName"),
+		Region:       regionFromARN(arn),
+		Endpoint:     cloud.String(payload, "bootstrapBrokerStringPublicTls"),
+		Tags:         cloud.Tags(cloud.AnyCase(payload, "tags")),
+		GroupIDs:     cloud.Strings(broker, "securityGroups"),
+		Public:       strings.EqualFold(cloud.String(publicAccess, "type"), "SERVICE_PROVIDED_EIPS"),
+	}
+	ports := mskPublicPorts
+	if !as.Public {
+		ports = []int{9092, 9094, 9096, 9098}
+	}
+	as.Rules = sgs.Rules(as.GroupIDs, ports)
+	return as
+}
+
+// ---------------------- DocumentDB ----------------------
+
+type docDBCollector struct{}
+
+func (docDBCollector) ResourceType() string { return "docdb" }
+func (docDBCollector) Path() string         { return PathDocDB }
+
+func (docDBCollector) Parse(payload map[string]interface{}, sgs *SecurityGroups) *cloud.Asset {
+	id := cloud.String(payload, "dbClusterIdentifier")
+	if id == "" {
+		return nil
+	}
+	arn := cloud.String(payload, "dbClusterArn")
+	as := &cloud.Asset{
+		InstanceID:   cloud.FirstString(arn, id),
+		InstanceName: id,
+		Region:       regionFromARN(arn),
+		Endpoint:     cloud.String(payload, "endpoint"),
+		Tags:         cloud.Tags(cloud.AnyCase(payload, "tagList")),
+		GroupIDs:     vpcSecurityGroupIDs(payload),
+		Public:       cloud.Bool(payload, "publiclyAccessible"),
+	}
+	port, ok := cloud.Int(payload, "port")
+	if !ok {
+		port = 27017
+	}
+	as.Rules = sgs.Rules(as.GroupIDs, []int{port})
+	return as
+}
+
+// ---------------------- helpers ----------------------
+
+// endpointRule 由服务自身的访问控制（而非安全组）生成的规则
+func endpointRule(port int, cidrs []string) cloud.Rule {
+	return cloud.Rule{
+		FromPort: port,
+		ToPort:   port,
+		Protocol: "tcp",
+		Iprange:  cidrs,
+		Exposure: cloud.ClassifyCIDRs(cidrs),
+	}
+}
+
+func endpointPorts(endpoint map[string]interface{}, defaultPort int) []int {
+	if port, ok := cloud.Int(endpoint, "port"); ok && port > 0 {
+		return []int{port}
+	}
+	return []int{defaultPort}
+}
+
+func vpcSecurityGroupIDs(payload map[string]interface{}) []string {
+	var out []string
+	for _, g := range cloud.Maps(payload, "vpcSecurityGroups") {
+		if id := cloud.String(g, "vpcSecurityGroupId"); id != "" {
+			out = cloud.AppendUnique(out, id)
+		}
+	}
+	return out
+}
+
+// policySourceIPs 提取访问策略中 Allow 语句允许的来源地址，无条件放行任意主体时返回 0.0.0.0/0
+func policySourceIPs(policy string) []string {
+	var out []string
+	for _, st := range parsePolicyStatements(policy) {
+		if !strings.EqualFold(cloud.String(st, "Effect"), "Allow") {
+			continue
+		}
+		cond := cloud.Map(st, "Condition")
+		if len(cond) == 0 {
+			if isWildcardPrincipal(cloud.AnyCase(st, "Principal")) {
+				return []string{cloud.ExposureIprange}
+			}
+			continue
+		}
+		for _, ip := range cloud.Strings(cloud.Map(cond, "IpAddress"), "aws:SourceIp") {
+			out = cloud.AppendUnique(out, ip)
+		}
+	}
+	return out
+}
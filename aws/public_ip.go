@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/colin-404/logx"
+	"github.com/xid-protocol/attack-surface/cloud"
 	"github.com/xid-protocol/xidp/protocols"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -208,13 +209,24 @@ func BuildPublicIPMapFromXIDs(items []*protocols.XID) map[string][]string {
 	return out
 }
 
+// addPublicIP 校验并规范化地址，无法解析的地址记录后跳过；
+// 私网、CGNAT、保留地址等仍然保留，由 ipranges 标注类别并产生问题
+func addPublicIP(set map[string]struct{}, s string) {
+	addr, ok := cloud.ParseAddress(s)
+	if !ok {
+		logx.Warnf("skip invalid public ip %q", s)
+		return
+	}
+	set[addr.String()] = struct{}{}
+}
+
 func extractPublicIPs(payload bson.M) []string {
 	set := map[string]struct{}{}
 
 	// Top-level Public IP
 	if v := getAnyCase(payload, "publicipaddress"); v != nil {
 		if s, ok := v.(string); ok && s != "" {
-			set[s] = struct{}{}
+			addPublicIP(set, s)
 		}
 	}
 
@@ -231,7 +243,7 @@ func extractPublicIPs(payload bson.M) []string {
 					if assoc, ok := toMap(assocAny); ok {
 						if p := getAnyCase(assoc, "publicip"); p != nil {
 							if s, ok := p.(string); ok && s != "" {
-								set[s] = struct{}{}
+								addPublicIP(set, s)
 							}
 						}
 					}
@@ -248,7 +260,7 @@ func extractPublicIPs(payload bson.M) []string {
 								if assoc, ok := toMap(assocAny); ok {
 									if p := getAnyCase(assoc, "publicip"); p != nil {
 										if s, ok := p.(string); ok && s != "" {
-											set[s] = struct{}{}
+											addPublicIP(set, s)
 										}
 									}
 								}
@@ -275,7 +287,7 @@ func extractPublicIPsFromKV(kvs []interface{}) []string {
 	// Top-level publicipaddress
 	if v := kvFind(kvs, "publicipaddress"); v != nil {
 		if s := asString(v); s != "" {
-			set[s] = struct{}{}
+			addPublicIP(set, s)
 		}
 	}
 
@@ -291,7 +303,7 @@ func extractPublicIPsFromKV(kvs []interface{}) []string {
 					if assocKVs, ok := assocAny.([]interface{}); ok {
 						if p := kvFind(assocKVs, "publicip"); p != nil {
 							if s := asString(p); s != "" {
-								set[s] = struct{}{}
+								addPublicIP(set, s)
 							}
 						}
 					}
@@ -307,7 +319,7 @@ func extractPublicIPsFromKV(kvs []interface{}) []string {
 								if assocKVs, ok := assocAny.([]interface{}); ok {
 									if p := kvFind(assocKVs, "publicip"); p != nil {
 										if s := asString(p); s != "" {
-											set[s] = struct{}{}
+											addPublicIP(set, s)
 										}
 									}
 								}
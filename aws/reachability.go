@@ -0,0 +1,408 @@
+package aws
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// 采集器写入 aws_info 的网络层资源路径
+const (
+	PathNACL       = "/info/aws/nacl"
+	PathRouteTable = "/info/aws/routetable"
+	PathIGW        = "/info/aws/igw"
+)
+
+// 可达性判定的各个网络层
+const (
+	LayerSecurityGroup = "security-group"
+	LayerNACL          = "nacl"
+	LayerRoute         = "route"
+	LayerPublicIP      = "public-ip"
+)
+
+// NACL 无状态，回包走临时端口
+const (
+	ephemeralFrom = 1024
+	ephemeralTo   = 65535
+)
+
+// networkIndex 按子网/VPC 索引 NACL、路由表与网关
+type networkIndex struct {
+	naclBySubnet  map[string]map[string]interface{}
+	defaultNACL   map[string]map[string]interface{} // vpcId -> 默认 NACL
+	routeBySubnet map[string]map[string]interface{}
+	mainRoute     map[string]map[string]interface{} // vpcId -> 主路由表
+	igwVPC        map[string]string                 // igw id -> 已挂载的 vpcId
+}
+
+func (c *AWSCloud) loadNetworkIndex() (*networkIndex, error) {
+	idx := &networkIndex{
+		naclBySubnet:  map[string]map[string]interface{}{},
+		defaultNACL:   map[string]map[string]interface{}{},
+		routeBySubnet: map[string]map[string]interface{}{},
+		mainRoute:     map[string]map[string]interface{}{},
+		igwVPC:        map[string]string{},
+	}
+
+	nacls, err := c.ListByPath(PathNACL)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathNACL, err)
+	}
+	for _, record := range nacls {
+		acl := cloud.PayloadOf(record)
+		if cloud.String(acl, "networkAclId") == "" {
+			continue
+		}
+		if cloud.Bool(acl, "isDefault") {
+			idx.defaultNACL[cloud.String(acl, "vpcId")] = acl
+		}
+		for _, a := range cloud.Maps(acl, "associations") {
+			if subnet := cloud.String(a, "subnetId"); subnet != "" {
+				idx.naclBySubnet[subnet] = acl
+			}
+		}
+	}
+
+	routes, err := c.ListByPath(PathRouteTable)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathRouteTable, err)
+	}
+	for _, record := range routes {
+		rt := cloud.PayloadOf(record)
+		if cloud.String(rt, "routeTableId") == "" {
+			continue
+		}
+		for _, a := range cloud.Maps(rt, "associations") {
+			if cloud.Bool(a, "main") {
+				idx.mainRoute[cloud.String(rt, "vpcId")] = rt
+			}
+			if subnet := cloud.String(a, "subnetId"); subnet != "" {
+				idx.routeBySubnet[subnet] = rt
+			}
+		}
+	}
+
+	igws, err := c.ListByPath(PathIGW)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathIGW, err)
+	}
+	for _, record := range igws {
+		igw := cloud.PayloadOf(record)
+		id := cloud.String(igw, "internetGatewayId")
+		for _, a := range cloud.Maps(igw, "attachments") {
+			state := cloud.String(a, "state")
+			if id != "" && (state == "" || state == "available" || state == "attached") {
+				idx.igwVPC[id] = cloud.String(a, "vpcId")
+			}
+		}
+	}
+	return idx, nil
+}
+
+func (n *networkIndex) empty() bool {
+	return len(n.naclBySubnet) == 0 && len(n.defaultNACL) == 0 && len(n.routeBySubnet) == 0 && len(n.mainRoute) == 0
+}
+
+func (n *networkIndex) naclFor(subnet, vpc string) map[string]interface{} {
+	if acl, ok := n.naclBySubnet[subnet]; ok {
+		return acl
+	}
+	return n.defaultNACL[vpc]
+}
+
+func (n *networkIndex) routeTableFor(subnet, vpc string) map[string]interface{} {
+	if rt, ok := n.routeBySubnet[subnet]; ok {
+		return rt
+	}
+	return n.mainRoute[vpc]
+}
+
+// evaluate 对实例每条放行公网来源的规则计算有效可达性
+func (n *networkIndex) evaluate(as *cloud.Asset) []cloud.Reachability {
+	acl := n.naclFor(as.SubnetID, as.VpcID)
+	rt := n.routeTableFor(as.SubnetID, as.VpcID)
+
+	out := []cloud.Reachability{}
+	for _, rule := range as.Rules {
+		var sources []netip.Prefix
+		for _, cidr := range rule.Sources() {
+			if e := cloud.ClassifyCIDR(cidr); e == cloud.ExposureNone || e == cloud.ExposurePrivate {
+				continue
+			}
+			if p, err := netip.ParsePrefix(cidr); err == nil {
+				sources = append(sources, p.Masked())
+			}
+		}
+		if len(sources) == 0 {
+			continue
+		}
+		out = append(out, n.evaluateRule(as, rule, sources, acl, rt))
+	}
+	return out
+}
+
+func (n *networkIndex) evaluateRule(as *cloud.Asset, rule cloud.Rule, sources []netip.Prefix, acl, rt map[string]interface{}) cloud.Reachability {
+	r := cloud.Reachability{
+		GroupID:  rule.GroupID,
+		FromPort: rule.FromPort,
+		ToPort:   rule.ToPort,
+		Protocol: rule.Protocol,
+		Sources:  prefixStrings(sources),
+	}
+	r.Layers = append(r.Layers, cloud.LayerResult{Layer: LayerSecurityGroup, Allowed: true, Detail: "allowed by " + rule.GroupID})
+
+	// NACL：入方向过滤来源，出方向需放行临时端口的回包
+	effective := sources
+	if acl == nil {
+		r.Layers = append(r.Layers, cloud.LayerResult{Layer: LayerNACL, Allowed: true, Detail: "no network ACL found, assuming default allow"})
+	} else {
+		aclID := cloud.String(acl, "networkAclId")
+		inbound, outbound := naclEntries(acl)
+		effective = nil
+		for _, src := range sources {
+			effective = append(effective, naclAllowed(inbound, rule.Protocol, rule.FromPort, rule.ToPort, src)...)
+		}
+		returnOK := false
+		for _, src := range effective {
+			if len(naclAllowed(outbound, rule.Protocol, ephemeralFrom, ephemeralTo, src)) > 0 {
+				returnOK = true
+				break
+			}
+		}
+		switch {
+		case len(effective) == 0:
+			r.Layers = append(r.Layers, cloud.LayerResult{Layer: LayerNACL, Detail: aclID + " denies inbound"})
+		case !returnOK:
+			r.Layers = append(r.Layers, cloud.LayerResult{Layer: LayerNACL, Detail: aclID + " denies outbound ephemeral ports"})
+			effective = nil
+		default:
+			r.Layers = append(r.Layers, cloud.LayerResult{Layer: LayerNACL, Allowed: true, Detail: "allowed by " + aclID})
+		}
+	}
+
+	// 路由：子网需要有指向已挂载 IGW 的路由，egress-only IGW 不接受入站
+	var routed []netip.Prefix
+	routeDetail := "no route table found"
+	if rt != nil {
+		routeDetail = cloud.String(rt, "routeTableId") + " has no internet gateway route"
+		for _, src := range effective {
+			target, ok := n.igwRoute(rt, as.VpcID, src)
+			if ok {
+				routed = append(routed, src)
+				routeDetail = "routed via " + target
+			} else if strings.HasPrefix(target, "eigw-") {
+				routeDetail = target + " is egress-only"
+			} else if target != "" && len(routed) == 0 {
+				routeDetail = src.String() + " routed via " + target
+			}
+		}
+	}
+	r.Layers = append(r.Layers, cloud.LayerResult{Layer: LayerRoute, Allowed: len(routed) > 0, Detail: routeDetail})
+
+	// 公网地址：IPv4 来源需要公网 IPv4，IPv6 来源需要 IPv6 地址
+	var reachable []netip.Prefix
+	for _, src := range routed {
+		if (src.Addr().Is4() && cloud.HasPublicAddress(as.PublicIPs)) || (src.Addr().Is6() && len(as.IPv6s) > 0) {
+			reachable = append(reachable, src)
+		}
+	}
+	ipDetail := "no public address"
+	if len(as.PublicIPs) > 0 || len(as.IPv6s) > 0 {
+		ipDetail = strings.Join(append(append([]string{}, as.PublicIPs...), as.IPv6s...), ",")
+	}
+	r.Layers = append(r.Layers, cloud.LayerResult{Layer: LayerPublicIP, Allowed: len(reachable) > 0, Detail: ipDetail})
+
+	r.Effective = prefixStrings(reachable)
+	r.Reachable = len(reachable) > 0
+	r.Exposure = cloud.ClassifyCIDRs(r.Effective)
+	for _, l := range r.Layers {
+		if !l.Allowed {
+			r.BlockedBy = l.Layer
+			break
+		}
+	}
+	return r
+}
+
+// igwRoute 按最长前缀匹配选出来源地址段的回程路由，目标为 IGW 时返回网关。
+// 没有路由完整覆盖来源时（如来源为 0.0.0.0/0 而路由更具体），只要有一部分经 IGW 返回即视为可达
+func (n *networkIndex) igwRoute(rt map[string]interface{}, vpc string, src netip.Prefix) (string, bool) {
+	var best map[string]interface{}
+	bestBits := -1
+	var partial []map[string]interface{}
+	for _, route := range cloud.Maps(rt, "routes") {
+		if strings.EqualFold(cloud.String(route, "state"), "blackhole") {
+			continue
+		}
+		dest := cloud.FirstString(cloud.String(route, "destinationCidrBlock"), cloud.String(route, "destinationIpv6CidrBlock"))
+		p, err := netip.ParsePrefix(dest)
+		if err != nil {
+			continue
+		}
+		p = p.Masked()
+		switch {
+		case p.Bits() <= src.Bits() && p.Contains(src.Addr()):
+			if p.Bits() > bestBits {
+				best, bestBits = route, p.Bits()
+			}
+		case prefixOverlaps(p, src):
+			partial = append(partial, route)
+		}
+	}
+	if best != nil {
+		return n.routeTarget(best, vpc)
+	}
+	var fallback string
+	for _, route := range partial {
+		target, ok := n.routeTarget(route, vpc)
+		if ok {
+			return target, true
+		}
+		if strings.HasPrefix(target, "eigw-") {
+			fallback = target
+		}
+	}
+	return fallback, false
+}
+
+// routeTarget 返回路由目标，目标为挂载在该 VPC 上的 IGW 时为 true
+func (n *networkIndex) routeTarget(route map[string]interface{}, vpc string) (string, bool) {
+	if eigw := cloud.String(route, "egressOnlyInternetGatewayId"); eigw != "" {
+		return eigw, false
+	}
+	gw := cloud.String(route, "gatewayId")
+	if !strings.HasPrefix(gw, "igw-") {
+		return cloud.FirstString(gw, cloud.String(route, "natGatewayId"), cloud.String(route, "transitGatewayId"),
+			cloud.String(route, "vpcPeeringConnectionId"), cloud.String(route, "networkInterfaceId")), false
+	}
+	// 未采集网关挂载信息时信任路由表
+	if attached, ok := n.igwVPC[gw]; len(n.igwVPC) > 0 && (!ok || attached != vpc) {
+		return gw, false
+	}
+	return gw, true
+}
+
+type naclEntry struct {
+	number   int
+	protocol string
+	allow    bool
+	cidr     netip.Prefix
+	from, to int
+}
+
+// naclEntries 解析 NACL 条目并按规则号排序，返回入方向与出方向
+func naclEntries(acl map[string]interface{}) ([]naclEntry, []naclEntry) {
+	var inbound, outbound []naclEntry
+	for _, e := range cloud.Maps(acl, "entries") {
+		cidr := cloud.FirstString(cloud.String(e, "cidrBlock"), cloud.String(e, "ipv6CidrBlock"))
+		p, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			continue
+		}
+		num, _ := cloud.Int(e, "ruleNumber")
+		entry := naclEntry{
+			number:   num,
+			protocol: protocolNumber(cloud.String(e, "protocol")),
+			allow:    strings.EqualFold(cloud.String(e, "ruleAction"), "allow"),
+			cidr:     p.Masked(),
+			from:     0,
+			to:       65535,
+		}
+		if pr := cloud.Map(e, "portRange"); pr != nil {
+			entry.from, _ = cloud.Int(pr, "from")
+			entry.to, _ = cloud.Int(pr, "to")
+		}
+		if cloud.Bool(e, "egress") {
+			outbound = append(outbound, entry)
+		} else {
+			inbound = append(inbound, entry)
+		}
+	}
+	sort.Slice(inbound, func(i, j int) bool { return inbound[i].number < inbound[j].number })
+	sort.Slice(outbound, func(i, j int) bool { return outbound[i].number < outbound[j].number })
+	return inbound, outbound
+}
+
+// naclAllowed 按规则号顺序评估 NACL，返回来源中被放行的地址段
+func naclAllowed(entries []naclEntry, protocol string, from, to int, src netip.Prefix) []netip.Prefix {
+	proto := protocolNumber(protocol)
+	var allowed, denied []netip.Prefix
+	for _, e := range entries {
+		if e.protocol != "-1" && proto != "-1" && e.protocol != proto {
+			continue
+		}
+		if e.protocol != "-1" && e.protocol != "1" && (e.to < from || e.from > to) {
+			continue
+		}
+		if !prefixOverlaps(e.cidr, src) {
+			continue
+		}
+		narrow := narrower(e.cidr, src)
+		if e.allow {
+			if !coveredBy(narrow, denied) && !coveredBy(narrow, allowed) {
+				allowed = append(allowed, narrow)
+			}
+		} else {
+			denied = append(denied, narrow)
+		}
+		// 条目完整覆盖来源与端口时，后续条目不再生效
+		fullPorts := e.protocol == "-1" || (e.from <= from && e.to >= to)
+		if e.cidr.Contains(src.Addr()) && e.cidr.Bits() <= src.Bits() && fullPorts {
+			break
+		}
+	}
+	return allowed
+}
+
+// protocolNumber 将安全组/NACL 中的协议统一为协议号字符串
+func protocolNumber(p string) string {
+	switch strings.ToLower(p) {
+	case "tcp":
+		return "6"
+	case "udp":
+		return "17"
+	case "icmp":
+		return "1"
+	case "icmpv6":
+		return "58"
+	case "all", "":
+		return "-1"
+	default:
+		return p
+	}
+}
+
+func prefixOverlaps(a, b netip.Prefix) bool {
+	if a.Addr().Is4() != b.Addr().Is4() {
+		return false
+	}
+	return a.Contains(b.Addr()) || b.Contains(a.Addr())
+}
+
+func narrower(a, b netip.Prefix) netip.Prefix {
+	if a.Bits() >= b.Bits() {
+		return a
+	}
+	return b
+}
+
+func coveredBy(p netip.Prefix, list []netip.Prefix) bool {
+	for _, q := range list {
+		if q.Bits() <= p.Bits() && q.Contains(p.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+func prefixStrings(list []netip.Prefix) []string {
+	out := make([]string, 0, len(list))
+	for _, p := range list {
+		out = cloud.AppendUnique(out, p.String())
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// 采集器写入 aws_info 的 Route53 记录集路径
+const PathRoute53 = "/info/aws/route53"
+
+// Route53Names 返回 记录值（IP 或域名）-> 指向它的公网域名，私有托管区的记录被忽略
+func (c *AWSCloud) Route53Names() (map[string][]string, error) {
+	records, err := c.ListByPath(PathRoute53)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathRoute53, err)
+	}
+	out := map[string][]string{}
+	for _, record := range records {
+		payload := cloud.PayloadOf(record)
+		if cloud.Bool(payload, "privateZone") || cloud.Bool(cloud.Map(cloud.Map(payload, "hostedZone"), "config"), "privateZone") {
+			continue
+		}
+		switch strings.ToUpper(cloud.String(payload, "type")) {
+		case "A", "AAAA", "CNAME":
+		default:
+			continue
+		}
+		name := normalizeDNSName(cloud.String(payload, "name"))
+		if name == "" {
+			continue
+		}
+		var values []string
+		for _, rr := range cloud.Maps(payload, "resourceRecords") {
+			values = append(values, cloud.String(rr, "value"))
+		}
+		if alias := cloud.String(cloud.Map(payload, "aliasTarget"), "dnsName"); alias != "" {
+			values = append(values, alias)
+		}
+		for _, v := range values {
+			v = strings.TrimPrefix(normalizeDNSName(v), "dualstack.")
+			if v != "" {
+				out[v] = cloud.AppendUnique(out[v], name)
+			}
+		}
+	}
+	return out, nil
+}
+
+// normalizeDNSName 去掉末尾的点并转为小写，\052 转回通配符
+func normalizeDNSName(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	return strings.ReplaceAll(name, `\052`, "*")
+}
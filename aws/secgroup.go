@@ -0,0 +1,232 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// 采集器写入 aws_info 的安全组及其引用对象路径
+const (
+	PathSecGroup   = "/info/aws/secgroup"
+	PathENI        = "/info/aws/eni"
+	PathPrefixList = "/info/aws/prefixlist"
+)
+
+type groupMember struct {
+	eni string
+	ips []string
+}
+
+// SecurityGroups 安全组索引，负责展开规则中的安全组引用与前缀列表
+type SecurityGroups struct {
+	groups      map[string]map[string]interface{}
+	members     map[string][]groupMember
+	prefixLists map[string]cloud.PrefixListReference
+}
+
+// loadSecurityGroups 加载安全组、ENI 与前缀列表，兼容单个安全组与 DescribeSecurityGroups 输出两种存储形式
+func (c *AWSCloud) loadSecurityGroups() (*SecurityGroups, error) {
+	sgs := &SecurityGroups{
+		groups:      map[string]map[string]interface{}{},
+		members:     map[string][]groupMember{},
+		prefixLists: map[string]cloud.PrefixListReference{},
+	}
+
+	records, err := c.ListByPath(PathSecGroup)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathSecGroup, err)
+	}
+	for _, record := range records {
+		var payload map[string]interface{}
+		switch v := cloud.Plain(record.Payload).(type) {
+		case map[string]interface{}:
+			payload = v
+		case []interface{}:
+			// 历史数据以 [DescribeSecurityGroupsOutput] 形式存储
+			if len(v) > 0 {
+				payload, _ = cloud.ToMap(v[0])
+			}
+		}
+		groups := cloud.Maps(payload, "securityGroups")
+		if len(groups) == 0 && payload != nil {
+			groups = []map[string]interface{}{payload}
+		}
+		for _, sg := range groups {
+			if id := cloud.String(sg, "groupId"); id != "" {
+				sgs.groups[id] = sg
+			}
+		}
+	}
+
+	// 安全组成员：独立采集的 ENI 优先，实例网卡作为补充
+	seen := map[string]struct{}{}
+	for _, path := range []string{PathENI, PathInstance} {
+		records, err := c.ListByPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", path, err)
+		}
+		for _, record := range records {
+			payload := cloud.PayloadOf(record)
+			nics := cloud.Maps(payload, "networkInterfaces")
+			if path == PathENI {
+				nics = []map[string]interface{}{payload}
+			}
+			for _, nic := range nics {
+				sgs.addMember(nic, seen)
+			}
+		}
+	}
+
+	records, err = c.ListByPath(PathPrefixList)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathPrefixList, err)
+	}
+	for _, record := range records {
+		payload := cloud.PayloadOf(record)
+		id := cloud.String(payload, "prefixListId")
+		if id == "" {
+			continue
+		}
+		pl := cloud.PrefixListReference{
+			PrefixListID: id,
+			Name:         cloud.String(payload, "prefixListName"),
+			AWSManaged:   cloud.String(payload, "ownerId") == "AWS",
+			Cidrs:        cloud.Strings(payload, "cidrs"),
+			Resolved:     true,
+		}
+		// 托管前缀列表的条目来自 GetManagedPrefixListEntries
+		for _, e := range cloud.Maps(payload, "entries") {
+			if cidr := cloud.String(e, "cidr"); cidr != "" {
+				pl.Cidrs = cloud.AppendUnique(pl.Cidrs, cidr)
+			}
+		}
+		sgs.prefixLists[id] = pl
+	}
+	return sgs, nil
+}
+
+func (s *SecurityGroups) addMember(nic map[string]interface{}, seen map[string]struct{}) {
+	eni := cloud.String(nic, "networkInterfaceId")
+	if eni == "" {
+		return
+	}
+	if _, ok := seen[eni]; ok {
+		return
+	}
+	seen[eni] = struct{}{}
+
+	m := groupMember{eni: eni}
+	for _, pi := range cloud.Maps(nic, "privateIpAddresses") {
+		if ip := cloud.String(pi, "privateIpAddress"); ip != "" {
+			m.ips = cloud.AppendUnique(m.ips, ip)
+		}
+	}
+	if ip := cloud.String(nic, "privateIpAddress"); ip != "" {
+		m.ips = cloud.AppendUnique(m.ips, ip)
+	}
+	for _, g := range cloud.Maps(nic, "groups") {
+		if id := cloud.String(g, "groupId"); id != "" {
+			s.members[id] = append(s.members[id], m)
+		}
+	}
+}
+
+// Len 返回已加载的安全组数量
+func (s *SecurityGroups) Len() int {
+	return len(s.groups)
+}
+
+// Rules 汇总多个安全组的入方向规则，ports 非空时只保留覆盖这些端口的规则
+func (s *SecurityGroups) Rules(groupIDs []string, ports []int) []cloud.Rule {
+	var out []cloud.Rule
+	for _, id := range groupIDs {
+		for _, r := range s.IngressRules(id) {
+			if len(ports) == 0 {
+				out = append(out, r)
+				continue
+			}
+			for _, p := range ports {
+				if r.Covers(p) {
+					out = append(out, r)
+					break
+				}
+			}
+		}
+	}
+	return out
+}
+
+// IngressRules 解析单个安全组的入方向规则，并展开安全组引用与前缀列表
+func (s *SecurityGroups) IngressRules(groupID string) []cloud.Rule {
+	sg, ok := s.groups[groupID]
+	if !ok {
+		return nil
+	}
+	var rules []cloud.Rule
+	for _, perm := range cloud.Maps(sg, "ipPermissions") {
+		proto := cloud.String(perm, "ipProtocol")
+		from, _ := cloud.Int(perm, "fromPort")
+		to, _ := cloud.Int(perm, "toPort")
+		if proto == "-1" {
+			from, to = 0, 65535
+		}
+
+		r := cloud.Rule{
+			GroupID:  groupID,
+			FromPort: from,
+			ToPort:   to,
+			Protocol: proto,
+		}
+		// ipranges 可能为 null
+		for _, ip := range cloud.Maps(perm, "ipRanges") {
+			if cidr := cloud.String(ip, "cidrIp"); cidr != "" {
+				r.Iprange = append(r.Iprange, cidr)
+			}
+		}
+		for _, ip := range cloud.Maps(perm, "ipv6Ranges") {
+			if cidr := cloud.String(ip, "cidrIpv6"); cidr != "" {
+				r.Iprange = append(r.Iprange, cidr)
+			}
+		}
+		for _, pair := range cloud.Maps(perm, "userIdGroupPairs") {
+			r.SourceGroups = append(r.SourceGroups, s.resolveGroup(pair))
+		}
+		for _, p := range cloud.Maps(perm, "prefixListIds") {
+			r.PrefixLists = append(r.PrefixLists, s.resolvePrefixList(cloud.String(p, "prefixListId")))
+		}
+		r.Exposure = cloud.ClassifyCIDRs(r.Sources())
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+func (s *SecurityGroups) resolveGroup(pair map[string]interface{}) cloud.GroupReference {
+	ref := cloud.GroupReference{
+		GroupID:   cloud.String(pair, "groupId"),
+		UserID:    cloud.String(pair, "userId"),
+		PeeringID: cloud.String(pair, "vpcPeeringConnectionId"),
+		Members:   []string{},
+		IPs:       []string{},
+	}
+	_, known := s.groups[ref.GroupID]
+	members, hasMembers := s.members[ref.GroupID]
+	ref.Resolved = known || hasMembers
+	for _, m := range members {
+		ref.Members = cloud.AppendUnique(ref.Members, m.eni)
+		for _, ip := range m.ips {
+			ref.IPs = cloud.AppendUnique(ref.IPs, ip)
+		}
+	}
+	sort.Strings(ref.Members)
+	sort.Strings(ref.IPs)
+	return ref
+}
+
+func (s *SecurityGroups) resolvePrefixList(id string) cloud.PrefixListReference {
+	if pl, ok := s.prefixLists[id]; ok {
+		return pl
+	}
+	return cloud.PrefixListReference{PrefixListID: id, Cidrs: []string{}}
+}
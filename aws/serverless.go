@@ -0,0 +1,469 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/colin-404/logx"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+	"github.com/xid-protocol/xidp/protocols"
+)
+
+// 采集器写入 aws_info 的 serverless 资源路径
+const (
+	PathAPIGatewayRest   = "/info/aws/apigateway"
+	PathAPIGatewayV2     = "/info/aws/apigatewayv2"
+	PathAPIGatewayDomain = "/info/aws/apigateway-domain"
+	PathLambdaURL        = "/info/aws/lambda-url"
+	PathAppRunner        = "/info/aws/apprunner"
+
+	PathEndpointAttackSurface = "/protocols/external-attack-surface/aws-endpoint"
+)
+
+// 基于 URL 的资源类型
+const (
+	EndpointAPIGatewayREST      = "apigateway-rest"
+	EndpointAPIGatewayHTTP      = "apigateway-http"
+	EndpointAPIGatewayWebSocket = "apigateway-websocket"
+	EndpointLambdaURL           = "lambda-url"
+	EndpointAppRunner           = "apprunner"
+)
+
+const (
+	AuthNone  = "NONE"
+	AuthMixed = "MIXED"
+)
+
+// EndpointAttackSurface serverless 入口的解析结果，转为 Asset 后写入
+// path /protocols/external-attack-surface/aws-endpoint
+type EndpointAttackSurface struct {
+	ResourceID       string   `json:"resourceId"`
+	ResourceType     string   `json:"resourceType"`
+	Name             string   `json:"name"`
+	Region           string   `json:"region"`
+	URLs             []string `json:"urls"`
+	CustomDomains    []string `json:"customDomains"`
+	AuthType         string   `json:"authType"`
+	Authorizers      []string `json:"authorizers"`
+	PolicyRestricted bool     `json:"policyRestricted"`
+	PolicyConditions []string `json:"policyConditions"`
+	Public           bool     `json:"public"`
+	Unauthenticated  bool     `json:"unauthenticated"`
+}
+
+// GetServerlessEndpoints 汇总 API Gateway、Lambda function URL 与 App Runner 的公网入口
+func (c *AWSCloud) GetServerlessEndpoints() ([]*EndpointAttackSurface, error) {
+	domainRecords, err := c.ListByPath(PathAPIGatewayDomain)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathAPIGatewayDomain, err)
+	}
+	domains := buildCustomDomainMap(domainRecords)
+
+	var out []*EndpointAttackSurface
+	parsers := []struct {
+		path  string
+		parse func(map[string]interface{}, map[string][]string) *EndpointAttackSurface
+	}{
+		{PathAPIGatewayRest, parseRestAPI},
+		{PathAPIGatewayV2, parseAPIV2},
+		{PathLambdaURL, parseLambdaURL},
+		{PathAppRunner, parseAppRunner},
+	}
+	for _, p := range parsers {
+		records, err := c.ListByPath(p.path)
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", p.path, err)
+		}
+		for _, record := range records {
+			payload := cloud.PayloadOf(record)
+			if payload == nil {
+				continue
+			}
+			if ep := p.parse(payload, domains); ep != nil {
+				out = append(out, ep)
+			}
+		}
+	}
+
+	var unauth int
+	for _, ep := range out {
+		if ep.Public && ep.Unauthenticated {
+			unauth++
+		}
+	}
+	logx.Infof("serverless endpoints summary: total=%d, public unauthenticated=%d", len(out), unauth)
+	return out, nil
+}
+
+// RuleEndpointUnauthenticated 公网入口存在无需鉴权即可调用的路由或方法
+const RuleEndpointUnauthenticated = "endpoint-unauthenticated"
+
+// Asset 转为统一的资产记录，公网入口按 HTTPS 443 生成规则，资源策略限制来源时记为 restricted
+func (ep *EndpointAttackSurface) Asset() *cloud.Asset {
+	as := &cloud.Asset{
+		Provider:     ProviderName,
+		InstanceID:   ep.ResourceID,
+		InstanceName: ep.Name,
+		ResourceType: ep.ResourceType,
+		Region:       ep.Region,
+		Tags:         map[string]string{},
+		Public:       ep.Public,
+		Auth:         ep.AuthType,
+	}
+	if len(ep.URLs) > 0 {
+		if u, err := url.Parse(ep.URLs[0]); err == nil {
+			as.Endpoint = u.Hostname()
+		}
+	}
+	if ep.Public {
+		rule := endpointRule(443, []string{cloud.ExposureIprange})
+		if ep.PolicyRestricted {
+			rule.Iprange, rule.Exposure = nil, cloud.ExposureRestricted
+		}
+		as.Rules = append(as.Rules, rule)
+	}
+	as.Evaluate()
+	return as
+}
+
+// EndpointAssets 将 serverless 入口转为资产记录
+func EndpointAssets(items []*EndpointAttackSurface) []*cloud.Asset {
+	out := make([]*cloud.Asset, 0, len(items))
+	for _, ep := range items {
+		out = append(out, ep.Asset())
+	}
+	return out
+}
+
+// EndpointFindings 公网可达且存在未鉴权入口时产生问题；App Runner 的鉴权由应用自身负责，
+// 资源策略限制了调用来源时同样降低等级
+func EndpointFindings(items []*EndpointAttackSurface) []*finding.Finding {
+	var out []*finding.Finding
+	for _, ep := range items {
+		if !ep.Public || !ep.Unauthenticated {
+			continue
+		}
+		sev := finding.SeverityHigh
+		if ep.ResourceType == EndpointAppRunner {
+			sev = finding.SeverityMedium
+		}
+		if ep.PolicyRestricted {
+			sev = finding.SeverityLow
+		}
+		target := ep.ResourceID
+		if len(ep.URLs) > 0 {
+			target = ep.URLs[0]
+		}
+		f := finding.New(RuleEndpointUnauthenticated, sev, ep.Asset(), target, "Public endpoint allows unauthenticated access",
+			fmt.Sprintf("%s %s is reachable from the internet with authorization type %s", ep.ResourceType, cloud.FirstString(ep.Name, ep.ResourceID), ep.AuthType))
+		f.FromPort, f.ToPort = 443, 443
+		f.Evidence["authType"] = ep.AuthType
+		if len(ep.URLs) > 0 {
+			f.Evidence["urls"] = strings.Join(ep.URLs, ",")
+		}
+		if len(ep.PolicyConditions) > 0 {
+			f.Evidence["policyConditions"] = strings.Join(ep.PolicyConditions, ",")
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// buildCustomDomainMap 返回 apiId -> 自定义域名
+func buildCustomDomainMap(records []*protocols.XID) map[string][]string {
+	out := map[string][]string{}
+	for _, record := range records {
+		payload := cloud.PayloadOf(record)
+		name := cloud.String(payload, "domainName")
+		if name == "" {
+			continue
+		}
+		for _, m := range cloud.Maps(payload, "apiMappings") {
+			if id := cloud.String(m, "apiId"); id != "" {
+				out[id] = cloud.AppendUnique(out[id], name)
+			}
+		}
+		for _, m := range cloud.Maps(payload, "basePathMappings") {
+			if id := cloud.String(m, "restApiId"); id != "" {
+				out[id] = cloud.AppendUnique(out[id], name)
+			}
+		}
+	}
+	return out
+}
+
+func parseRestAPI(payload map[string]interface{}, domains map[string][]string) *EndpointAttackSurface {
+	id := cloud.String(payload, "id")
+	if id == "" {
+		return nil
+	}
+	region := cloud.String(payload, "region")
+	ep := &EndpointAttackSurface{
+		ResourceID:    id,
+		ResourceType:  EndpointAPIGatewayREST,
+		Name:          cloud.String(payload, "name"),
+		Region:        region,
+		CustomDomains: domains[id],
+		Public:        true,
+	}
+	for _, t := range cloud.Strings(cloud.Map(payload, "endpointConfiguration"), "types") {
+		if strings.EqualFold(t, "PRIVATE") {
+			ep.Public = false
+		}
+	}
+	if !cloud.Bool(payload, "disableExecuteApiEndpoint") {
+		for _, stage := range cloud.Maps(payload, "stages") {
+			if name := cloud.String(stage, "stageName"); name != "" {
+				ep.URLs = append(ep.URLs, fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com/%s", id, region, name))
+			}
+		}
+	}
+	for _, d := range ep.CustomDomains {
+		ep.URLs = append(ep.URLs, "https://"+d)
+	}
+
+	for _, a := range cloud.Maps(payload, "authorizers") {
+		ep.Authorizers = append(ep.Authorizers, authorizerLabel(cloud.String(a, "name"), cloud.String(a, "type")))
+	}
+	// 逐个方法统计授权方式，未采集方法时以是否配置 authorizer 判断
+	var authTypes []string
+	for _, res := range cloud.Maps(payload, "resources") {
+		for _, method := range cloud.MapValues(cloud.Map(res, "resourceMethods")) {
+			t := cloud.String(method, "authorizationType")
+			if t == AuthNone && cloud.Bool(method, "apiKeyRequired") {
+				t = "API_KEY"
+			}
+			authTypes = append(authTypes, t)
+		}
+	}
+	if len(authTypes) == 0 && len(ep.Authorizers) == 0 {
+		authTypes = append(authTypes, AuthNone)
+	}
+	ep.AuthType, ep.Unauthenticated = summarizeAuth(authTypes)
+	ep.PolicyRestricted, ep.PolicyConditions = analyzeResourcePolicy(cloud.String(payload, "policy"))
+	return ep
+}
+
+func parseAPIV2(payload map[string]interface{}, domains map[string][]string) *EndpointAttackSurface {
+	id := cloud.String(payload, "apiId")
+	if id == "" {
+		return nil
+	}
+	ep := &EndpointAttackSurface{
+		ResourceID:    id,
+		ResourceType:  EndpointAPIGatewayHTTP,
+		Name:          cloud.String(payload, "name"),
+		Region:        cloud.String(payload, "region"),
+		CustomDomains: domains[id],
+		Public:        true,
+	}
+	if strings.EqualFold(cloud.String(payload, "protocolType"), "WEBSOCKET") {
+		ep.ResourceType = EndpointAPIGatewayWebSocket
+	}
+	if u := cloud.String(payload, "apiEndpoint"); u != "" && !cloud.Bool(payload, "disableExecuteApiEndpoint") {
+		ep.URLs = append(ep.URLs, u)
+	}
+	for _, d := range ep.CustomDomains {
+		ep.URLs = append(ep.URLs, "https://"+d)
+	}
+
+	for _, a := range cloud.Maps(payload, "authorizers") {
+		ep.Authorizers = append(ep.Authorizers, authorizerLabel(cloud.String(a, "name"), cloud.String(a, "authorizerType")))
+	}
+	var authTypes []string
+	for _, r := range cloud.Maps(payload, "routes") {
+		authTypes = append(authTypes, cloud.String(r, "authorizationType"))
+	}
+	if len(authTypes) == 0 && len(ep.Authorizers) == 0 {
+		authTypes = append(authTypes, AuthNone)
+	}
+	ep.AuthType, ep.Unauthenticated = summarizeAuth(authTypes)
+	return ep
+}
+
+func parseLambdaURL(payload map[string]interface{}, _ map[string][]string) *EndpointAttackSurface {
+	arn := cloud.String(payload, "functionArn")
+	u := cloud.String(payload, "functionUrl")
+	if arn == "" || u == "" {
+		return nil
+	}
+	name := cloud.String(payload, "functionName")
+	if name == "" {
+		name = arn[strings.LastIndex(arn, ":")+1:]
+	}
+	ep := &EndpointAttackSurface{
+		ResourceID:   arn,
+		ResourceType: EndpointLambdaURL,
+		Name:         name,
+		Region:       regionFromARN(arn),
+		URLs:         []string{u},
+		Public:       true,
+	}
+	ep.AuthType, ep.Unauthenticated = summarizeAuth([]string{cloud.String(payload, "authType")})
+	ep.PolicyRestricted, ep.PolicyConditions = analyzeResourcePolicy(cloud.String(payload, "policy"))
+	return ep
+}
+
+func parseAppRunner(payload map[string]interface{}, _ map[string][]string) *EndpointAttackSurface {
+	arn := cloud.String(payload, "serviceArn")
+	if arn == "" {
+		return nil
+	}
+	ep := &EndpointAttackSurface{
+		ResourceID:   arn,
+		ResourceType: EndpointAppRunner,
+		Name:         cloud.String(payload, "serviceName"),
+		Region:       regionFromARN(arn),
+		Public:       true,
+	}
+	// isPubliclyAccessible 缺省为 true，仅显式关闭时视为私有
+	ingress := cloud.Map(cloud.Map(payload, "networkConfiguration"), "ingressConfiguration")
+	if v, ok := cloud.AnyCase(ingress, "isPubliclyAccessible").(bool); ok && !v {
+		ep.Public = false
+	}
+	if u := cloud.String(payload, "serviceUrl"); u != "" {
+		ep.URLs = append(ep.URLs, "https://"+strings.TrimPrefix(u, "https://"))
+	}
+	for _, d := range cloud.Maps(payload, "customDomains") {
+		if name := cloud.String(d, "domainName"); name != "" {
+			ep.CustomDomains = append(ep.CustomDomains, name)
+			ep.URLs = append(ep.URLs, "https://"+name)
+		}
+	}
+	// App Runner 没有内置鉴权，认证由应用自身负责
+	ep.AuthType, ep.Unauthenticated = summarizeAuth([]string{AuthNone})
+	return ep
+}
+
+func authorizerLabel(name, typ string) string {
+	if name == "" {
+		return typ
+	}
+	return fmt.Sprintf("%s(%s)", name, typ)
+}
+
+// summarizeAuth 合并路由/方法级别的授权方式，任一入口为 NONE 即视为存在未鉴权访问
+func summarizeAuth(types []string) (string, bool) {
+	set := map[string]struct{}{}
+	for _, t := range types {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if t == "" {
+			t = AuthNone
+		}
+		set[t] = struct{}{}
+	}
+	_, unauth := set[AuthNone]
+	if len(set) == 1 {
+		for t := range set {
+			return t, unauth
+		}
+	}
+	if len(set) == 0 {
+		return "", false
+	}
+	return AuthMixed, unauth
+}
+
+// callerConditionKeys 能够限制调用方身份或来源的条件键（小写）；
+// lambda:FunctionUrlAuthType 等键只描述调用方式，不构成来源限制
+var callerConditionKeys = map[string]bool{
+	"aws:sourceip":             true,
+	"aws:vpcsourceip":          true,
+	"aws:sourcevpc":            true,
+	"aws:sourcevpce":           true,
+	"aws:sourceaccount":        true,
+	"aws:sourcearn":            true,
+	"aws:sourceorgid":          true,
+	"aws:sourceorgpaths":       true,
+	"aws:principalorgid":       true,
+	"aws:principalorgpaths":    true,
+	"aws:principalaccount":     true,
+	"aws:principalarn":         true,
+	"aws:principalservicename": true,
+	"aws:userid":               true,
+	"aws:username":             true,
+}
+
+func isCallerConditionKey(key string) bool {
+	key = strings.ToLower(key)
+	return callerConditionKeys[key] || strings.HasPrefix(key, "aws:principaltag/")
+}
+
+// analyzeResourcePolicy 判断资源策略是否限制了调用来源，返回策略中的条件列表。
+// 只有限制调用方的条件键才计为限制，放行指定主体（非 *）的 Allow 同样视为限制
+func analyzeResourcePolicy(policy string) (bool, []string) {
+	statements := parsePolicyStatements(policy)
+	if len(statements) == 0 {
+		return false, nil
+	}
+
+	var (
+		conditions []string
+		openAllow  bool
+		limited    bool
+		denied     bool
+	)
+	for _, st := range statements {
+		effect := cloud.String(st, "Effect")
+		cond := cloud.Map(st, "Condition")
+		restricts := false
+		for _, op := range cloud.SortedKeys(cond) {
+			for _, key := range cloud.SortedKeys(cloud.Map(cond, op)) {
+				conditions = cloud.AppendUnique(conditions, fmt.Sprintf("%s:%s %s", effect, op, key))
+				if isCallerConditionKey(key) {
+					restricts = true
+				}
+			}
+		}
+		switch {
+		case strings.EqualFold(effect, "Deny"):
+			denied = denied || restricts
+		case !strings.EqualFold(effect, "Allow"):
+		case isWildcardPrincipal(cloud.AnyCase(st, "Principal")) && !restricts:
+			openAllow = true
+		default:
+			limited = true
+		}
+	}
+	// 存在放行任意调用方的 Allow 时，只有限制来源的 Deny 才构成限制
+	if openAllow {
+		return denied, conditions
+	}
+	return limited || denied, conditions
+}
+
+// parsePolicyStatements 解析 IAM 风格策略文档中的 Statement，兼容单对象与数组
+func parsePolicyStatements(policy string) []map[string]interface{} {
+	if policy == "" {
+		return nil
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		// API Gateway 返回的 policy 带有转义引号
+		if err := json.Unmarshal([]byte(strings.ReplaceAll(policy, `\"`, `"`)), &doc); err != nil {
+			logx.Warnf("parse resource policy error: %v", err)
+			return nil
+		}
+	}
+	if m, ok := cloud.ToMap(cloud.AnyCase(doc, "Statement")); ok {
+		return []map[string]interface{}{m}
+	}
+	return cloud.Maps(doc, "Statement")
+}
+
+func isWildcardPrincipal(v interface{}) bool {
+	if s, ok := v.(string); ok {
+		return s == "*"
+	}
+	if m, ok := cloud.ToMap(v); ok {
+		for _, p := range cloud.Strings(m, "AWS") {
+			if p == "*" {
+				return true
+			}
+		}
+	}
+	return false
+}
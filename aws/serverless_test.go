@@ -0,0 +1,59 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// 控制台或 CreateFunctionUrlConfig 在 AuthType 为 NONE 时自动添加的策略
+const lambdaURLPublicPolicy = `{"Version":"2012-10-17","Id":"default","Statement":[{"Sid":"FunctionURLAllowPublicAccess",` +
+	`"Effect":"Allow","Principal":"*","Action":"lambda:InvokeFunctionUrl",` +
+	`"Resource":"arn:aws:lambda:us-east-1:123456789012:function:my-function",` +
+	`"Condition":{"StringEquals":{"lambda:FunctionUrlAuthType":"NONE"}}}]}`
+
+func TestAnalyzeResourcePolicy(t *testing.T) {
+	for name, tc := range map[string]struct {
+		policy  string
+		limited bool
+	}{
+		"empty":           {"", false},
+		"lambda url none": {lambdaURLPublicPolicy, false},
+		"source ip": {`{"Statement":[{"Effect":"Allow","Principal":"*","Action":"lambda:InvokeFunctionUrl",
+			"Condition":{"StringEquals":{"lambda:FunctionUrlAuthType":"NONE"},"IpAddress":{"aws:SourceIp":"203.0.113.0/24"}}}]}`, true},
+		"deny outside vpce": {`{"Statement":[{"Effect":"Allow","Principal":"*","Action":"execute-api:Invoke","Resource":"*"},
+			{"Effect":"Deny","Principal":"*","Action":"execute-api:Invoke","Resource":"*","Condition":{"StringNotEquals":{"aws:SourceVpce":"vpce-1"}}}]}`, true},
+		"deny without caller key": {`{"Statement":[{"Effect":"Allow","Principal":"*","Action":"execute-api:Invoke","Resource":"*"},
+			{"Effect":"Deny","Principal":"*","Action":"execute-api:Invoke","Resource":"*","Condition":{"Bool":{"aws:SecureTransport":"false"}}}]}`, false},
+		"principal org": {`{"Statement":[{"Effect":"Allow","Principal":"*","Action":"lambda:InvokeFunctionUrl",
+			"Condition":{"StringEquals":{"aws:PrincipalOrgID":"o-abc"}}}]}`, true},
+		"specific account": {`{"Statement":{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::123456789012:root"},"Action":"lambda:InvokeFunctionUrl"}}`, true},
+		"escaped api gateway": {`{\"Version\":\"2012-10-17\",\"Statement\":[{\"Effect\":\"Allow\",\"Principal\":\"*\",\"Action\":\"execute-api:Invoke\",` +
+			`\"Condition\":{\"IpAddress\":{\"aws:SourceIp\":\"198.51.100.0/24\"}}}]}`, true},
+	} {
+		if limited, _ := analyzeResourcePolicy(tc.policy); limited != tc.limited {
+			t.Errorf("%s: limited = %v, want %v", name, limited, tc.limited)
+		}
+	}
+}
+
+func TestLambdaURLWithoutAuthIsHigh(t *testing.T) {
+	ep := parseLambdaURL(map[string]interface{}{
+		"functionArn": "arn:aws:lambda:us-east-1:123456789012:function:my-function",
+		"functionUrl": "https://abcdefg.lambda-url.us-east-1.on.aws/",
+		"authType":    "NONE",
+		"policy":      lambdaURLPublicPolicy,
+	}, nil)
+	if ep.PolicyRestricted || !ep.Unauthenticated {
+		t.Fatalf("unexpected endpoint %+v", ep)
+	}
+	a := ep.Asset()
+	if len(a.Rules) != 1 || a.Rules[0].Exposure != cloud.ExposureInternet {
+		t.Fatalf("unexpected rules %+v", a.Rules)
+	}
+	findings := EndpointFindings([]*EndpointAttackSurface{ep})
+	if len(findings) != 1 || findings[0].Severity != finding.SeverityHigh {
+		t.Fatalf("unexpected findings %+v", findings)
+	}
+}
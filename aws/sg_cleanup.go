@@ -0,0 +1,325 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/colin-404/logx"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// 安全组清理相关的问题规则
+const (
+	RuleDefaultSGWithRules = "sg-default-with-rules"
+	RuleUnusedSG           = "sg-unused"
+	RuleRedundantRule      = "sg-redundant-rule"
+	RuleStaleReference     = "sg-stale-reference"
+)
+
+const ResourceSecurityGroup = "security-group"
+
+// 采集器写入的 DescribeStaleSecurityGroups 与 DescribeVpcPeeringConnections 结果
+const (
+	PathStaleSecGroup = "/info/aws/stale-secgroup"
+	PathVPCPeering    = "/info/aws/vpc-peering"
+)
+
+// peeringRefs 经 VPC 对等连接的安全组引用状态。同一 VPC 内被引用的安全组无法删除，
+// 失效引用只出现在对等连接场景：对端安全组被删除或对等连接已失效
+type peeringRefs struct {
+	// stale 安全组 -> 失效引用的安全组 -> 对等连接状态，来自 DescribeStaleSecurityGroups
+	stale map[string]map[string]string
+	// status 对等连接 ID -> 状态（active、deleted、expired 等）
+	status map[string]string
+}
+
+// loadPeeringRefs 加载失效引用与对等连接状态，兼容单条记录与 Describe* 输出两种存储形式
+func (c *AWSCloud) loadPeeringRefs() (*peeringRefs, error) {
+	refs := &peeringRefs{stale: map[string]map[string]string{}, status: map[string]string{}}
+	records, err := c.ListByPath(PathStaleSecGroup)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathStaleSecGroup, err)
+	}
+	for _, record := range records {
+		for _, sg := range payloadItems(record.Payload, "staleSecurityGroupSet") {
+			id := cloud.String(sg, "groupId")
+			if id == "" {
+				continue
+			}
+			for _, perm := range append(cloud.Maps(sg, "staleIpPermissions"), cloud.Maps(sg, "staleIpPermissionsEgress")...) {
+				for _, pair := range cloud.Maps(perm, "userIdGroupPairs") {
+					ref := cloud.String(pair, "groupId")
+					if ref == "" {
+						continue
+					}
+					if refs.stale[id] == nil {
+						refs.stale[id] = map[string]string{}
+					}
+					refs.stale[id][ref] = cloud.String(pair, "peeringStatus")
+				}
+			}
+		}
+	}
+	records, err = c.ListByPath(PathVPCPeering)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathVPCPeering, err)
+	}
+	for _, record := range records {
+		for _, pcx := range payloadItems(record.Payload, "vpcPeeringConnections") {
+			if id := cloud.String(pcx, "vpcPeeringConnectionId"); id != "" {
+				refs.status[id] = strings.ToLower(cloud.String(cloud.Map(pcx, "status"), "code"))
+			}
+		}
+	}
+	return refs, nil
+}
+
+// payloadItems 记录为 Describe* 输出（或其列表）时取 key 下的条目，否则记录本身即为一条
+func payloadItems(payload interface{}, key string) []map[string]interface{} {
+	var out []map[string]interface{}
+	var add func(v interface{})
+	add = func(v interface{}) {
+		switch t := cloud.Plain(v).(type) {
+		case map[string]interface{}:
+			if items := cloud.Maps(t, key); len(items) > 0 {
+				out = append(out, items...)
+			} else if _, ok := t[key]; !ok {
+				out = append(out, t)
+			}
+		case []interface{}:
+			for _, item := range t {
+				add(item)
+			}
+		}
+	}
+	add(payload)
+	return out
+}
+
+// reason 经对等连接的引用是否失效，返回原因；无法判断时为空
+func (p *peeringRefs) reason(id, ref, pcx string) string {
+	if p == nil {
+		return ""
+	}
+	if status, ok := p.stale[id][ref]; ok {
+		return fmt.Sprintf("%s which is stale across VPC peering (peering status %s)", ref, cloud.FirstString(status, "unknown"))
+	}
+	if status, ok := p.status[pcx]; ok && status != "" && status != "active" && status != "provisioning" {
+		return fmt.Sprintf("%s through %s which is %s", ref, pcx, status)
+	}
+	return ""
+}
+
+// sgEntry 入方向规则按来源拆分后的单条放行
+type sgEntry struct {
+	protocol string
+	from, to int
+	source   string // CIDR、sg:<id> 或 pl:<id>
+}
+
+func (e sgEntry) String() string {
+	return fmt.Sprintf("%s/%d-%d from %s", e.protocol, e.from, e.to, e.source)
+}
+
+// covers 协议、端口范围与来源均覆盖另一条放行
+func (e sgEntry) covers(o sgEntry) bool {
+	if e.protocol != "-1" && e.protocol != o.protocol {
+		return false
+	}
+	if e.from > o.from || e.to < o.to {
+		return false
+	}
+	if strings.Contains(e.source, ":") || strings.Contains(o.source, ":") {
+		return e.source == o.source
+	}
+	return cloud.CIDRCovers(e.source, o.source)
+}
+
+// SecurityGroupCleanup 分析全部安全组，列出带规则的默认安全组、未绑定网卡的安全组、
+// 被其他规则覆盖的冗余规则以及引用已删除安全组的规则
+func (c *AWSCloud) SecurityGroupCleanup() ([]*finding.Finding, error) {
+	sgs, err := c.loadSecurityGroups()
+	if err != nil {
+		return nil, err
+	}
+	peering, err := c.loadPeeringRefs()
+	if err != nil {
+		return nil, err
+	}
+	var out []*finding.Finding
+	for _, id := range sortedGroupIDs(sgs) {
+		out = append(out, sgs.cleanup(id, peering)...)
+	}
+	finding.Sort(out)
+	logx.Infof("security group cleanup summary: securityGroups=%d, findings=%d", sgs.Len(), len(out))
+	return out, nil
+}
+
+func (s *SecurityGroups) cleanup(id string, peering *peeringRefs) []*finding.Finding {
+	sg := s.groups[id]
+	owner := cloud.String(sg, "ownerId")
+	as := &cloud.Asset{
+		Provider:     ProviderName,
+		InstanceID:   id,
+		InstanceName: cloud.String(sg, "groupName"),
+		ResourceType: ResourceSecurityGroup,
+		VpcID:        cloud.String(sg, "vpcId"),
+		Tags:         cloud.Tags(cloud.AnyCase(sg, "tags")),
+		GroupIDs:     []string{id},
+	}
+	var out []*finding.Finding
+	add := func(rule string, sev finding.Severity, target, title, detail string) *finding.Finding {
+		f := finding.New(rule, sev, as, target, title, detail)
+		f.GroupID = id
+		if owner != "" {
+			f.Evidence["account"] = owner
+		}
+		out = append(out, f)
+		return f
+	}
+
+	inbound := cloud.Maps(sg, "ipPermissions")
+	egress := cloud.Maps(sg, "ipPermissionsEgress")
+	isDefault := as.InstanceName == "default"
+	if isDefault && (len(inbound) > 0 || len(egress) > 0) {
+		f := add(RuleDefaultSGWithRules, finding.SeverityMedium, id, "Default security group has rules",
+			fmt.Sprintf("default security group has %d inbound and %d outbound rules", len(inbound), len(egress)))
+		f.Evidence["inbound"] = fmt.Sprint(len(inbound))
+		f.Evidence["outbound"] = fmt.Sprint(len(egress))
+	}
+	// 默认安全组无法删除，不列为未使用
+	if !isDefault && len(s.members[id]) == 0 {
+		f := add(RuleUnusedSG, finding.SeverityLow, id, "Security group not attached to any network interface",
+			"no ENI or instance network interface references this security group")
+		if refs := s.referencedBy(id); len(refs) > 0 {
+			f.Evidence["referencedBy"] = strings.Join(refs, ",")
+		}
+	}
+
+	entries := s.entries(sg)
+	for i, e := range entries {
+		for j, o := range entries {
+			if i == j || !o.covers(e) {
+				continue
+			}
+			// 完全相同的两条只报后一条
+			if e == o && j > i {
+				continue
+			}
+			f := add(RuleRedundantRule, finding.SeverityInfo, id+" "+e.String(), "Redundant security group rule",
+				fmt.Sprintf("%s is already allowed by %s", e, o))
+			f.FromPort, f.ToPort = e.from, e.to
+			f.Evidence["coveredBy"] = o.String()
+			break
+		}
+	}
+
+	reported := map[string]bool{}
+	for _, dir := range []struct {
+		name  string
+		perms []map[string]interface{}
+	}{{"inbound", inbound}, {"outbound", egress}} {
+		for _, perm := range dir.perms {
+			for _, pair := range cloud.Maps(perm, "userIdGroupPairs") {
+				ref := cloud.String(pair, "groupId")
+				if ref == "" {
+					continue
+				}
+				var reason string
+				if pcx := cloud.String(pair, "vpcPeeringConnectionId"); pcx != "" {
+					reason = peering.reason(id, ref, pcx)
+				} else if user := cloud.String(pair, "userId"); user != "" && owner != "" && user != owner {
+					// 跨账号引用无法判断是否存在
+					continue
+				} else if _, ok := s.groups[ref]; !ok {
+					reason = ref + " which no longer exists"
+				}
+				if reason == "" {
+					continue
+				}
+				target := id + " sg:" + ref
+				if dir.name == "outbound" {
+					target = id + " egress sg:" + ref
+				}
+				if reported[target] {
+					continue
+				}
+				reported[target], reported[ref] = true, true
+				f := add(RuleStaleReference, finding.SeverityLow, target, "Security group rule references a deleted group",
+					fmt.Sprintf("%s rule references %s", dir.name, reason))
+				f.Evidence["referencedGroup"] = ref
+				if pcx := cloud.String(pair, "vpcPeeringConnectionId"); pcx != "" {
+					f.Evidence["peeringConnection"] = pcx
+				}
+			}
+		}
+	}
+	// 失效引用清单中有、但当前规则中未出现的引用（安全组数据与清单采集时间不同）
+	if peering != nil {
+		for ref, status := range peering.stale[id] {
+			if reported[ref] {
+				continue
+			}
+			f := add(RuleStaleReference, finding.SeverityLow, id+" sg:"+ref, "Security group rule references a deleted group",
+				fmt.Sprintf("rule references %s which is stale across VPC peering (peering status %s)", ref, cloud.FirstString(status, "unknown")))
+			f.Evidence["referencedGroup"] = ref
+		}
+	}
+	return out
+}
+
+// entries 将入方向权限按来源拆分
+func (s *SecurityGroups) entries(sg map[string]interface{}) []sgEntry {
+	var out []sgEntry
+	for _, perm := range cloud.Maps(sg, "ipPermissions") {
+		proto := strings.ToLower(cloud.String(perm, "ipProtocol"))
+		from, _ := cloud.Int(perm, "fromPort")
+		to, _ := cloud.Int(perm, "toPort")
+		if proto == "-1" {
+			from, to = 0, 65535
+		}
+		add := func(source string) {
+			out = append(out, sgEntry{protocol: proto, from: from, to: to, source: source})
+		}
+		for _, ip := range cloud.Maps(perm, "ipRanges") {
+			if cidr := cloud.String(ip, "cidrIp"); cidr != "" {
+				add(cidr)
+			}
+		}
+		for _, ip := range cloud.Maps(perm, "ipv6Ranges") {
+			if cidr := cloud.String(ip, "cidrIpv6"); cidr != "" {
+				add(cidr)
+			}
+		}
+		for _, pair := range cloud.Maps(perm, "userIdGroupPairs") {
+			if ref := cloud.String(pair, "groupId"); ref != "" {
+				add("sg:" + ref)
+			}
+		}
+		for _, p := range cloud.Maps(perm, "prefixListIds") {
+			if ref := cloud.String(p, "prefixListId"); ref != "" {
+				add("pl:" + ref)
+			}
+		}
+	}
+	return out
+}
+
+// referencedBy 返回在规则中引用该安全组的其他安全组
+func (s *SecurityGroups) referencedBy(id string) []string {
+	var out []string
+	for _, other := range sortedGroupIDs(s) {
+		if other == id {
+			continue
+		}
+		for _, perm := range append(cloud.Maps(s.groups[other], "ipPermissions"), cloud.Maps(s.groups[other], "ipPermissionsEgress")...) {
+			for _, pair := range cloud.Maps(perm, "userIdGroupPairs") {
+				if cloud.String(pair, "groupId") == id {
+					out = cloud.AppendUnique(out, other)
+				}
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package azure
+
+import (
+	"github.com/colin-404/logx"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/xidp/protocols"
+)
+
+const ProviderName = "azure"
+
+// 采集器写入 azure_info 的资源路径
+const (
+	PathVM         = "/info/azure/vm"
+	PathNIC        = "/info/azure/nic"
+	PathPublicIP   = "/info/azure/public-ip"
+	PathNSG        = "/info/azure/nsg"
+	PathAppGateway = "/info/azure/application-gateway"
+
+	PathInstanceAttackSurface = "/protocols/external-attack-surface/azure-instance"
+)
+
+type AzureCloud struct {
+	*cloud.Store
+}
+
+func init() {
+	cloud.Register(ProviderName, func() cloud.CloudProvider { return NewAzureCloud() })
+}
+
+func NewAzureCloud() *AzureCloud {
+	// 连接 Mongo 并获取 azure_info 集合
+	return &AzureCloud{
+		Store: cloud.NewStore("azure_info"),
+	}
+}
+
+func (c *AzureCloud) Name() string {
+	return ProviderName
+}
+
+// ListAssets 返回虚拟机、应用网关与未绑定的公网 IP
+func (c *AzureCloud) ListAssets() ([]*cloud.Asset, error) {
+	inv, err := c.loadInventory()
+	if err != nil {
+		return nil, err
+	}
+	vms, err := c.GetVirtualMachines(inv)
+	if err != nil {
+		return nil, err
+	}
+	gateways, err := c.GetApplicationGateways(inv)
+	if err != nil {
+		return nil, err
+	}
+	addresses := inv.unattachedPublicIPs()
+
+	out := append(vms, gateways...)
+	out = append(out, addresses...)
+	var exposed int
+	for _, a := range out {
+		if cloud.IsWideExposure(a.Exposure) {
+			exposed++
+		}
+	}
+	logx.Infof("azure attack surface summary: vms=%d, appGateways=%d, publicIps=%d, nsgs=%d, exposed=%d",
+		len(vms), len(gateways), len(addresses), len(inv.nsgs), exposed)
+	return out, nil
+}
+
+func (c *AzureCloud) PublicIPs() (map[string][]string, error) {
+	assets, err := c.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.PublicIPsOf(assets), nil
+}
+
+func (c *AzureCloud) FirewallRules() (map[string][]cloud.Rule, error) {
+	assets, err := c.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.RulesOf(assets), nil
+}
+
+func (c *AzureCloud) Collect() ([]*protocols.XID, error) {
+	assets, err := c.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.AssetXIDs(assets, PathInstanceAttackSurface), nil
+}
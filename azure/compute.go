@@ -0,0 +1,394 @@
+package azure
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// nic 网卡及其 IP 配置
+type nic struct {
+	id         string
+	vmID       string
+	nsgID      string
+	subnetIDs  []string
+	privateIPs []string
+	publicIPs  []string
+	// basicSKU 绑定了 Basic SKU 公网 IP，未关联 NSG 时默认放行全部入站
+	basicSKU bool
+}
+
+type publicIP struct {
+	id       string
+	name     string
+	location string
+	address  string
+	sku      string
+	attached bool
+	tags     map[string]string
+}
+
+// inventory NIC、公网 IP 与 NSG 索引，资源 ID 统一转为小写
+type inventory struct {
+	nics      map[string]*nic
+	publicIPs map[string]*publicIP
+	nsgs      map[string]*securityGroup
+	subnetNSG map[string]string
+	// rules 每个 NSG 的有效放行规则
+	rules map[string][]cloud.Rule
+}
+
+func (c *AzureCloud) loadInventory() (*inventory, error) {
+	inv := &inventory{
+		nics:      map[string]*nic{},
+		publicIPs: map[string]*publicIP{},
+		nsgs:      map[string]*securityGroup{},
+		subnetNSG: map[string]string{},
+		rules:     map[string][]cloud.Rule{},
+	}
+
+	records, err := c.ListByPath(PathPublicIP)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathPublicIP, err)
+	}
+	for _, record := range records {
+		payload := cloud.PayloadOf(record)
+		id := cloud.String(payload, "id")
+		if id == "" {
+			continue
+		}
+		p := props(payload)
+		inv.publicIPs[strings.ToLower(id)] = &publicIP{
+			id:       id,
+			name:     cloud.String(payload, "name"),
+			location: cloud.String(payload, "location"),
+			address:  cloud.String(p, "ipAddress"),
+			sku:      cloud.String(cloud.Map(payload, "sku"), "name"),
+			attached: cloud.Map(p, "ipConfiguration") != nil,
+			tags:     cloud.Tags(cloud.AnyCase(payload, "tags")),
+		}
+	}
+
+	// 应用安全组成员：网卡 IP 配置中引用的 ASG
+	asgs := map[string]cloud.GroupReference{}
+	records, err = c.ListByPath(PathNIC)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathNIC, err)
+	}
+	for _, record := range records {
+		payload := cloud.PayloadOf(record)
+		id := cloud.String(payload, "id")
+		if id == "" {
+			continue
+		}
+		p := props(payload)
+		n := &nic{
+			id:    id,
+			vmID:  strings.ToLower(cloud.String(cloud.Map(p, "virtualMachine"), "id")),
+			nsgID: strings.ToLower(cloud.String(cloud.Map(p, "networkSecurityGroup"), "id")),
+		}
+		for _, ipc := range cloud.Maps(p, "ipConfigurations") {
+			ip := props(ipc)
+			if addr := cloud.String(ip, "privateIPAddress"); addr != "" {
+				n.privateIPs = cloud.AppendUnique(n.privateIPs, addr)
+			}
+			if subnet := cloud.String(cloud.Map(ip, "subnet"), "id"); subnet != "" {
+				n.subnetIDs = cloud.AppendUnique(n.subnetIDs, strings.ToLower(subnet))
+			}
+			if pip, ok := inv.publicIPs[strings.ToLower(cloud.String(cloud.Map(ip, "publicIPAddress"), "id"))]; ok && pip.address != "" {
+				n.publicIPs = cloud.AppendUnique(n.publicIPs, pip.address)
+				n.basicSKU = n.basicSKU || pip.basic()
+			}
+			for _, asg := range cloud.Maps(ip, "applicationSecurityGroups") {
+				asgID := strings.ToLower(cloud.String(asg, "id"))
+				ref, ok := asgs[asgID]
+				if !ok {
+					ref = cloud.GroupReference{GroupID: asgID, Members: []string{}, IPs: []string{}, Resolved: true}
+				}
+				ref.Members = cloud.AppendUnique(ref.Members, id)
+				for _, addr := range n.privateIPs {
+					ref.IPs = cloud.AppendUnique(ref.IPs, addr)
+				}
+				asgs[asgID] = ref
+			}
+		}
+		inv.nics[strings.ToLower(id)] = n
+	}
+
+	records, err = c.ListByPath(PathNSG)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathNSG, err)
+	}
+	for _, record := range records {
+		payload := cloud.PayloadOf(record)
+		nsg := parseNSG(payload)
+		if nsg == nil {
+			continue
+		}
+		key := strings.ToLower(nsg.id)
+		inv.nsgs[key] = nsg
+		inv.rules[key] = nsg.effectiveRules(asgs)
+		for _, s := range cloud.Maps(props(payload), "subnets") {
+			if id := cloud.String(s, "id"); id != "" {
+				inv.subnetNSG[strings.ToLower(id)] = key
+			}
+		}
+	}
+	return inv, nil
+}
+
+// basic 是否为 Basic SKU，早期创建、未返回 SKU 的地址按 Basic 处理
+func (p *publicIP) basic() bool {
+	return p.sku == "" || strings.EqualFold(p.sku, "Basic")
+}
+
+// rulesFor 计算网卡所在子网 NSG 与网卡 NSG 叠加后的有效规则。
+// 两级都没有 NSG 时，Basic SKU 公网 IP 默认放行全部入站，Standard SKU 默认拒绝
+func (inv *inventory) rulesFor(subnetID, nsgID string, openByDefault bool) []cloud.Rule {
+	var subnetRules, nicRules []cloud.Rule
+	// 已关联 NSG 但没有放行规则（如自定义 DenyAll）时为空切片而非 nil，表示全部拒绝而不是未配置
+	if id, ok := inv.subnetNSG[subnetID]; ok {
+		subnetRules = inv.rules[id]
+		if subnetRules == nil {
+			subnetRules = []cloud.Rule{}
+		}
+	}
+	if nsgID != "" {
+		nicRules = inv.rules[nsgID]
+		if nicRules == nil {
+			nicRules = []cloud.Rule{}
+		}
+	}
+	if subnetRules == nil && nicRules == nil {
+		if !openByDefault {
+			return nil
+		}
+		return allowAll()
+	}
+	return intersectRules(subnetRules, nicRules)
+}
+
+func (inv *inventory) nsgName(id string) string {
+	if nsg, ok := inv.nsgs[id]; ok {
+		return nsg.name
+	}
+	return lastSegment(id)
+}
+
+// GetVirtualMachines 解析虚拟机并关联网卡、公网 IP 与 NSG
+func (c *AzureCloud) GetVirtualMachines(inv *inventory) ([]*cloud.Asset, error) {
+	records, err := c.ListByPath(PathVM)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathVM, err)
+	}
+	var out []*cloud.Asset
+	for _, record := range records {
+		payload := cloud.PayloadOf(record)
+		id := cloud.String(payload, "id")
+		if id == "" {
+			continue
+		}
+		as := &cloud.Asset{
+			Provider:     ProviderName,
+			InstanceID:   id,
+			InstanceName: cloud.String(payload, "name"),
+			ResourceType: "vm",
+			Region:       cloud.String(payload, "location"),
+			Tags:         cloud.Tags(cloud.AnyCase(payload, "tags")),
+		}
+		nicIDs := map[string]struct{}{}
+		for _, ref := range cloud.Maps(cloud.Map(props(payload), "networkProfile"), "networkInterfaces") {
+			nicIDs[strings.ToLower(cloud.String(ref, "id"))] = struct{}{}
+		}
+		// 兼容只在网卡上记录 virtualMachine 的数据
+		for key, n := range inv.nics {
+			if n.vmID == strings.ToLower(id) {
+				nicIDs[key] = struct{}{}
+			}
+		}
+		keys := make([]string, 0, len(nicIDs))
+		for key := range nicIDs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			n, ok := inv.nics[key]
+			if !ok {
+				continue
+			}
+			for _, ip := range n.privateIPs {
+				as.PrivateIPs = cloud.AppendUnique(as.PrivateIPs, ip)
+			}
+			for _, ip := range n.publicIPs {
+				as.PublicIPs = cloud.AppendUnique(as.PublicIPs, ip)
+			}
+			if n.nsgID != "" {
+				as.GroupIDs = cloud.AppendUnique(as.GroupIDs, inv.nsgName(n.nsgID))
+			}
+			subnets := n.subnetIDs
+			if len(subnets) == 0 {
+				subnets = []string{""}
+			}
+			for _, subnet := range subnets {
+				if as.SubnetID == "" && subnet != "" {
+					as.SubnetID = lastSegment(subnet)
+					as.VpcID = vnetOf(subnet)
+				}
+				if id, ok := inv.subnetNSG[subnet]; ok {
+					as.GroupIDs = cloud.AppendUnique(as.GroupIDs, inv.nsgName(id))
+				}
+				as.Rules = append(as.Rules, inv.rulesFor(subnet, n.nsgID, n.basicSKU)...)
+			}
+		}
+		as.Public = len(as.PublicIPs) > 0
+		as.Evaluate()
+		out = append(out, as)
+	}
+	return out, nil
+}
+
+// GetApplicationGateways 解析应用网关的公网前端与监听端口，受网关子网 NSG 约束
+func (c *AzureCloud) GetApplicationGateways(inv *inventory) ([]*cloud.Asset, error) {
+	records, err := c.ListByPath(PathAppGateway)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathAppGateway, err)
+	}
+	var out []*cloud.Asset
+	for _, record := range records {
+		payload := cloud.PayloadOf(record)
+		id := cloud.String(payload, "id")
+		if id == "" {
+			continue
+		}
+		p := props(payload)
+		as := &cloud.Asset{
+			Provider:     ProviderName,
+			InstanceID:   id,
+			InstanceName: cloud.String(payload, "name"),
+			ResourceType: "application-gateway",
+			Region:       cloud.String(payload, "location"),
+			Tags:         cloud.Tags(cloud.AnyCase(payload, "tags")),
+		}
+
+		publicFrontends := map[string]bool{}
+		for _, fe := range cloud.Maps(p, "frontendIPConfigurations") {
+			fp := props(fe)
+			feID := strings.ToLower(cloud.String(fe, "id"))
+			if pip, ok := inv.publicIPs[strings.ToLower(cloud.String(cloud.Map(fp, "publicIPAddress"), "id"))]; ok {
+				publicFrontends[feID] = true
+				if pip.address != "" {
+					as.PublicIPs = cloud.AppendUnique(as.PublicIPs, pip.address)
+				}
+			}
+			if ip := cloud.String(fp, "privateIPAddress"); ip != "" {
+				as.PrivateIPs = cloud.AppendUnique(as.PrivateIPs, ip)
+			}
+		}
+		ports := map[string]int{}
+		for _, fp := range cloud.Maps(p, "frontendPorts") {
+			if port, ok := cloud.Int(props(fp), "port"); ok {
+				ports[strings.ToLower(cloud.String(fp, "id"))] = port
+			}
+		}
+
+		var listeners []cloud.Rule
+		for _, l := range cloud.Maps(p, "httpListeners") {
+			lp := props(l)
+			if !publicFrontends[strings.ToLower(cloud.String(cloud.Map(lp, "frontendIPConfiguration"), "id"))] {
+				continue
+			}
+			port, ok := ports[strings.ToLower(cloud.String(cloud.Map(lp, "frontendPort"), "id"))]
+			if !ok {
+				continue
+			}
+			if as.Endpoint == "" {
+				as.Endpoint = cloud.FirstString(cloud.String(lp, "hostName"), cloud.FirstString(cloud.Strings(lp, "hostNames")...))
+			}
+			listeners = append(listeners, cloud.Rule{
+				GroupID:  cloud.String(l, "name"),
+				FromPort: port,
+				ToPort:   port,
+				Protocol: "tcp",
+				Iprange:  []string{cloud.ExposureIprange},
+				Exposure: cloud.ExposureInternet,
+			})
+		}
+
+		var subnetRules []cloud.Rule
+		for _, gc := range cloud.Maps(p, "gatewayIPConfigurations") {
+			subnet := strings.ToLower(cloud.String(cloud.Map(props(gc), "subnet"), "id"))
+			if subnet == "" {
+				continue
+			}
+			as.SubnetID = lastSegment(subnet)
+			as.VpcID = vnetOf(subnet)
+			if id, ok := inv.subnetNSG[subnet]; ok {
+				as.GroupIDs = cloud.AppendUnique(as.GroupIDs, inv.nsgName(id))
+				subnetRules = inv.rules[id]
+				if subnetRules == nil {
+					subnetRules = []cloud.Rule{}
+				}
+			}
+		}
+		as.Rules = intersectRules(subnetRules, listeners)
+		as.Public = len(publicFrontends) > 0
+		as.Evaluate()
+		out = append(out, as)
+	}
+	return out, nil
+}
+
+// unattachedPublicIPs 未绑定任何资源的公网 IP，便于发现悬空地址
+func (inv *inventory) unattachedPublicIPs() []*cloud.Asset {
+	keys := make([]string, 0, len(inv.publicIPs))
+	for key := range inv.publicIPs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var out []*cloud.Asset
+	for _, key := range keys {
+		pip := inv.publicIPs[key]
+		if pip.attached || pip.address == "" {
+			continue
+		}
+		out = append(out, &cloud.Asset{
+			Provider:     ProviderName,
+			InstanceID:   pip.id,
+			InstanceName: pip.name,
+			ResourceType: "public-ip",
+			Region:       pip.location,
+			Tags:         pip.tags,
+			PublicIPs:    []string{pip.address},
+			Public:       true,
+			Exposure:     cloud.ExposureNone,
+		})
+	}
+	return out
+}
+
+// props ARM 资源的属性位于 properties 下，SDK 展开后的数据直接使用原对象
+func props(m map[string]interface{}) map[string]interface{} {
+	if p := cloud.Map(m, "properties"); p != nil {
+		return p
+	}
+	return m
+}
+
+// vnetOf 从子网 ID（.../virtualNetworks/<vnet>/subnets/<subnet>）取出虚拟网络名
+func vnetOf(subnetID string) string {
+	parts := strings.Split(subnetID, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if strings.EqualFold(parts[i], "virtualNetworks") {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+func lastSegment(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
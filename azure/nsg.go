@@ -0,0 +1,255 @@
+package azure
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// NSG 服务标签对应的地址段，AzureLoadBalancer 为平台健康探测，不计入暴露
+var serviceTags = map[string][]string{
+	"*":                 {cloud.ExposureIprange},
+	"any":               {cloud.ExposureIprange},
+	"internet":          {cloud.ExposureIprange},
+	"virtualnetwork":    {"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"},
+	"azureloadbalancer": {},
+}
+
+type portRange struct {
+	from, to int
+}
+
+// nsgRule 单条 NSG 安全规则
+type nsgRule struct {
+	name     string
+	priority int
+	allow    bool
+	protocol string
+	ports    []portRange
+	sources  []string
+	asgs     []string
+}
+
+// securityGroup NSG 及按优先级排序后的入方向规则（含默认规则）
+type securityGroup struct {
+	id    string
+	name  string
+	rules []nsgRule
+}
+
+func parseNSG(payload map[string]interface{}) *securityGroup {
+	id := cloud.String(payload, "id")
+	if id == "" {
+		return nil
+	}
+	nsg := &securityGroup{id: id, name: cloud.String(payload, "name")}
+	p := props(payload)
+	rules := append(cloud.Maps(p, "securityRules"), cloud.Maps(p, "defaultSecurityRules")...)
+	for _, r := range rules {
+		rp := props(r)
+		if !strings.EqualFold(cloud.String(rp, "direction"), "Inbound") {
+			continue
+		}
+		rule := nsgRule{
+			name:     cloud.String(r, "name"),
+			allow:    strings.EqualFold(cloud.String(rp, "access"), "Allow"),
+			protocol: normalizeProtocol(cloud.String(rp, "protocol")),
+		}
+		rule.priority, _ = cloud.Int(rp, "priority")
+		for _, s := range append(cloud.Strings(rp, "destinationPortRange"), cloud.Strings(rp, "destinationPortRanges")...) {
+			if pr, ok := parsePortRange(s); ok {
+				rule.ports = append(rule.ports, pr)
+			}
+		}
+		for _, s := range append(cloud.Strings(rp, "sourceAddressPrefix"), cloud.Strings(rp, "sourceAddressPrefixes")...) {
+			rule.sources = append(rule.sources, expandSource(s)...)
+		}
+		for _, asg := range cloud.Maps(rp, "sourceApplicationSecurityGroups") {
+			if id := cloud.String(asg, "id"); id != "" {
+				rule.asgs = append(rule.asgs, strings.ToLower(id))
+			}
+		}
+		nsg.rules = append(nsg.rules, rule)
+	}
+	sort.SliceStable(nsg.rules, func(i, j int) bool {
+		return nsg.rules[i].priority < nsg.rules[j].priority
+	})
+	return nsg
+}
+
+// effectiveRules 按优先级展开放行规则，剔除被更高优先级 deny 规则完全覆盖的来源
+func (nsg *securityGroup) effectiveRules(asgs map[string]cloud.GroupReference) []cloud.Rule {
+	var out []cloud.Rule
+	for i, rule := range nsg.rules {
+		if !rule.allow {
+			continue
+		}
+		for _, pr := range rule.ports {
+			r := cloud.Rule{
+				GroupID:  nsg.name + "/" + rule.name,
+				FromPort: pr.from,
+				ToPort:   pr.to,
+				Protocol: rule.protocol,
+				Iprange:  []string{},
+			}
+			for _, src := range rule.sources {
+				if !deniedBefore(nsg.rules[:i], rule.protocol, pr, src) {
+					r.Iprange = append(r.Iprange, src)
+				}
+			}
+			for _, id := range rule.asgs {
+				ref, ok := asgs[id]
+				if !ok {
+					ref = cloud.GroupReference{GroupID: id, Members: []string{}, IPs: []string{}}
+				}
+				r.SourceGroups = append(r.SourceGroups, ref)
+			}
+			if len(r.Iprange) == 0 && len(r.SourceGroups) == 0 {
+				continue
+			}
+			r.Exposure = cloud.ClassifyCIDRs(r.Sources())
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// deniedBefore 判断来源是否已被更高优先级的 deny 规则拒绝
+func deniedBefore(higher []nsgRule, protocol string, pr portRange, src string) bool {
+	for _, d := range higher {
+		if d.allow || (d.protocol != "-1" && d.protocol != protocol) {
+			continue
+		}
+		portCovered := false
+		for _, dp := range d.ports {
+			if dp.from <= pr.from && dp.to >= pr.to {
+				portCovered = true
+				break
+			}
+		}
+		if !portCovered {
+			continue
+		}
+		for _, ds := range d.sources {
+			if cloud.CIDRCovers(ds, src) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// intersectRules 入方向流量须同时被子网与网卡上的 NSG 放行，nil 表示未绑定 NSG（全部放行）
+func intersectRules(subnet, nic []cloud.Rule) []cloud.Rule {
+	if subnet == nil {
+		return nic
+	}
+	if nic == nil {
+		return subnet
+	}
+	var out []cloud.Rule
+	for _, a := range subnet {
+		for _, b := range nic {
+			proto, ok := intersectProtocol(a.Protocol, b.Protocol)
+			if !ok {
+				continue
+			}
+			from, to := max(a.FromPort, b.FromPort), min(a.ToPort, b.ToPort)
+			if from > to {
+				continue
+			}
+			sources := intersectSources(a.Sources(), b.Sources())
+			if len(sources) == 0 {
+				continue
+			}
+			out = append(out, cloud.Rule{
+				GroupID:  cloud.FirstString(b.GroupID, a.GroupID),
+				FromPort: from,
+				ToPort:   to,
+				Protocol: proto,
+				Iprange:  sources,
+				Exposure: cloud.ClassifyCIDRs(sources),
+			})
+		}
+	}
+	if out == nil {
+		out = []cloud.Rule{}
+	}
+	return out
+}
+
+// allowAll 未绑定任何 NSG 时 Azure 放行全部入方向流量
+func allowAll() []cloud.Rule {
+	return []cloud.Rule{{
+		FromPort: 0,
+		ToPort:   65535,
+		Protocol: "-1",
+		Iprange:  []string{cloud.ExposureIprange},
+		Exposure: cloud.ExposureInternet,
+	}}
+}
+
+func intersectProtocol(a, b string) (string, bool) {
+	switch {
+	case a == "-1":
+		return b, true
+	case b == "-1" || a == b:
+		return a, true
+	default:
+		return "", false
+	}
+}
+
+// intersectSources 前缀要么嵌套要么不相交，交集取两侧被对方覆盖的那部分
+func intersectSources(a, b []string) []string {
+	var out []string
+	for _, x := range a {
+		for _, y := range b {
+			if cloud.CIDRCovers(y, x) {
+				out = cloud.AppendUnique(out, x)
+			} else if cloud.CIDRCovers(x, y) {
+				out = cloud.AppendUnique(out, y)
+			}
+		}
+	}
+	return out
+}
+
+func expandSource(s string) []string {
+	if cidrs, ok := serviceTags[strings.ToLower(s)]; ok {
+		return cidrs
+	}
+	return []string{s}
+}
+
+func normalizeProtocol(p string) string {
+	switch strings.ToLower(p) {
+	case "", "*":
+		return "-1"
+	default:
+		return strings.ToLower(p)
+	}
+}
+
+// parsePortRange 解析 "*"、"22" 或 "8000-9000"
+func parsePortRange(s string) (portRange, bool) {
+	s = strings.TrimSpace(s)
+	if s == "*" {
+		return portRange{0, 65535}, true
+	}
+	lo, hi, found := strings.Cut(s, "-")
+	from, err := strconv.Atoi(lo)
+	if err != nil {
+		return portRange{}, false
+	}
+	if !found {
+		return portRange{from, from}, true
+	}
+	to, err := strconv.Atoi(hi)
+	if err != nil {
+		return portRange{}, false
+	}
+	return portRange{from, to}, true
+}
@@ -0,0 +1,147 @@
+package certs
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// 证书相关的问题规则
+const (
+	RuleExpired     = "tls-cert-expired"
+	RuleExpiring    = "tls-cert-expiring"
+	RuleSelfSigned  = "tls-cert-self-signed"
+	RuleWeakKey     = "tls-weak-key"
+	RuleSANMismatch = "tls-san-mismatch"
+	RuleLegacyTLS   = "tls-legacy-version"
+	RuleWeakCipher  = "tls-weak-cipher"
+)
+
+// 密钥长度下限
+const (
+	minRSABits   = 2048
+	minECDSABits = 256
+)
+
+// Evaluate 检查证书有效期、自签名、弱密钥、SAN 与域名不匹配以及旧版本协议
+func (s *Scanner) Evaluate(a *cloud.Asset, ep cloud.TLSEndpoint, names []string) []*finding.Finding {
+	if len(ep.Chain) == 0 {
+		return nil
+	}
+	target := net.JoinHostPort(ep.IP, strconv.Itoa(ep.Port))
+	leaf := ep.Chain[0]
+	var out []*finding.Finding
+	add := func(rule string, sev finding.Severity, title, detail string) *finding.Finding {
+		f := finding.New(rule, sev, a, target, title, detail)
+		f.Evidence["subject"] = leaf.Subject
+		f.Evidence["sha256"] = leaf.SHA256
+		f.FromPort, f.ToPort = ep.Port, ep.Port
+		out = append(out, f)
+		return f
+	}
+
+	now := time.Now()
+	remaining := leaf.NotAfter.Sub(now)
+	switch {
+	case remaining <= 0:
+		f := add(RuleExpired, finding.SeverityCritical, "TLS certificate expired",
+			fmt.Sprintf("certificate expired at %s", leaf.NotAfter.Format(time.RFC3339)))
+		f.Evidence["notAfter"] = leaf.NotAfter.Format(time.RFC3339)
+	case remaining < time.Duration(s.ExpiryDays)*24*time.Hour:
+		sev := finding.SeverityMedium
+		if remaining < 7*24*time.Hour {
+			sev = finding.SeverityHigh
+		}
+		f := add(RuleExpiring, sev, "TLS certificate expiring soon",
+			fmt.Sprintf("certificate expires in %d days", int(remaining.Hours()/24)))
+		f.Evidence["notAfter"] = leaf.NotAfter.Format(time.RFC3339)
+	}
+
+	if leaf.SelfSigned {
+		add(RuleSelfSigned, finding.SeverityMedium, "Self-signed TLS certificate", "certificate issuer equals subject and is not signed by a CA")
+	}
+
+	for i, c := range ep.Chain {
+		if (c.KeyType == "RSA" && c.KeySize < minRSABits) || (c.KeyType == "ECDSA" && c.KeySize < minECDSABits) {
+			f := add(RuleWeakKey, finding.SeverityHigh, "Weak TLS certificate key",
+				fmt.Sprintf("chain[%d] %s uses %s-%d", i, c.Subject, c.KeyType, c.KeySize))
+			f.Evidence["keyType"] = c.KeyType
+			f.Evidence["keySize"] = strconv.Itoa(c.KeySize)
+			break
+		}
+	}
+
+	// 每个域名与以其作为 SNI 取得的证书核对，握手失败的域名不参与核对
+	var mismatched []string
+	for _, name := range names {
+		cert := leaf
+		if sni := sniOf(name); sni != ep.ServerName {
+			c, ok := ep.Served[sni]
+			if !ok {
+				continue
+			}
+			cert = c
+		}
+		if !matchesSAN(cert, name) {
+			mismatched = append(mismatched, name)
+		}
+	}
+	if len(mismatched) > 0 {
+		f := add(RuleSANMismatch, finding.SeverityMedium, "TLS certificate does not cover DNS names",
+			fmt.Sprintf("names %s resolve to this endpoint but are not in the certificate", strings.Join(mismatched, ", ")))
+		f.Evidence["names"] = strings.Join(mismatched, ",")
+		f.Evidence["sans"] = strings.Join(leaf.SANs, ",")
+	}
+
+	var legacy []string
+	for _, v := range ep.Versions {
+		if v == "TLS1.0" || v == "TLS1.1" {
+			legacy = append(legacy, v)
+		}
+	}
+	if len(legacy) > 0 {
+		f := add(RuleLegacyTLS, finding.SeverityMedium, "Legacy TLS versions enabled",
+			fmt.Sprintf("endpoint accepts %s", strings.Join(legacy, ", ")))
+		f.Evidence["versions"] = strings.Join(ep.Versions, ",")
+	}
+
+	insecure := map[string]bool{}
+	for _, c := range tlsInsecureCipherNames() {
+		insecure[c] = true
+	}
+	var weak []string
+	for _, c := range ep.Ciphers {
+		if insecure[c] {
+			weak = append(weak, c)
+		}
+	}
+	if len(weak) > 0 {
+		f := add(RuleWeakCipher, finding.SeverityMedium, "Weak TLS cipher suites enabled",
+			fmt.Sprintf("endpoint accepts %s", strings.Join(weak, ", ")))
+		f.Evidence["ciphers"] = strings.Join(weak, ",")
+	}
+	return out
+}
+
+// matchesSAN 按 RFC 6125 规则匹配域名，通配符只匹配最左侧一级
+func matchesSAN(c cloud.Certificate, name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	for _, san := range c.SANs {
+		san = strings.ToLower(san)
+		if san == name {
+			return true
+		}
+		if strings.HasPrefix(san, "*.") {
+			_, rest, ok := strings.Cut(name, ".")
+			if ok && rest == san[2:] {
+				return true
+			}
+		}
+	}
+	return false
+}
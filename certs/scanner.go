@@ -0,0 +1,306 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// 常见的 TLS 端口，未做服务识别时按端口判断
+var tlsPorts = map[int]bool{
+	443: true, 465: true, 636: true, 993: true, 995: true, 2376: true, 5986: true,
+	6443: true, 8443: true, 9443: true, 10250: true,
+}
+
+var versions = []struct {
+	id   uint16
+	name string
+}{
+	{tls.VersionTLS10, "TLS1.0"},
+	{tls.VersionTLS11, "TLS1.1"},
+	{tls.VersionTLS12, "TLS1.2"},
+	{tls.VersionTLS13, "TLS1.3"},
+}
+
+type Scanner struct {
+	Timeout time.Duration
+	Workers int
+	// ExpiryDays 证书剩余有效期少于该天数时告警
+	ExpiryDays int
+	// EnumerateCiphers 逐个尝试 TLS1.2 及以下的密码套件
+	EnumerateCiphers bool
+}
+
+// NewScannerFromViper 读取 Certs 配置段
+func NewScannerFromViper() *Scanner {
+	s := &Scanner{
+		Timeout:          time.Duration(viper.GetInt("Certs.timeout_ms")) * time.Millisecond,
+		Workers:          viper.GetInt("Certs.workers"),
+		ExpiryDays:       viper.GetInt("Certs.expiry_days"),
+		EnumerateCiphers: true,
+	}
+	if viper.IsSet("Certs.enumerate_ciphers") {
+		s.EnumerateCiphers = viper.GetBool("Certs.enumerate_ciphers")
+	}
+	if s.Timeout <= 0 {
+		s.Timeout = 5 * time.Second
+	}
+	if s.Workers <= 0 {
+		s.Workers = 8
+	}
+	if s.ExpiryDays <= 0 {
+		s.ExpiryDays = 30
+	}
+	return s
+}
+
+// ScanAssets 采集已验证开放的 TLS 端口证书，结果写回 Asset.Certificates 并返回发现的问题
+// dnsNames 为 IP/域名 -> 指向它的公网域名，用于 SNI 与 SAN 核对
+func (s *Scanner) ScanAssets(ctx context.Context, assets []*cloud.Asset, dnsNames map[string][]string) []*finding.Finding {
+	type job struct {
+		asset *cloud.Asset
+		ip    string
+		port  int
+		sni   string
+		names []string
+	}
+	jobs := make(chan job)
+	var out []*finding.Finding
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	var scanned int
+	for i := 0; i < s.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				ep := s.Scan(ctx, j.ip, j.port, j.sni)
+				if len(ep.Chain) == 0 {
+					continue
+				}
+				s.ScanNames(ctx, &ep, j.names)
+				findings := s.Evaluate(j.asset, ep, j.names)
+				lock.Lock()
+				j.asset.Certificates = append(j.asset.Certificates, ep)
+				out = append(out, findings...)
+				scanned++
+				lock.Unlock()
+			}
+		}()
+	}
+	for _, a := range assets {
+		names := namesFor(a, dnsNames)
+		sni := serverNameFor(a, names)
+		for _, c := range a.Verification {
+			if c.State == cloud.PortOpen && tlsCapable(a, c) {
+				jobs <- job{asset: a, ip: c.IP, port: c.Port, sni: sni, names: names}
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	finding.Sort(out)
+	logx.Infof("certificate summary: tls endpoints=%d, findings=%d", scanned, len(out))
+	return out
+}
+
+// tlsCapable 服务识别为 TLS、端口为常见 TLS 端口或尚未识别的端口都尝试握手
+func tlsCapable(a *cloud.Asset, c cloud.PortCheck) bool {
+	if tlsPorts[c.Port] {
+		return true
+	}
+	for _, fp := range a.Fingerprints {
+		if fp.IP == c.IP && fp.Port == c.Port {
+			return fp.TLS
+		}
+	}
+	return true
+}
+
+// namesFor 汇总 DNS 记录中指向资产公网地址或 endpoint 的域名，这些域名需要与证书 SAN 核对
+func namesFor(a *cloud.Asset, dnsNames map[string][]string) []string {
+	var out []string
+	if a.Endpoint != "" {
+		for _, n := range dnsNames[strings.ToLower(a.Endpoint)] {
+			out = cloud.AppendUnique(out, n)
+		}
+	}
+	for _, ip := range a.PublicIPs {
+		for _, n := range dnsNames[ip] {
+			out = cloud.AppendUnique(out, n)
+		}
+	}
+	return out
+}
+
+// serverNameFor 首次握手的 SNI：优先使用 DNS 记录中的域名，其次是云厂商分配的 endpoint 域名
+// （如 EC2 的 publicDnsName、负载均衡的 DNS 名），后者只用于握手，不参与 SAN 核对
+func serverNameFor(a *cloud.Asset, names []string) string {
+	if len(names) > 0 {
+		return sniOf(names[0])
+	}
+	if a.Endpoint != "" {
+		if _, err := netip.ParseAddr(a.Endpoint); err != nil {
+			return sniOf(a.Endpoint)
+		}
+	}
+	return ""
+}
+
+// Scan 握手获取证书链，再逐个版本与密码套件探测支持情况
+func (s *Scanner) Scan(ctx context.Context, ip string, port int, serverName string) cloud.TLSEndpoint {
+	addr := net.JoinHostPort(ip, strconv.Itoa(port))
+	ep := cloud.TLSEndpoint{IP: ip, Port: port, ServerName: serverName, Versions: []string{}, Ciphers: []string{}, CheckedAt: time.Now().UTC()}
+
+	state, err := s.handshake(ctx, addr, serverName, tls.VersionTLS10, tls.VersionTLS13, allCipherIDs())
+	if err != nil {
+		ep.Error = err.Error()
+		return ep
+	}
+	for _, c := range state.PeerCertificates {
+		ep.Chain = append(ep.Chain, describe(c))
+	}
+
+	for _, v := range versions {
+		st, err := s.handshake(ctx, addr, serverName, v.id, v.id, allCipherIDs())
+		if err != nil {
+			continue
+		}
+		ep.Versions = append(ep.Versions, v.name)
+		if v.id == tls.VersionTLS13 || !s.EnumerateCiphers {
+			ep.Ciphers = cloud.AppendUnique(ep.Ciphers, tls.CipherSuiteName(st.CipherSuite))
+			continue
+		}
+		for _, suite := range allCiphers() {
+			if !supports(suite, v.id) {
+				continue
+			}
+			if _, err := s.handshake(ctx, addr, serverName, v.id, v.id, []uint16{suite.ID}); err == nil {
+				ep.Ciphers = cloud.AppendUnique(ep.Ciphers, suite.Name)
+			}
+		}
+	}
+	return ep
+}
+
+// ScanNames 对其余域名分别以其作为 SNI 握手，记录各自取得的叶子证书
+func (s *Scanner) ScanNames(ctx context.Context, ep *cloud.TLSEndpoint, names []string) {
+	addr := net.JoinHostPort(ep.IP, strconv.Itoa(ep.Port))
+	for _, name := range names {
+		sni := sniOf(name)
+		if sni == ep.ServerName {
+			continue
+		}
+		if _, ok := ep.Served[sni]; ok {
+			continue
+		}
+		state, err := s.handshake(ctx, addr, sni, tls.VersionTLS10, tls.VersionTLS13, allCipherIDs())
+		if err != nil || len(state.PeerCertificates) == 0 {
+			continue
+		}
+		if ep.Served == nil {
+			ep.Served = map[string]cloud.Certificate{}
+		}
+		ep.Served[sni] = describe(state.PeerCertificates[0])
+	}
+}
+
+// sniOf 通配符域名以其父域作为 SNI
+func sniOf(name string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSuffix(name, ".")), "*.")
+}
+
+func (s *Scanner) handshake(ctx context.Context, addr, serverName string, minVersion, maxVersion uint16, ciphers []uint16) (tls.ConnectionState, error) {
+	d := net.Dialer{Timeout: s.Timeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(s.Timeout))
+	tc := tls.Client(conn, &tls.Config{
+		InsecureSkipVerify: true,
+		ServerName:         serverName,
+		MinVersion:         minVersion,
+		MaxVersion:         maxVersion,
+		CipherSuites:       ciphers,
+	})
+	if err := tc.HandshakeContext(ctx); err != nil {
+		return tls.ConnectionState{}, err
+	}
+	return tc.ConnectionState(), nil
+}
+
+func allCiphers() []*tls.CipherSuite {
+	return append(tls.CipherSuites(), tls.InsecureCipherSuites()...)
+}
+
+func allCipherIDs() []uint16 {
+	var out []uint16
+	for _, c := range allCiphers() {
+		out = append(out, c.ID)
+	}
+	return out
+}
+
+func supports(suite *tls.CipherSuite, version uint16) bool {
+	for _, v := range suite.SupportedVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+func describe(c *x509.Certificate) cloud.Certificate {
+	sum := sha256.Sum256(c.Raw)
+	out := cloud.Certificate{
+		Subject:            c.Subject.String(),
+		Issuer:             c.Issuer.String(),
+		SANs:               append([]string{}, c.DNSNames...),
+		SerialNumber:       c.SerialNumber.String(),
+		NotBefore:          c.NotBefore.UTC(),
+		NotAfter:           c.NotAfter.UTC(),
+		SignatureAlgorithm: c.SignatureAlgorithm.String(),
+		SHA256:             hex.EncodeToString(sum[:]),
+	}
+	for _, ip := range c.IPAddresses {
+		out.SANs = append(out.SANs, ip.String())
+	}
+	switch k := c.PublicKey.(type) {
+	case *rsa.PublicKey:
+		out.KeyType, out.KeySize = "RSA", k.N.BitLen()
+	case *ecdsa.PublicKey:
+		out.KeyType, out.KeySize = "ECDSA", k.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		out.KeyType, out.KeySize = "Ed25519", 256
+	default:
+		out.KeyType = c.PublicKeyAlgorithm.String()
+	}
+	out.SelfSigned = string(c.RawSubject) == string(c.RawIssuer) && c.CheckSignatureFrom(c) == nil
+	return out
+}
+
+func tlsInsecureCipherNames() []string {
+	var out []string
+	for _, c := range tls.InsecureCipherSuites() {
+		out = append(out, c.Name)
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package cloud
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// AddressClass 单个地址的类别
+type AddressClass string
+
+const (
+	AddressPublic    AddressClass = "public"
+	AddressPrivate   AddressClass = "rfc1918" // RFC 1918 与 IPv6 ULA
+	AddressCGNAT     AddressClass = "cgnat"   // 100.64.0.0/10
+	AddressLinkLocal AddressClass = "link-local"
+	AddressBogon     AddressClass = "bogon" // 保留、文档、组播、回环等不应出现在公网的地址
+	AddressInvalid   AddressClass = "invalid"
+)
+
+// bogonPrefixes 除私网、CGNAT 与链路本地之外不可路由的地址段
+var bogonPrefixes = func() []netip.Prefix {
+	var out []netip.Prefix
+	for _, s := range []string{
+		"0.0.0.0/8", "127.0.0.0/8", "192.0.0.0/24", "192.0.2.0/24", "198.18.0.0/15",
+		"198.51.100.0/24", "203.0.113.0/24", "224.0.0.0/4", "240.0.0.0/4",
+		"::/128", "::1/128", "100::/64", "2001:db8::/32", "ff00::/8",
+	} {
+		out = append(out, netip.MustParsePrefix(s))
+	}
+	return out
+}()
+
+// ParseAddress 校验并规范化地址，IPv4 映射的 IPv6 地址还原为 IPv4
+func ParseAddress(s string) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(s))
+	if err != nil || addr.Zone() != "" {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// ClassifyAddress 判断地址类别，无法解析时为 invalid
+func ClassifyAddress(s string) AddressClass {
+	addr, ok := ParseAddress(s)
+	if !ok {
+		return AddressInvalid
+	}
+	return ClassOf(addr)
+}
+
+// ClassOf 判断已解析地址的类别
+func ClassOf(addr netip.Addr) AddressClass {
+	switch {
+	case addr.IsPrivate():
+		return AddressPrivate
+	case isSharedAddress(addr):
+		return AddressCGNAT
+	case addr.IsLinkLocalUnicast():
+		return AddressLinkLocal
+	}
+	for _, p := range bogonPrefixes {
+		if p.Contains(addr) {
+			return AddressBogon
+		}
+	}
+	if addr.Is6() && !netip.MustParsePrefix("2000::/3").Contains(addr) {
+		// 全球单播之外的 IPv6 地址
+		return AddressBogon
+	}
+	return AddressPublic
+}
+
+// HasPublicAddress 是否包含公网类别的地址，公网 IP 字段中的私网或保留地址不算
+func HasPublicAddress(ips []string) bool {
+	for _, ip := range ips {
+		if ClassifyAddress(ip) == AddressPublic {
+			return true
+		}
+	}
+	return false
+}
+
+// AddressInfo 公网地址的分类与云厂商地址段归属，Expected 为否时表示不在预期地址段内
+type AddressInfo struct {
+	IP       string       `json:"ip"`
+	Class    AddressClass `json:"class"`
+	Provider string       `json:"provider,omitempty"`
+	Service  string       `json:"service,omitempty"`
+	Region   string       `json:"region,omitempty"`
+	Prefix   string       `json:"prefix,omitempty"`
+	Expected bool         `json:"expected"`
+}
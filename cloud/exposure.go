@@ -0,0 +1,129 @@
+package cloud
+
+import (
+	"net/netip"
+	"strings"
+)
+
+const (
+	ExposureIprange     = "0.0.0.0/0"
+	ExposureIpv6Iprange = "::/0"
+)
+
+// Exposure 描述一组来源 CIDR 对公网的开放程度
+type Exposure string
+
+const (
+	ExposureNone       Exposure = "none"       // 无任何来源
+	ExposurePrivate    Exposure = "private"    // 仅内网地址段
+	ExposureRestricted Exposure = "restricted" // 限定的公网地址段
+	ExposureBroad      Exposure = "broad"      // 大范围公网地址段
+	ExposureInternet   Exposure = "internet"   // 0.0.0.0/0 或 ::/0
+)
+
+// 公网前缀短于该长度视为大范围开放
+const (
+	broadIPv4Bits = 16
+	broadIPv6Bits = 32
+)
+
+func (e Exposure) rank() int {
+	switch e {
+	case ExposurePrivate:
+		return 1
+	case ExposureRestricted:
+		return 2
+	case ExposureBroad:
+		return 3
+	case ExposureInternet:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// MaxExposure 返回开放程度更高的一个
+func MaxExposure(a, b Exposure) Exposure {
+	if b.rank() > a.rank() {
+		return b
+	}
+	if a == "" {
+		return ExposureNone
+	}
+	return a
+}
+
+// ClassifyCIDR 判断单个 CIDR（或单个 IP）的开放程度，无法解析时按 none 处理
+func ClassifyCIDR(cidr string) Exposure {
+	cidr = strings.TrimSpace(cidr)
+	if cidr == "" {
+		return ExposureNone
+	}
+	var prefix netip.Prefix
+	if strings.Contains(cidr, "/") {
+		p, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return ExposureNone
+		}
+		prefix = p.Masked()
+	} else {
+		addr, err := netip.ParseAddr(cidr)
+		if err != nil {
+			return ExposureNone
+		}
+		prefix = netip.PrefixFrom(addr, addr.BitLen())
+	}
+
+	addr := prefix.Addr()
+	if prefix.Bits() == 0 {
+		return ExposureInternet
+	}
+	if isInternalPrefix(prefix) {
+		return ExposurePrivate
+	}
+	if (addr.Is4() && prefix.Bits() < broadIPv4Bits) || (addr.Is6() && prefix.Bits() < broadIPv6Bits) {
+		return ExposureBroad
+	}
+	return ExposureRestricted
+}
+
+// ClassifyCIDRs 返回一组 CIDR 中开放程度最高的分类
+func ClassifyCIDRs(cidrs []string) Exposure {
+	out := ExposureNone
+	for _, c := range cidrs {
+		out = MaxExposure(out, ClassifyCIDR(c))
+	}
+	return out
+}
+
+// IsWideExposure 判断是否对整个互联网或大范围公网开放
+func IsWideExposure(e Exposure) bool {
+	return e == ExposureInternet || e == ExposureBroad
+}
+
+// internalBlocks RFC 1918、CGNAT、回环、链路本地与 IPv6 ULA 地址段
+var internalBlocks = func() []netip.Prefix {
+	var out []netip.Prefix
+	for _, s := range []string{
+		"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16",
+		"fc00::/7", "::1/128", "fe80::/10",
+	} {
+		out = append(out, netip.MustParsePrefix(s))
+	}
+	return out
+}()
+
+// isInternalPrefix 整个前缀都落在某个内网地址段内，10.0.0.0/7 这类跨入公网的前缀不算
+func isInternalPrefix(prefix netip.Prefix) bool {
+	for _, b := range internalBlocks {
+		if b.Bits() <= prefix.Bits() && b.Contains(prefix.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSharedAddress 判断 100.64.0.0/10 运营商级 NAT 地址
+func isSharedAddress(addr netip.Addr) bool {
+	return addr.Is4() && netip.MustParsePrefix("100.64.0.0/10").Contains(addr)
+}
@@ -0,0 +1,16 @@
+package cloud
+
+// GeoInfo 地址或地址段的归属，Network 为 MMDB 中命中的网段。
+// 查询的地址段大于该网段时汇总其中全部网段：Countries、ASNs 为去重后的集合，
+// Country、ASN 只在集合唯一时填写；网段过多未能遍历完时 Partial 为 true
+type GeoInfo struct {
+	Address     string   `json:"address"`
+	Network     string   `json:"network,omitempty"`
+	Country     string   `json:"country,omitempty"`
+	CountryName string   `json:"countryName,omitempty"`
+	ASN         uint     `json:"asn,omitempty"`
+	Org         string   `json:"org,omitempty"`
+	Countries   []string `json:"countries,omitempty"`
+	ASNs        []uint   `json:"asns,omitempty"`
+	Partial     bool     `json:"partial,omitempty"`
+}
@@ -0,0 +1,225 @@
+package cloud
+
+import (
+	"time"
+
+	"github.com/xid-protocol/xidp/protocols"
+)
+
+// Asset 各云厂商统一的资产暴露记录，托管服务复用同一结构，InstanceID 为集群/域名等资源标识
+// path /protocols/external-attack-surface/<provider>-instance
+type Asset struct {
+	Provider     string `json:"provider"`
+	Account      string `json:"account,omitempty"`
+	InstanceID   string `json:"instanceId"`
+	InstanceName string `json:"instanceName"`
+	ResourceType string `json:"resourceType"`
+	Region       string `json:"region"`
+	VpcID        string `json:"vpcId,omitempty"`
+	SubnetID     string `json:"subnetId,omitempty"`
+	Endpoint     string `json:"endpoint,omitempty"`
+	// Auth URL 类入口的鉴权方式，NONE 或 MIXED 表示存在无需鉴权的入口，其余资源为空
+	Auth       string            `json:"auth,omitempty"`
+	Tags       map[string]string `json:"tags"`
+	PublicIPs  []string          `json:"publicIps"`
+	PrivateIPs []string          `json:"privateIps"`
+	IPv6s      []string          `json:"ipv6s,omitempty"`
+	GroupIDs   []string          `json:"groupIds"`
+	Rules      []Rule            `json:"rules"`
+	Public     bool              `json:"public"`
+	Exposure   Exposure          `json:"exposure"`
+	// Reachability 结合 ACL、路由表与公网地址后的有效可达性，未采集网络层时为空
+	Reachability []Reachability `json:"reachability,omitempty"`
+	// Verification 主动探测结果，未启用探测时为空
+	Verification []PortCheck `json:"verification,omitempty"`
+	// Fingerprints 已验证开放端口上的服务识别结果
+	Fingerprints []Fingerprint `json:"fingerprints,omitempty"`
+	// Certificates TLS 端口的证书与协议信息
+	Certificates []TLSEndpoint `json:"certificates,omitempty"`
+	// Web HTTP(S) 端口的页面与敏感路径信息
+	Web []WebSurface `json:"web,omitempty"`
+	// Risk 综合端口、暴露范围、探测结果与标签的风险评分
+	Risk *RiskScore `json:"risk,omitempty"`
+	// Owner 由标签与配置映射推出的负责团队
+	Owner *Ownership `json:"owner,omitempty"`
+	// Geo 公网地址的国家与 ASN
+	Geo []GeoInfo `json:"geo,omitempty"`
+	// Addresses 公网地址的类别与所属云厂商地址段
+	Addresses []AddressInfo `json:"addresses,omitempty"`
+}
+
+// Evaluate 按规则计算整体暴露等级，不可从公网访问的资源记为 none
+func (a *Asset) Evaluate() {
+	a.Exposure = ExposureNone
+	if a.Reachability != nil {
+		for _, r := range a.Reachability {
+			if r.Reachable {
+				a.Exposure = MaxExposure(a.Exposure, r.Exposure)
+			}
+		}
+		return
+	}
+	if !a.Public {
+		return
+	}
+	for _, r := range a.Rules {
+		a.Exposure = MaxExposure(a.Exposure, r.Exposure)
+	}
+}
+
+type Rule struct {
+	GroupID      string                `json:"groupId,omitempty"`
+	FromPort     int                   `json:"fromPort"`
+	ToPort       int                   `json:"toPort"`
+	Protocol     string                `json:"protocol"`
+	Iprange      []string              `json:"iprange"`
+	SourceGroups []GroupReference      `json:"sourceGroups,omitempty"`
+	PrefixLists  []PrefixListReference `json:"prefixLists,omitempty"`
+	Exposure     Exposure              `json:"exposure"`
+	// Geo 公网来源地址段的国家与 ASN，未启用 GeoIP 时为空
+	Geo []GeoInfo `json:"geo,omitempty"`
+}
+
+// GroupReference 规则中引用的来源安全组，展开为其成员网卡与地址
+type GroupReference struct {
+	GroupID   string   `json:"groupId"`
+	UserID    string   `json:"userId,omitempty"`
+	PeeringID string   `json:"vpcPeeringConnectionId,omitempty"`
+	Members   []string `json:"members"`
+	IPs       []string `json:"ips"`
+	Resolved  bool     `json:"resolved"`
+}
+
+// PrefixListReference 规则中引用的前缀列表，展开为 CIDR
+type PrefixListReference struct {
+	PrefixListID string   `json:"prefixListId"`
+	Name         string   `json:"name,omitempty"`
+	AWSManaged   bool     `json:"awsManaged"`
+	Cidrs        []string `json:"cidrs"`
+	Resolved     bool     `json:"resolved"`
+}
+
+// Covers 判断规则是否放行指定端口，协议为 -1 且未指定端口范围时放行全部端口
+func (r Rule) Covers(port int) bool {
+	if (r.Protocol == "-1" || r.Protocol == "all") && r.FromPort <= 0 && r.ToPort <= 0 {
+		return true
+	}
+	return port >= r.FromPort && port <= r.ToPort
+}
+
+// Sources 返回规则放行的全部来源地址：CIDR、前缀列表展开结果以及引用安全组的成员地址
+func (r Rule) Sources() []string {
+	out := append([]string{}, r.Iprange...)
+	for _, pl := range r.PrefixLists {
+		for _, cidr := range pl.Cidrs {
+			out = AppendUnique(out, cidr)
+		}
+	}
+	for _, g := range r.SourceGroups {
+		for _, ip := range g.IPs {
+			out = AppendUnique(out, ip)
+		}
+	}
+	return out
+}
+
+type LayerResult struct {
+	Layer   string `json:"layer"`
+	Allowed bool   `json:"allowed"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// Reachability 单条规则经过各网络层（安全组、ACL、路由、公网地址）后的有效可达性
+type Reachability struct {
+	GroupID   string        `json:"groupId,omitempty"`
+	FromPort  int           `json:"fromPort"`
+	ToPort    int           `json:"toPort"`
+	Protocol  string        `json:"protocol"`
+	Sources   []string      `json:"sources"`
+	Effective []string      `json:"effective"`
+	Layers    []LayerResult `json:"layers"`
+	Reachable bool          `json:"reachable"`
+	BlockedBy string        `json:"blockedBy,omitempty"`
+	Exposure  Exposure      `json:"exposure"`
+}
+
+// 主动探测得到的端口状态
+const (
+	PortOpen     = "open"
+	PortClosed   = "closed"
+	PortFiltered = "filtered"
+)
+
+// PortCheck 单个 IP:端口 的 TCP 连接探测结果
+type PortCheck struct {
+	IP        string    `json:"ip"`
+	Port      int       `json:"port"`
+	Protocol  string    `json:"protocol"`
+	State     string    `json:"state"`
+	LatencyMs int64     `json:"latencyMs,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Fingerprint 端口上识别出的服务，AuthRequired 为空表示无法判断
+type Fingerprint struct {
+	IP           string            `json:"ip"`
+	Port         int               `json:"port"`
+	Service      string            `json:"service"`
+	Product      string            `json:"product,omitempty"`
+	Version      string            `json:"version,omitempty"`
+	AuthRequired *bool             `json:"authRequired,omitempty"`
+	TLS          bool              `json:"tls"`
+	Banner       string            `json:"banner,omitempty"`
+	Detail       map[string]string `json:"detail,omitempty"`
+	CheckedAt    time.Time         `json:"checkedAt"`
+}
+
+// NewAttackSurfaceXID 以资源 ID 生成攻击面 XID
+func NewAttackSurfaceXID(id, xidType, path string, payload interface{}) *protocols.XID {
+	info := protocols.NewInfo(id, xidType)
+	metadata := protocols.NewMetadata(protocols.OperationCreate, path, "application/json")
+	return protocols.NewXID(&info, &metadata, payload)
+}
+
+// PublicIPsOf 返回 资产ID -> 公网 IP
+func PublicIPsOf(assets []*Asset) map[string][]string {
+	out := make(map[string][]string, len(assets))
+	for _, a := range assets {
+		if len(a.PublicIPs) > 0 {
+			out[a.InstanceID] = a.PublicIPs
+		}
+	}
+	return out
+}
+
+// RulesOf 返回 资产ID -> 入方向规则
+func RulesOf(assets []*Asset) map[string][]Rule {
+	out := make(map[string][]Rule, len(assets))
+	for _, a := range assets {
+		if len(a.Rules) > 0 {
+			out[a.InstanceID] = a.Rules
+		}
+	}
+	return out
+}
+
+// AssetXIDs 将暴露记录封装为攻击面 XID，xidType 为 <provider>-<resourceType>
+func AssetXIDs(assets []*Asset, path string) []*protocols.XID {
+	out := make([]*protocols.XID, 0, len(assets))
+	for _, a := range assets {
+		out = append(out, NewAttackSurfaceXID(a.InstanceID, a.Provider+"-"+a.ResourceType, path, a))
+	}
+	return out
+}
+
+// AssetsOf 从攻击面 XID 中取回资产记录，供后续关联（如 Kubernetes 节点、负载均衡）使用
+func AssetsOf(xids []*protocols.XID) []*Asset {
+	var out []*Asset
+	for _, x := range xids {
+		if a, ok := x.Payload.(*Asset); ok {
+			out = append(out, a)
+		}
+	}
+	return out
+}
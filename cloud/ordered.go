@@ -0,0 +1,95 @@
+package cloud
+
+import (
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// PolicyEntry 按优先级依次匹配的安全组条目（阿里云、腾讯云），先命中的条目生效
+type PolicyEntry struct {
+	Priority     int
+	Allow        bool
+	Protocol     string
+	FromPort     int
+	ToPort       int
+	Iprange      []string
+	SourceGroups []GroupReference
+}
+
+// OrderedRules 按优先级展开放行条目，同优先级拒绝优先，已被更高优先级拒绝条目覆盖的来源 CIDR 被剔除
+func OrderedRules(groupID string, entries []PolicyEntry) []Rule {
+	sorted := append([]PolicyEntry{}, entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority < sorted[j].Priority
+		}
+		return !sorted[i].Allow && sorted[j].Allow
+	})
+
+	var out []Rule
+	for i, e := range sorted {
+		if !e.Allow {
+			continue
+		}
+		r := Rule{
+			GroupID:      groupID,
+			FromPort:     e.FromPort,
+			ToPort:       e.ToPort,
+			Protocol:     e.Protocol,
+			Iprange:      []string{},
+			SourceGroups: e.SourceGroups,
+		}
+		for _, src := range e.Iprange {
+			if !deniedBy(sorted[:i], e, src) {
+				r.Iprange = append(r.Iprange, src)
+			}
+		}
+		if len(r.Iprange) == 0 && len(r.SourceGroups) == 0 {
+			continue
+		}
+		r.Exposure = ClassifyCIDRs(r.Sources())
+		out = append(out, r)
+	}
+	return out
+}
+
+func deniedBy(higher []PolicyEntry, allow PolicyEntry, src string) bool {
+	for _, d := range higher {
+		if d.Allow || (d.Protocol != "-1" && d.Protocol != allow.Protocol) {
+			continue
+		}
+		if d.FromPort > allow.FromPort || d.ToPort < allow.ToPort {
+			continue
+		}
+		for _, ds := range d.Iprange {
+			if CIDRCovers(ds, src) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CIDRCovers 判断 outer 是否包含 inner，单个 IP 视为主机前缀
+func CIDRCovers(outer, inner string) bool {
+	o, ok1 := parsePrefix(outer)
+	i, ok2 := parsePrefix(inner)
+	if !ok1 || !ok2 {
+		return strings.EqualFold(outer, inner)
+	}
+	return o.Addr().Is4() == i.Addr().Is4() && o.Bits() <= i.Bits() && o.Contains(i.Addr())
+}
+
+func parsePrefix(s string) (netip.Prefix, bool) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		return p.Masked(), err == nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), true
+}
@@ -0,0 +1,36 @@
+package cloud
+
+import "strings"
+
+// Ownership 资产的归属信息，Source 记录团队来自标签、规则、VPC、账号映射还是默认值
+type Ownership struct {
+	Team        string `json:"team,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	Service     string `json:"service,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	Email       string `json:"email,omitempty"`
+	Slack       string `json:"slack,omitempty"`
+	// Assignee 工单系统中的经办人标识
+	Assignee string `json:"assignee,omitempty"`
+	Source   string `json:"source,omitempty"`
+}
+
+// AccountOf 从资源标识推出账号：AWS ARN 的账号、GCP 的项目或 Azure 的订阅
+func AccountOf(id string) string {
+	if strings.HasPrefix(id, "arn:") {
+		if parts := strings.Split(id, ":"); len(parts) > 4 {
+			return parts[4]
+		}
+	}
+	lower := strings.ToLower(id)
+	for _, marker := range []string{"projects/", "/subscriptions/"} {
+		if i := strings.Index(lower, marker); i >= 0 {
+			rest := id[i+len(marker):]
+			if j := strings.Index(rest, "/"); j >= 0 {
+				return rest[:j]
+			}
+			return rest
+		}
+	}
+	return ""
+}
@@ -0,0 +1,317 @@
+package cloud
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xid-protocol/xidp/protocols"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// PayloadOf 把 XID 的 payload 统一转换为普通 map，兼容 bson.M / bson.D / Key-Value 列表三种存储形式
+func PayloadOf(record *protocols.XID) map[string]interface{} {
+	if record == nil || record.Payload == nil {
+		return nil
+	}
+	switch v := record.Payload.(type) {
+	case bson.Raw:
+		return PlainMap(toBsonMap(v))
+	case bson.M, bson.D, map[string]interface{}, []interface{}, bson.A:
+		return PlainMap(v)
+	default:
+		return PlainMap(toBsonMap(v))
+	}
+}
+
+// PlainMap 将任意文档形态递归转换为 map[string]interface{}
+func PlainMap(v interface{}) map[string]interface{} {
+	m, _ := Plain(v).(map[string]interface{})
+	return m
+}
+
+// Plain 递归规范化 BSON 值：文档转为 map，数组转为 []interface{}，Key-Value 列表转为 map
+func Plain(v interface{}) interface{} {
+	switch t := v.(type) {
+	case bson.M:
+		return Plain(map[string]interface{}(t))
+	case bson.D:
+		return Plain(dToMap(t))
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, item := range t {
+			out[k] = Plain(item)
+		}
+		return out
+	case bson.A:
+		return Plain([]interface{}(t))
+	case []interface{}:
+		if m, ok := kvToMap(t); ok {
+			return m
+		}
+		out := make([]interface{}, 0, len(t))
+		for _, item := range t {
+			out = append(out, Plain(item))
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// kvToMap 识别 [{"Key":k,"Value":v}, ...] 形式的列表并转换为 map；不是该形式时返回 false
+func kvToMap(list []interface{}) (map[string]interface{}, bool) {
+	if len(list) == 0 {
+		return nil, false
+	}
+	out := make(map[string]interface{}, len(list))
+	for _, item := range list {
+		var m map[string]interface{}
+		switch t := item.(type) {
+		case map[string]interface{}:
+			m = t
+		case bson.M:
+			m = map[string]interface{}(t)
+		case bson.D:
+			m = dToMap(t)
+		default:
+			return nil, false
+		}
+		if len(m) != 2 {
+			return nil, false
+		}
+		k, ok := m["Key"].(string)
+		if !ok {
+			return nil, false
+		}
+		v, ok := m["Value"]
+		if !ok {
+			return nil, false
+		}
+		out[k] = Plain(v)
+	}
+	return out, true
+}
+
+func String(m map[string]interface{}, key string) string {
+	s, _ := AnyCase(m, key).(string)
+	return s
+}
+
+func Bool(m map[string]interface{}, key string) bool {
+	switch v := AnyCase(m, key).(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	default:
+		return false
+	}
+}
+
+func Int(m map[string]interface{}, key string) (int, bool) {
+	switch v := AnyCase(m, key).(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func Map(m map[string]interface{}, key string) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	out, _ := ToMap(AnyCase(m, key))
+	return out
+}
+
+// Maps 返回 key 对应的对象数组，忽略非对象元素
+func Maps(m map[string]interface{}, key string) []map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	arr, ok := ToSlice(AnyCase(m, key))
+	if !ok {
+		return nil
+	}
+	out := make([]map[string]interface{}, 0, len(arr))
+	for _, item := range arr {
+		if im, ok := ToMap(item); ok {
+			out = append(out, im)
+		}
+	}
+	return out
+}
+
+// Strings 返回 key 对应的字符串数组，单个字符串也视为长度为 1 的数组
+func Strings(m map[string]interface{}, key string) []string {
+	if m == nil {
+		return nil
+	}
+	v := AnyCase(m, key)
+	if s, ok := v.(string); ok && s != "" {
+		return []string{s}
+	}
+	arr, ok := ToSlice(v)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(arr))
+	for _, item := range arr {
+		if s, ok := item.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Tags 解析资源标签，兼容 [{key,value}] 数组与已展开的 map 两种形式
+func Tags(v interface{}) map[string]string {
+	tags := map[string]string{}
+	if m, ok := ToMap(v); ok {
+		for k, val := range m {
+			if s, ok := val.(string); ok {
+				tags[k] = s
+			}
+		}
+		return tags
+	}
+	arr, ok := ToSlice(v)
+	if !ok {
+		return tags
+	}
+	for _, item := range arr {
+		m, ok := ToMap(item)
+		if !ok {
+			continue
+		}
+		k := String(m, "key")
+		if k != "" {
+			tags[k] = String(m, "value")
+		}
+	}
+	return tags
+}
+
+// AnyCase 大小写不敏感地读取 map 中的 key
+func AnyCase(m map[string]interface{}, key string) interface{} {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	lower := strings.ToLower(key)
+	for k, v := range m {
+		if strings.ToLower(k) == lower {
+			return v
+		}
+	}
+	return nil
+}
+
+// ToMap 将 BSON 文档或普通 map 转换为 map[string]interface{}
+func ToMap(v interface{}) (map[string]interface{}, bool) {
+	switch t := v.(type) {
+	case bson.M:
+		return map[string]interface{}(t), true
+	case bson.D:
+		return dToMap(t), true
+	case map[string]interface{}:
+		return t, true
+	default:
+		return nil, false
+	}
+}
+
+// ToSlice 将 BSON 数组转换为 []interface{}
+func ToSlice(v interface{}) ([]interface{}, bool) {
+	switch t := v.(type) {
+	case []interface{}:
+		return t, true
+	case bson.A:
+		return []interface{}(t), true
+	default:
+		return nil, false
+	}
+}
+
+// dToMap converts bson.D (ordered document) to a plain map
+func dToMap(d bson.D) map[string]interface{} {
+	m := make(map[string]interface{}, len(d))
+	for _, e := range d {
+		m[e.Key] = e.Value
+	}
+	return m
+}
+
+// toBsonMap converts arbitrary structs to bson.M via BSON round trip
+func toBsonMap(v interface{}) bson.M {
+	if v == nil {
+		return nil
+	}
+	b, err := bson.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m bson.M
+	if err := bson.Unmarshal(b, &m); err != nil {
+		return nil
+	}
+	return m
+}
+
+// MapValues 按 key 排序返回 map 中所有对象类型的值
+func MapValues(m map[string]interface{}) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(m))
+	for _, k := range SortedKeys(m) {
+		if im, ok := ToMap(m[k]); ok {
+			out = append(out, im)
+		}
+	}
+	return out
+}
+
+func SortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func AppendUnique(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func FirstString(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func FirstNonNil(values ...interface{}) interface{} {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
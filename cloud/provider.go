@@ -0,0 +1,59 @@
+package cloud
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/xid-protocol/xidp/protocols"
+)
+
+// CloudProvider 云厂商抽象：采集资产并统一输出相同结构的攻击面记录
+type CloudProvider interface {
+	// Name 厂商标识，如 aws、gcp，同时作为 XID 类型前缀
+	Name() string
+	// Collect 采集全部资源并生成攻击面 XID
+	Collect() ([]*protocols.XID, error)
+	// ListAssets 返回实例级暴露记录
+	ListAssets() ([]*Asset, error)
+	// PublicIPs 返回 资产ID -> 公网 IP
+	PublicIPs() (map[string][]string, error)
+	// FirewallRules 返回 资产ID -> 入方向规则
+	FirewallRules() (map[string][]Rule, error)
+}
+
+// Factory 按需创建 provider，避免未启用的厂商在启动时连接数据库
+type Factory func() CloudProvider
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register 注册云厂商实现，重复注册会覆盖
+func Register(name string, f Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[name] = f
+}
+
+// New 创建指定名称的 provider，未注册时返回 nil
+func New(name string) CloudProvider {
+	mu.RLock()
+	defer mu.RUnlock()
+	if f, ok := factories[name]; ok {
+		return f()
+	}
+	return nil
+}
+
+// Names 返回已注册的厂商名称
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]string, 0, len(factories))
+	for name := range factories {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,26 @@
+package cloud
+
+// RiskScore 实例的综合风险评分，0-100，Factors 记录评分依据
+type RiskScore struct {
+	Score   int          `json:"score"`
+	Level   string       `json:"level"`
+	Ports   []PortRisk   `json:"ports,omitempty"`
+	Factors []RiskFactor `json:"factors"`
+}
+
+// PortRisk 单个暴露端口的评分
+type PortRisk struct {
+	Port     int      `json:"port"`
+	Protocol string   `json:"protocol"`
+	Service  string   `json:"service"`
+	Exposure Exposure `json:"exposure"`
+	State    string   `json:"state,omitempty"`
+	Score    int      `json:"score"`
+}
+
+// RiskFactor 参与评分的因素，Weight 为乘数或加分
+type RiskFactor struct {
+	Name   string  `json:"name"`
+	Detail string  `json:"detail,omitempty"`
+	Weight float64 `json:"weight"`
+}
@@ -0,0 +1,94 @@
+package cloud
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/xidp/protocols"
+	"github.com/xid-protocol/xidp/xdb"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Store 采集器写入的 XID 集合，各云厂商各自一个集合（aws_info、gcp_info ...）
+type Store struct {
+	Ctx      context.Context
+	DBClient *xdb.Client
+}
+
+// 所有 Store 共用一个 Mongo 连接池，首次创建 Store 时建立，进程退出前由 CloseStores 断开
+var (
+	clientMu sync.Mutex
+	client   *mongo.Client
+)
+
+func sharedClient(ctx context.Context) *mongo.Client {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	if client == nil {
+		mc, err := mongo.Connect(ctx, options.Client().ApplyURI(viper.GetString("mongodb.uri")))
+		if err != nil {
+			log.Fatal(err)
+		}
+		client = mc
+	}
+	return client
+}
+
+func NewStore(collection string) *Store {
+	ctx := context.Background()
+
+	// 使用共用连接获取集合
+	col := sharedClient(ctx).Database(viper.GetString("mongodb.database")).Collection(collection)
+
+	dbClient := xdb.NewClientWithMongo(col, &xdb.ClientOptions{
+		EnableIdempotency: true,
+		DefaultTimeout:    2 * time.Second,
+	})
+	return &Store{
+		Ctx:      ctx,
+		DBClient: dbClient,
+	}
+}
+
+// CloseStores 断开共用的 Mongo 连接，之后创建的 Store 会重新连接
+func CloseStores() error {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	if client == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := client.Disconnect(ctx)
+	client = nil
+	return err
+}
+
+// ListByPath 分页拉取指定 path 下每个 xid 的最新记录
+func (s *Store) ListByPath(path string) ([]*protocols.XID, error) {
+	q := xdb.Query{
+		Path:     path,
+		PageSize: 100,
+		SortBy:   "createdAt",
+		SortAsc:  false,
+	}
+
+	result := make([]*protocols.XID, 0)
+	for {
+		items, next, err := s.DBClient.List(s.Ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+
+		if next == "" {
+			break
+		}
+		q.AfterCursor = &next
+	}
+	return result, nil
+}
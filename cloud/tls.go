@@ -0,0 +1,31 @@
+package cloud
+
+import "time"
+
+// TLSEndpoint 单个 TLS 端口的证书链与协议支持情况
+type TLSEndpoint struct {
+	IP         string        `json:"ip"`
+	Port       int           `json:"port"`
+	ServerName string        `json:"serverName,omitempty"`
+	Versions   []string      `json:"versions"`
+	Ciphers    []string      `json:"ciphers"`
+	Chain      []Certificate `json:"chain"`
+	// Served 以其他域名作为 SNI 握手取得的叶子证书，key 为域名；按 SNI 选择证书的端点（如 ALB）各名称证书不同
+	Served    map[string]Certificate `json:"served,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	CheckedAt time.Time              `json:"checkedAt"`
+}
+
+type Certificate struct {
+	Subject            string    `json:"subject"`
+	Issuer             string    `json:"issuer"`
+	SANs               []string  `json:"sans,omitempty"`
+	SerialNumber       string    `json:"serialNumber"`
+	NotBefore          time.Time `json:"notBefore"`
+	NotAfter           time.Time `json:"notAfter"`
+	KeyType            string    `json:"keyType"`
+	KeySize            int       `json:"keySize"`
+	SignatureAlgorithm string    `json:"signatureAlgorithm"`
+	SelfSigned         bool      `json:"selfSigned"`
+	SHA256             string    `json:"sha256"`
+}
@@ -0,0 +1,29 @@
+package cloud
+
+import "time"
+
+// WebSurface HTTP(S) 端口的首页信息、安全响应头与敏感路径探测结果
+type WebSurface struct {
+	URL             string            `json:"url"`
+	Status          int               `json:"status"`
+	Title           string            `json:"title,omitempty"`
+	Server          string            `json:"server,omitempty"`
+	RedirectChain   []string          `json:"redirectChain,omitempty"`
+	FinalURL        string            `json:"finalUrl"`
+	SecurityHeaders map[string]string `json:"securityHeaders"`
+	MissingHeaders  []string          `json:"missingHeaders,omitempty"`
+	// FaviconHash Shodan 风格的 mmh3(base64(favicon))
+	FaviconHash   *int32    `json:"faviconHash,omitempty"`
+	FaviconSHA256 string    `json:"faviconSha256,omitempty"`
+	Paths         []WebPath `json:"paths,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	CheckedAt     time.Time `json:"checkedAt"`
+}
+
+// WebPath 命中的管理、调试类路径
+type WebPath struct {
+	Path     string `json:"path"`
+	Status   int    `json:"status"`
+	Length   int    `json:"length"`
+	Severity string `json:"severity"`
+}
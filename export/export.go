@@ -0,0 +1,114 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/query"
+)
+
+// 导出文件名
+const (
+	fileJSON     = "attack-surface.json"
+	fileAssets   = "attack-surface-assets.csv"
+	fileFindings = "attack-surface-findings.csv"
+	fileGroups   = "attack-surface-groups.csv"
+)
+
+// WriteJSON 输出完整的过滤结果
+func WriteJSON(w io.Writer, res *query.Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(res)
+}
+
+// WriteAssetsCSV 每个资产一行
+func WriteAssetsCSV(w io.Writer, res *query.Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"provider", "account", "region", "vpc", "id", "name", "type", "team", "owner", "exposure", "risk", "level", "publicIps", "ports"})
+	for _, a := range res.Assets {
+		r := query.FromAsset(a)
+		var ports []string
+		for _, p := range r.Ports {
+			ports = append(ports, portString(p))
+		}
+		var level string
+		if a.Risk != nil {
+			level = a.Risk.Level
+		}
+		cw.Write([]string{r.Provider, r.Account, r.Region, r.VpcID, r.ID, r.Name, r.ResourceType, r.Team, r.Owner,
+			r.Exposure, strconv.Itoa(r.Risk), level, strings.Join(a.PublicIPs, " "), strings.Join(ports, " ")})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteFindingsCSV 每个问题一行
+func WriteFindingsCSV(w io.Writer, res *query.Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "severity", "rule", "title", "provider", "account", "region", "resourceId", "resourceName", "target", "team", "owner", "status", "state", "detectedAt"})
+	for _, f := range res.Findings {
+		var team, owner string
+		if f.Owner != nil {
+			team, owner = f.Owner.Team, f.Owner.Owner
+		}
+		cw.Write([]string{f.ID, string(f.Severity), f.Rule, f.Title, f.Provider, f.Account, f.Region, f.ResourceID, f.ResourceName,
+			f.Target, team, owner, string(f.Status), string(f.State), f.DetectedAt.Format("2006-01-02T15:04:05Z07:00")})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteGroupsCSV 每个分组一行
+func WriteGroupsCSV(w io.Writer, res *query.Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{res.GroupBy, "assets", "findings", "maxRisk", "maxSeverity", "critical", "high", "medium", "low", "info"})
+	for _, g := range res.Groups {
+		cw.Write([]string{g.Key, strconv.Itoa(g.Assets), strconv.Itoa(g.Findings), strconv.Itoa(g.MaxRisk), g.MaxSeverity,
+			strconv.Itoa(g.Severities["critical"]), strconv.Itoa(g.Severities["high"]), strconv.Itoa(g.Severities["medium"]),
+			strconv.Itoa(g.Severities["low"]), strconv.Itoa(g.Severities["info"])})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Export 在目录下写入 JSON 与资产、问题的 CSV，有分组时额外写入分组 CSV
+func Export(dir string, res *query.Result) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	files := map[string]func(io.Writer, *query.Result) error{
+		fileJSON:     WriteJSON,
+		fileAssets:   WriteAssetsCSV,
+		fileFindings: WriteFindingsCSV,
+	}
+	if res.GroupBy != "" {
+		files[fileGroups] = WriteGroupsCSV
+	}
+	for name, write := range files {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		err = write(f, res)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func portString(p [2]int) string {
+	if p[0] == p[1] {
+		return strconv.Itoa(p[0])
+	}
+	return strconv.Itoa(p[0]) + "-" + strconv.Itoa(p[1])
+}
@@ -0,0 +1,199 @@
+package finding
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/xidp/protocols"
+)
+
+const PathFinding = "/protocols/external-attack-surface/finding"
+
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Rank 严重程度排序，未知等级为 0
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityInfo:
+		return 1
+	case SeverityLow:
+		return 2
+	case SeverityMedium:
+		return 3
+	case SeverityHigh:
+		return 4
+	case SeverityCritical:
+		return 5
+	default:
+		return 0
+	}
+}
+
+// ParseSeverity 解析配置中的严重程度，大小写不敏感
+func ParseSeverity(s string) Severity {
+	sev := Severity(strings.ToLower(strings.TrimSpace(s)))
+	if sev.Rank() == 0 {
+		return ""
+	}
+	return sev
+}
+
+// path /protocols/external-attack-surface/finding
+type Finding struct {
+	// ID 由规则、资源与目标计算的稳定标识，同一问题多次检出保持不变
+	ID           string   `json:"id"`
+	Rule         string   `json:"rule"`
+	Severity     Severity `json:"severity"`
+	Title        string   `json:"title"`
+	Detail       string   `json:"detail,omitempty"`
+	Provider     string   `json:"provider"`
+	Account      string   `json:"account,omitempty"`
+	ResourceID   string   `json:"resourceId"`
+	ResourceType string   `json:"resourceType"`
+	ResourceName string   `json:"resourceName,omitempty"`
+	Region       string   `json:"region,omitempty"`
+	VpcID        string   `json:"vpcId,omitempty"`
+	Target       string   `json:"target,omitempty"`
+	// GroupID、端口范围与来源地址为可选的结构化字段，用于豁免范围匹配
+	GroupID    string            `json:"groupId,omitempty"`
+	FromPort   int               `json:"fromPort,omitempty"`
+	ToPort     int               `json:"toPort,omitempty"`
+	Sources    []string          `json:"sources,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Owner      *cloud.Ownership  `json:"owner,omitempty"`
+	Evidence   map[string]string `json:"evidence,omitempty"`
+	Status     Status            `json:"status"`
+	State      State             `json:"state,omitempty"`
+	Waiver     *WaiverRef        `json:"waiver,omitempty"`
+	DetectedAt time.Time         `json:"detectedAt"`
+}
+
+// Status 问题的处理状态
+type Status string
+
+const (
+	StatusOpen     Status = "open"
+	StatusWaived   Status = "waived"   // 命中有效豁免，不再告警但仍保留记录
+	StatusReopened Status = "reopened" // 命中的豁免均已过期
+)
+
+// State 跨扫描持久化的告警生命周期状态，与豁免状态 Status 相互独立
+type State string
+
+const (
+	StateNew          State = "new"
+	StateOpen         State = "open"
+	StateAcknowledged State = "acknowledged"
+	StateResolved     State = "resolved"
+	StateReopened     State = "reopened"
+)
+
+// WaiverRef 命中的豁免摘要
+type WaiverRef struct {
+	ID        string    `json:"id"`
+	Approver  string    `json:"approver,omitempty"`
+	Ticket    string    `json:"ticket,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// New 基于资产创建问题记录，target 为 ip:port、域名等具体对象
+func New(rule string, severity Severity, a *cloud.Asset, target, title, detail string) *Finding {
+	f := &Finding{
+		Rule:       rule,
+		Severity:   severity,
+		Title:      title,
+		Detail:     detail,
+		Target:     target,
+		Evidence:   map[string]string{},
+		Status:     StatusOpen,
+		DetectedAt: time.Now().UTC(),
+	}
+	if a != nil {
+		f.Provider = a.Provider
+		f.Account = a.Account
+		f.ResourceID = a.InstanceID
+		f.ResourceType = a.ResourceType
+		f.ResourceName = a.InstanceName
+		f.Region = a.Region
+		f.VpcID = a.VpcID
+		f.Tags = a.Tags
+		f.Owner = a.Owner
+	}
+	f.ID = Key(f.Rule, f.Provider, f.ResourceID, f.Target)
+	return f
+}
+
+// Key 计算稳定标识
+func Key(parts ...string) string {
+	sum := sha1.Sum([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// MatchRule 规则名是否匹配任一模式，支持 "tls-*" 形式的前缀匹配
+func MatchRule(patterns []string, rule string) bool {
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok && strings.HasPrefix(rule, prefix) {
+			return true
+		}
+		if p == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Sort 按严重程度降序、规则与资源排序
+func Sort(items []*Finding) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Severity.Rank() != items[j].Severity.Rank() {
+			return items[i].Severity.Rank() > items[j].Severity.Rank()
+		}
+		if items[i].Rule != items[j].Rule {
+			return items[i].Rule < items[j].Rule
+		}
+		return items[i].ResourceID < items[j].ResourceID
+	})
+}
+
+// Actionable 过滤掉已豁免的问题
+func Actionable(items []*Finding) []*Finding {
+	var out []*Finding
+	for _, f := range items {
+		if f.Status != StatusWaived {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// XIDs 将问题记录封装为 XID
+func XIDs(items []*Finding) []*protocols.XID {
+	out := make([]*protocols.XID, 0, len(items))
+	for _, f := range items {
+		out = append(out, cloud.NewAttackSurfaceXID(f.ID, "finding", PathFinding, f))
+	}
+	return out
+}
+
+// Of 从 XID 中取回问题记录，采集阶段产生的问题与资产一起返回
+func Of(xids []*protocols.XID) []*Finding {
+	var out []*Finding
+	for _, x := range xids {
+		if f, ok := x.Payload.(*Finding); ok {
+			out = append(out, f)
+		}
+	}
+	return out
+}
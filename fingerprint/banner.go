@@ -0,0 +1,165 @@
+package fingerprint
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+type sshProbe struct{}
+
+func (sshProbe) Name() string { return "ssh" }
+
+func (sshProbe) Ports() []int { return []int{22, 2222} }
+
+// Probe 读取 "SSH-2.0-OpenSSH_8.9p1 Ubuntu-3" 形式的版本标识
+func (sshProbe) Probe(ctx context.Context, addr string, timeout time.Duration) (*cloud.Fingerprint, error) {
+	conn, err := dial(ctx, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && line == "" {
+		return nil, err
+	}
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "SSH-") {
+		return nil, nil
+	}
+	fp := &cloud.Fingerprint{Banner: line, AuthRequired: boolPtr(true)}
+	parts := strings.SplitN(line, "-", 3)
+	if len(parts) == 3 {
+		software, _, _ := strings.Cut(parts[2], " ")
+		fp.Product, fp.Version, _ = strings.Cut(software, "_")
+		fp.Detail = map[string]string{"protocol": parts[1]}
+	}
+	return fp, nil
+}
+
+type smtpProbe struct{}
+
+func (smtpProbe) Name() string { return "smtp" }
+
+func (smtpProbe) Ports() []int { return []int{25, 587, 2525} }
+
+// Probe 读取 220 问候并发送 EHLO，记录 STARTTLS 与 AUTH 扩展
+func (smtpProbe) Probe(ctx context.Context, addr string, timeout time.Duration) (*cloud.Fingerprint, error) {
+	conn, err := dial(ctx, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	greeting, err := readSMTPReply(r)
+	if err != nil || !strings.HasPrefix(greeting, "220") {
+		return nil, err
+	}
+	fp := &cloud.Fingerprint{Banner: firstLine(greeting), Detail: map[string]string{}}
+	for _, product := range []string{"Postfix", "Exim", "Sendmail", "Microsoft ESMTP", "Exchange", "qmail", "OpenSMTPD", "Haraka"} {
+		if strings.Contains(greeting, product) {
+			fp.Product = product
+			break
+		}
+	}
+	if _, err := conn.Write([]byte("EHLO attack-surface.local\r\n")); err != nil {
+		return fp, nil
+	}
+	if reply, err := readSMTPReply(r); err == nil && strings.HasPrefix(reply, "250") {
+		for _, ext := range strings.Split(reply, "\n") {
+			ext = strings.ToUpper(strings.TrimSpace(ext))
+			if len(ext) < 4 {
+				continue
+			}
+			ext = ext[4:]
+			switch {
+			case ext == "STARTTLS":
+				fp.Detail["starttls"] = "true"
+			case strings.HasPrefix(ext, "AUTH"):
+				fp.Detail["auth"] = strings.TrimSpace(strings.TrimPrefix(ext, "AUTH"))
+			}
+		}
+	}
+	conn.Write([]byte("QUIT\r\n"))
+	return fp, nil
+}
+
+// readSMTPReply 读取多行应答，直到 "250 " 形式的末行
+func readSMTPReply(r *bufio.Reader) (string, error) {
+	var b strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		b.WriteString(line)
+		if err != nil {
+			return b.String(), err
+		}
+		if len(line) < 4 || line[3] != '-' {
+			return b.String(), nil
+		}
+	}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimSpace(line)
+}
+
+type redisProbe struct{}
+
+func (redisProbe) Name() string { return "redis" }
+
+func (redisProbe) Ports() []int { return []int{6379, 6380} }
+
+// Probe 发送 PING，未开启认证时继续读取 INFO server 获取版本
+func (redisProbe) Probe(ctx context.Context, addr string, timeout time.Duration) (*cloud.Fingerprint, error) {
+	conn, err := dial(ctx, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("PING\r\n")); err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	fp := &cloud.Fingerprint{Product: "Redis"}
+	switch {
+	case strings.HasPrefix(line, "+PONG"):
+		fp.AuthRequired = boolPtr(false)
+	case strings.HasPrefix(line, "-NOAUTH"), strings.HasPrefix(line, "-WRONGPASS"):
+		fp.AuthRequired = boolPtr(true)
+		return fp, nil
+	case strings.HasPrefix(line, "-DENIED"):
+		// protected-mode 拒绝外部连接
+		fp.AuthRequired = boolPtr(true)
+		fp.Detail = map[string]string{"protectedMode": "true"}
+		return fp, nil
+	default:
+		return nil, nil
+	}
+
+	if _, err := conn.Write([]byte("INFO server\r\n")); err != nil {
+		return fp, nil
+	}
+	// 应答为 bulk string：$<len>\r\n<body>\r\n
+	if header, err := r.ReadString('\n'); err != nil || !strings.HasPrefix(header, "$") {
+		return fp, nil
+	}
+	for i := 0; i < 64; i++ {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			break
+		}
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), "redis_version:"); ok {
+			fp.Version = v
+			break
+		}
+	}
+	return fp, nil
+}
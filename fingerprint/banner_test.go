@@ -0,0 +1,105 @@
+package fingerprint
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestSSHBanner(t *testing.T) {
+	addr := serve(t, func(conn net.Conn) {
+		conn.Write([]byte("SSH-2.0-OpenSSH_8.9p1 Ubuntu-3ubuntu0.6\r\n"))
+	})
+	fp := probe(t, sshProbe{}, addr)
+	wantAuth(t, fp, true)
+	if fp.Product != "OpenSSH" || fp.Version != "8.9p1" || fp.Detail["protocol"] != "2.0" {
+		t.Fatalf("unexpected fingerprint %+v", fp)
+	}
+}
+
+func TestSSHNotSSH(t *testing.T) {
+	addr := serve(t, func(conn net.Conn) {
+		conn.Write([]byte("220 mail.example.com ESMTP Postfix\r\n"))
+	})
+	if fp := probe(t, sshProbe{}, addr); fp != nil {
+		t.Fatalf("unexpected fingerprint %+v", fp)
+	}
+}
+
+func TestSMTPExtensions(t *testing.T) {
+	addr := serve(t, func(conn net.Conn) {
+		conn.Write([]byte("220 mail.example.com ESMTP Postfix (Ubuntu)\r\n"))
+		r := bufio.NewReader(conn)
+		if line, _ := r.ReadString('\n'); !strings.HasPrefix(line, "EHLO ") {
+			t.Errorf("unexpected command %q", line)
+			return
+		}
+		conn.Write([]byte("250-mail.example.com\r\n250-PIPELINING\r\n250-STARTTLS\r\n250-AUTH PLAIN LOGIN\r\n250 8BITMIME\r\n"))
+		r.ReadString('\n')
+	})
+	fp := probe(t, smtpProbe{}, addr)
+	if fp == nil || fp.Product != "Postfix" || fp.Banner != "220 mail.example.com ESMTP Postfix (Ubuntu)" {
+		t.Fatalf("unexpected fingerprint %+v", fp)
+	}
+	if fp.Detail["starttls"] != "true" || fp.Detail["auth"] != "PLAIN LOGIN" {
+		t.Fatalf("unexpected detail %v", fp.Detail)
+	}
+}
+
+// redisServer 对 PING 返回 pong，未开启认证时对 INFO server 返回 info
+func redisServer(t *testing.T, pong, info string) string {
+	return serve(t, func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch strings.TrimSpace(line) {
+			case "PING":
+				conn.Write([]byte(pong))
+			case "INFO server":
+				fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(info), info)
+			default:
+				t.Errorf("unexpected command %q", line)
+				return
+			}
+		}
+	})
+}
+
+func TestRedisUnauthenticated(t *testing.T) {
+	addr := redisServer(t, "+PONG\r\n", "# Server\r\nredis_version:7.2.4\r\nredis_mode:standalone\r\n")
+	fp := probe(t, redisProbe{}, addr)
+	wantAuth(t, fp, false)
+	if fp.Product != "Redis" || fp.Version != "7.2.4" {
+		t.Fatalf("unexpected fingerprint %+v", fp)
+	}
+}
+
+func TestRedisNoAuth(t *testing.T) {
+	addr := redisServer(t, "-NOAUTH Authentication required.\r\n", "")
+	fp := probe(t, redisProbe{}, addr)
+	wantAuth(t, fp, true)
+	if fp.Version != "" {
+		t.Fatalf("version read without authentication: %+v", fp)
+	}
+}
+
+func TestRedisProtectedMode(t *testing.T) {
+	addr := redisServer(t, "-DENIED Redis is running in protected mode because protected mode is enabled\r\n", "")
+	fp := probe(t, redisProbe{}, addr)
+	wantAuth(t, fp, true)
+	if fp.Detail["protectedMode"] != "true" {
+		t.Fatalf("unexpected detail %v", fp.Detail)
+	}
+}
+
+func TestRedisNotRedis(t *testing.T) {
+	addr := redisServer(t, "HTTP/1.1 400 Bad Request\r\n", "")
+	if fp := probe(t, redisProbe{}, addr); fp != nil {
+		t.Fatalf("unexpected fingerprint %+v", fp)
+	}
+}
@@ -0,0 +1,231 @@
+package fingerprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type mysqlProbe struct{}
+
+func (mysqlProbe) Name() string { return "mysql" }
+
+func (mysqlProbe) Ports() []int { return []int{3306, 3307} }
+
+// Probe 解析服务端握手包：3 字节长度、1 字节序号、协议版本 10、以 \0 结尾的版本字符串
+func (mysqlProbe) Probe(ctx context.Context, addr string, timeout time.Duration) (*cloud.Fingerprint, error) {
+	conn, err := dial(ctx, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	if length == 0 || length > 1<<16 {
+		return nil, nil
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return nil, err
+	}
+	fp := &cloud.Fingerprint{Product: "MySQL", AuthRequired: boolPtr(true)}
+	switch payload[0] {
+	case 0x0a:
+		end := bytes.IndexByte(payload[1:], 0)
+		if end < 0 {
+			return nil, nil
+		}
+		fp.Version = string(payload[1 : 1+end])
+		if bytes.Contains(bytes.ToLower([]byte(fp.Version)), []byte("mariadb")) {
+			fp.Product = "MariaDB"
+		}
+	case 0xff:
+		// 错误包，如 "Host is not allowed to connect"
+		if len(payload) > 3 {
+			fp.Detail = map[string]string{"error": string(payload[3:])}
+		}
+	default:
+		return nil, nil
+	}
+	return fp, nil
+}
+
+// maxPGMessages 认证完成后最多读取的消息数，避免异常服务端持续发送
+const maxPGMessages = 64
+
+type postgresProbe struct{}
+
+func (postgresProbe) Name() string { return "postgresql" }
+
+func (postgresProbe) Ports() []int { return []int{5432, 5433} }
+
+// Probe 以 postgres 用户发送 StartupMessage，根据认证请求类型判断是否需要密码
+func (postgresProbe) Probe(ctx context.Context, addr string, timeout time.Duration) (*cloud.Fingerprint, error) {
+	conn, err := dial(ctx, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var body bytes.Buffer
+	binary.Write(&body, binary.BigEndian, int32(196608)) // 协议版本 3.0
+	for _, kv := range []string{"user", "postgres", "database", "postgres"} {
+		body.WriteString(kv)
+		body.WriteByte(0)
+	}
+	body.WriteByte(0)
+	msg := make([]byte, 4, 4+body.Len())
+	binary.BigEndian.PutUint32(msg, uint32(4+body.Len()))
+	if _, err := conn.Write(append(msg, body.Bytes()...)); err != nil {
+		return nil, err
+	}
+
+	fp := &cloud.Fingerprint{Product: "PostgreSQL"}
+	for i := 0; i < maxPGMessages; i++ {
+		kind, payload, err := readPGMessage(conn)
+		if err != nil {
+			if fp.AuthRequired != nil {
+				return fp, nil
+			}
+			return nil, err
+		}
+		switch kind {
+		case 'R':
+			if len(payload) < 4 {
+				return nil, nil
+			}
+			code := binary.BigEndian.Uint32(payload)
+			if code != 0 {
+				// 3 明文、5 MD5、10 SASL 等
+				fp.AuthRequired = boolPtr(true)
+				return fp, nil
+			}
+			fp.AuthRequired = boolPtr(false)
+		case 'S':
+			parts := bytes.Split(payload, []byte{0})
+			if len(parts) >= 2 && string(parts[0]) == "server_version" {
+				fp.Version = string(parts[1])
+			}
+		case 'Z':
+			conn.Write([]byte{'X', 0, 0, 0, 4})
+			return fp, nil
+		case 'E':
+			// pg_hba 拒绝或数据库不存在，仍可确认为 PostgreSQL
+			fp.AuthRequired = boolPtr(true)
+			fp.Detail = map[string]string{"error": pgErrorMessage(payload)}
+			return fp, nil
+		default:
+			// 认证通过后服务端还会发送 BackendKeyData（K）、NoticeResponse（N）等，跳过直到 ReadyForQuery；
+			// 认证请求之前出现其他消息说明不是 PostgreSQL
+			if fp.AuthRequired == nil {
+				return nil, nil
+			}
+		}
+	}
+	return fp, nil
+}
+
+func readPGMessage(conn net.Conn) (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return 0, nil, err
+	}
+	length := int(binary.BigEndian.Uint32(header[1:]))
+	if length < 4 || length > 1<<20 {
+		return 0, nil, errors.New("invalid postgres message length")
+	}
+	payload := make([]byte, length-4)
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[0], payload, nil
+}
+
+// pgErrorMessage 取出错误响应中的 M 字段
+func pgErrorMessage(payload []byte) string {
+	for _, field := range bytes.Split(payload, []byte{0}) {
+		if len(field) > 1 && field[0] == 'M' {
+			return string(field[1:])
+		}
+	}
+	return ""
+}
+
+type mongoProbe struct{}
+
+func (mongoProbe) Name() string { return "mongodb" }
+
+func (mongoProbe) Ports() []int { return []int{27017, 27018} }
+
+// Probe 通过 OP_MSG 依次执行 buildInfo 与 listDatabases，后者被拒绝时说明开启了认证
+func (mongoProbe) Probe(ctx context.Context, addr string, timeout time.Duration) (*cloud.Fingerprint, error) {
+	conn, err := dial(ctx, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	info, err := mongoCommand(conn, 1, bson.D{{Key: "buildInfo", Value: 1}, {Key: "$db", Value: "admin"}})
+	if err != nil {
+		return nil, err
+	}
+	fp := &cloud.Fingerprint{Product: "MongoDB"}
+	if v, ok := info["version"].(string); ok {
+		fp.Version = v
+	}
+	dbs, err := mongoCommand(conn, 2, bson.D{{Key: "listDatabases", Value: 1}, {Key: "nameOnly", Value: true}, {Key: "$db", Value: "admin"}})
+	if err != nil {
+		return fp, nil
+	}
+	if ok, _ := dbs["ok"].(float64); ok == 1 {
+		fp.AuthRequired = boolPtr(false)
+	} else {
+		fp.AuthRequired = boolPtr(true)
+	}
+	return fp, nil
+}
+
+// mongoCommand 发送 OP_MSG（opCode 2013）并解析 kind 0 的响应文档
+func mongoCommand(conn net.Conn, requestID int32, cmd bson.D) (bson.M, error) {
+	doc, err := bson.Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+	msg := make([]byte, 16, 16+5+len(doc))
+	binary.LittleEndian.PutUint32(msg[0:], uint32(16+5+len(doc)))
+	binary.LittleEndian.PutUint32(msg[4:], uint32(requestID))
+	binary.LittleEndian.PutUint32(msg[12:], 2013)
+	msg = append(msg, 0, 0, 0, 0, 0)
+	msg = append(msg, doc...)
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	length := int(binary.LittleEndian.Uint32(header))
+	if binary.LittleEndian.Uint32(header[12:]) != 2013 || length < 21 || length > 16<<20 {
+		return nil, errors.New("not a mongodb OP_MSG reply")
+	}
+	body := make([]byte, length-16)
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return nil, err
+	}
+	var out bson.M
+	if err := bson.Unmarshal(body[5:], &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,174 @@
+package fingerprint
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// pgMessage 编码 PostgreSQL 后端消息：1 字节类型、4 字节长度（含自身）、消息体
+func pgMessage(kind byte, payload []byte) []byte {
+	msg := []byte{kind, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(msg[1:], uint32(4+len(payload)))
+	return append(msg, payload...)
+}
+
+func pgAuth(code uint32, extra ...byte) []byte {
+	payload := binary.BigEndian.AppendUint32(nil, code)
+	return pgMessage('R', append(payload, extra...))
+}
+
+// pgServer 读取 StartupMessage 后依次发送 messages
+func pgServer(t *testing.T, messages ...[]byte) string {
+	return serve(t, func(conn net.Conn) {
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		startup := make([]byte, binary.BigEndian.Uint32(header)-4)
+		if _, err := io.ReadFull(conn, startup); err != nil {
+			return
+		}
+		if binary.BigEndian.Uint32(startup) != 196608 || !bytes.Contains(startup, []byte("user\x00postgres\x00")) {
+			t.Errorf("unexpected startup message %q", startup)
+			return
+		}
+		for _, m := range messages {
+			conn.Write(m)
+		}
+		// 等待客户端发送 Terminate 或关闭连接
+		io.Copy(io.Discard, conn)
+	})
+}
+
+func TestPostgresTrust(t *testing.T) {
+	// trust 认证：AuthenticationOk 之后服务端还会发送 ParameterStatus、BackendKeyData、NoticeResponse，最后 ReadyForQuery
+	addr := pgServer(t,
+		pgAuth(0),
+		pgMessage('S', []byte("server_version\x0016.2\x00")),
+		pgMessage('S', []byte("client_encoding\x00UTF8\x00")),
+		pgMessage('K', []byte{0, 0, 0, 1, 0, 0, 0, 2}),
+		pgMessage('N', []byte("SNOTICE\x00Mtrust connection\x00\x00")),
+		pgMessage('Z', []byte{'I'}),
+	)
+	fp := probe(t, postgresProbe{}, addr)
+	wantAuth(t, fp, false)
+	if fp.Product != "PostgreSQL" || fp.Version != "16.2" {
+		t.Fatalf("unexpected fingerprint %+v", fp)
+	}
+}
+
+func TestPostgresMD5(t *testing.T) {
+	addr := pgServer(t, pgAuth(5, 0x01, 0x02, 0x03, 0x04))
+	wantAuth(t, probe(t, postgresProbe{}, addr), true)
+}
+
+func TestPostgresSASL(t *testing.T) {
+	addr := pgServer(t, pgAuth(10, []byte("SCRAM-SHA-256\x00\x00")...))
+	wantAuth(t, probe(t, postgresProbe{}, addr), true)
+}
+
+func TestPostgresRejected(t *testing.T) {
+	addr := pgServer(t, pgMessage('E', []byte("SFATAL\x00C28000\x00Mno pg_hba.conf entry for host\x00\x00")))
+	fp := probe(t, postgresProbe{}, addr)
+	wantAuth(t, fp, true)
+	if fp.Detail["error"] != "no pg_hba.conf entry for host" {
+		t.Fatalf("unexpected detail %v", fp.Detail)
+	}
+}
+
+func TestPostgresNotPostgres(t *testing.T) {
+	// 认证请求之前出现其他消息，说明不是 PostgreSQL
+	addr := pgServer(t, pgMessage('K', []byte{0, 0, 0, 1, 0, 0, 0, 2}))
+	if fp := probe(t, postgresProbe{}, addr); fp != nil {
+		t.Fatalf("unexpected fingerprint %+v", fp)
+	}
+}
+
+// mongoServer 解析 OP_MSG 请求，按命令名从 replies 中取响应文档
+func mongoServer(t *testing.T, replies map[string]bson.D) string {
+	return serve(t, func(conn net.Conn) {
+		for {
+			header := make([]byte, 16)
+			if _, err := io.ReadFull(conn, header); err != nil {
+				return
+			}
+			if binary.LittleEndian.Uint32(header[12:]) != 2013 {
+				t.Errorf("unexpected opCode %d", binary.LittleEndian.Uint32(header[12:]))
+				return
+			}
+			body := make([]byte, binary.LittleEndian.Uint32(header)-16)
+			if _, err := io.ReadFull(conn, body); err != nil {
+				return
+			}
+			var cmd bson.D
+			if err := bson.Unmarshal(body[5:], &cmd); err != nil || len(cmd) == 0 {
+				t.Errorf("decode command: %v", err)
+				return
+			}
+			doc, _ := bson.Marshal(replies[cmd[0].Key])
+			reply := make([]byte, 16, 16+5+len(doc))
+			binary.LittleEndian.PutUint32(reply[0:], uint32(16+5+len(doc)))
+			binary.LittleEndian.PutUint32(reply[8:], binary.LittleEndian.Uint32(header[4:]))
+			binary.LittleEndian.PutUint32(reply[12:], 2013)
+			reply = append(reply, 0, 0, 0, 0, 0)
+			if _, err := conn.Write(append(reply, doc...)); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func TestMongoUnauthenticated(t *testing.T) {
+	addr := mongoServer(t, map[string]bson.D{
+		"buildInfo":     {{Key: "version", Value: "7.0.5"}, {Key: "ok", Value: 1.0}},
+		"listDatabases": {{Key: "databases", Value: bson.A{bson.D{{Key: "name", Value: "admin"}}}}, {Key: "ok", Value: 1.0}},
+	})
+	fp := probe(t, mongoProbe{}, addr)
+	wantAuth(t, fp, false)
+	if fp.Product != "MongoDB" || fp.Version != "7.0.5" {
+		t.Fatalf("unexpected fingerprint %+v", fp)
+	}
+}
+
+func TestMongoAuthRequired(t *testing.T) {
+	addr := mongoServer(t, map[string]bson.D{
+		"buildInfo": {{Key: "version", Value: "6.0.12"}, {Key: "ok", Value: 1.0}},
+		"listDatabases": {
+			{Key: "ok", Value: 0.0},
+			{Key: "errmsg", Value: "command listDatabases requires authentication"},
+			{Key: "code", Value: int32(13)},
+			{Key: "codeName", Value: "Unauthorized"},
+		},
+	})
+	wantAuth(t, probe(t, mongoProbe{}, addr), true)
+}
+
+func TestMySQLHandshake(t *testing.T) {
+	for name, tc := range map[string]struct {
+		payload []byte
+		product string
+		version string
+		detail  string
+	}{
+		"mysql":   {append([]byte("\x0a8.0.36\x00"), make([]byte, 40)...), "MySQL", "8.0.36", ""},
+		"mariadb": {append([]byte("\x0a5.5.5-10.11.6-MariaDB\x00"), make([]byte, 40)...), "MariaDB", "5.5.5-10.11.6-MariaDB", ""},
+		"denied":  {[]byte("\xffj\x04Host '10.0.0.1' is not allowed to connect"), "MySQL", "", "Host '10.0.0.1' is not allowed to connect"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			addr := serve(t, func(conn net.Conn) {
+				n := len(tc.payload)
+				conn.Write(append([]byte{byte(n), byte(n >> 8), byte(n >> 16), 0}, tc.payload...))
+			})
+			fp := probe(t, mysqlProbe{}, addr)
+			wantAuth(t, fp, true)
+			if fp.Product != tc.product || fp.Version != tc.version || fp.Detail["error"] != tc.detail {
+				t.Fatalf("unexpected fingerprint %+v", fp)
+			}
+		})
+	}
+}
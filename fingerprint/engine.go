@@ -0,0 +1,192 @@
+package fingerprint
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// Probe 协议识别探针，每次识别使用独立连接
+type Probe interface {
+	// Name 服务名，如 http、ssh、redis
+	Name() string
+	// Ports 该服务的常见端口，引擎优先对这些端口使用该探针
+	Ports() []int
+	// Probe 识别 addr 上的服务，不匹配时返回 nil
+	Probe(ctx context.Context, addr string, timeout time.Duration) (*cloud.Fingerprint, error)
+}
+
+var (
+	mu     sync.RWMutex
+	probes []Probe
+)
+
+// Register 注册探针，同名探针会被替换；未命中常见端口时按注册顺序依次尝试
+func Register(p Probe) {
+	mu.Lock()
+	defer mu.Unlock()
+	for i, existing := range probes {
+		if existing.Name() == p.Name() {
+			probes[i] = p
+			return
+		}
+	}
+	probes = append(probes, p)
+}
+
+func init() {
+	// 主动发送请求的探针在前，被动读取 banner 的探针（ssh、smtp、mysql）在后
+	Register(rdpProbe{})
+	Register(elasticsearchProbe{})
+	Register(httpProbe{})
+	Register(tlsProbe{})
+	Register(redisProbe{})
+	Register(postgresProbe{})
+	Register(mongoProbe{})
+	Register(sshProbe{})
+	Register(smtpProbe{})
+	Register(mysqlProbe{})
+}
+
+// Limiter 连接限速，由 verify.Verifier 实现，使识别连接与端口探测共用全局速率与单主机并发上限
+type Limiter interface {
+	Acquire(ctx context.Context, ip string) (release func(), err error)
+}
+
+type Engine struct {
+	Timeout time.Duration
+	Workers int
+	// Limiter 为空时不限速
+	Limiter Limiter
+}
+
+// NewEngineFromViper 读取 Fingerprint 配置段
+func NewEngineFromViper() *Engine {
+	e := &Engine{
+		Timeout: time.Duration(viper.GetInt("Fingerprint.timeout_ms")) * time.Millisecond,
+		Workers: viper.GetInt("Fingerprint.workers"),
+	}
+	if e.Timeout <= 0 {
+		e.Timeout = 3 * time.Second
+	}
+	if e.Workers <= 0 {
+		e.Workers = 16
+	}
+	return e
+}
+
+// Identify 识别单个端口，常见端口对应的探针优先，全部未命中时返回 nil
+func (e *Engine) Identify(ctx context.Context, ip string, port int) *cloud.Fingerprint {
+	addr := net.JoinHostPort(ip, strconv.Itoa(port))
+	for _, p := range ordered(port) {
+		if ctx.Err() != nil {
+			return nil
+		}
+		fp, err := e.probe(ctx, p, ip, addr)
+		if err != nil || fp == nil {
+			continue
+		}
+		fp.IP, fp.Port = ip, port
+		if fp.Service == "" {
+			fp.Service = p.Name()
+		}
+		fp.CheckedAt = time.Now().UTC()
+		return fp
+	}
+	return nil
+}
+
+// probe 每个探针使用一个独立连接，连接前经 Limiter 限速
+func (e *Engine) probe(ctx context.Context, p Probe, ip, addr string) (*cloud.Fingerprint, error) {
+	if e.Limiter != nil {
+		release, err := e.Limiter.Acquire(ctx, ip)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+	return p.Probe(ctx, addr, e.Timeout)
+}
+
+func ordered(port int) []Probe {
+	mu.RLock()
+	defer mu.RUnlock()
+	var hinted, rest []Probe
+	for _, p := range probes {
+		matched := false
+		for _, hp := range p.Ports() {
+			if hp == port {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			hinted = append(hinted, p)
+		} else {
+			rest = append(rest, p)
+		}
+	}
+	return append(hinted, rest...)
+}
+
+// FingerprintAssets 对已验证开放的端口做服务识别，结果写回 Asset.Fingerprints
+func (e *Engine) FingerprintAssets(ctx context.Context, assets []*cloud.Asset) {
+	type job struct {
+		asset *cloud.Asset
+		check cloud.PortCheck
+	}
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var identified int
+	for i := 0; i < e.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				fp := e.Identify(ctx, j.check.IP, j.check.Port)
+				if fp == nil {
+					continue
+				}
+				lock.Lock()
+				j.asset.Fingerprints = append(j.asset.Fingerprints, *fp)
+				identified++
+				lock.Unlock()
+			}
+		}()
+	}
+	var total int
+	for _, a := range assets {
+		for _, c := range a.Verification {
+			if c.State != cloud.PortOpen {
+				continue
+			}
+			total++
+			jobs <- job{asset: a, check: c}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	logx.Infof("fingerprint summary: open ports=%d, identified=%d", total, identified)
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// dial 建立带截止时间的 TCP 连接
+func dial(ctx context.Context, addr string, timeout time.Duration) (net.Conn, error) {
+	d := net.Dialer{Timeout: timeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	return conn, nil
+}
@@ -0,0 +1,94 @@
+package fingerprint
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+const testTimeout = 2 * time.Second
+
+// serve 在本地端口上模拟服务端，每个连接交给 handle 处理，返回监听地址
+func serve(t *testing.T, handle func(conn net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(testTimeout))
+				handle(conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// probe 对 addr 执行单个探针，出错时直接失败
+func probe(t *testing.T, p Probe, addr string) *cloud.Fingerprint {
+	t.Helper()
+	fp, err := p.Probe(context.Background(), addr, testTimeout)
+	if err != nil {
+		t.Fatalf("%s probe: %v", p.Name(), err)
+	}
+	return fp
+}
+
+// wantAuth 校验鉴权结论，nil 表示未能判断
+func wantAuth(t *testing.T, fp *cloud.Fingerprint, want bool) {
+	t.Helper()
+	if fp == nil {
+		t.Fatal("service not identified")
+	}
+	if fp.AuthRequired == nil || *fp.AuthRequired != want {
+		t.Fatalf("AuthRequired = %v, want %v (%+v)", fp.AuthRequired, want, fp)
+	}
+}
+
+type countingLimiter struct{ acquired, released int }
+
+func (l *countingLimiter) Acquire(context.Context, string) (func(), error) {
+	l.acquired++
+	return func() { l.released++ }, nil
+}
+
+// 非常见端口上按注册顺序尝试，主动探针不匹配后由被动读取 banner 的 ssh 探针识别
+func TestIdentifyFallsBackToBannerProbes(t *testing.T) {
+	addr := serve(t, func(conn net.Conn) {
+		conn.Write([]byte("SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13\r\n"))
+		// 读取并丢弃主动探针发送的请求，直到对端关闭
+		buf := make([]byte, 1024)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				return
+			}
+		}
+	})
+	host, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+
+	limiter := &countingLimiter{}
+	e := &Engine{Timeout: 500 * time.Millisecond, Workers: 1, Limiter: limiter}
+	fp := e.Identify(context.Background(), host, port)
+	if fp == nil || fp.Service != "ssh" || fp.Product != "OpenSSH" || fp.Version != "9.6p1" {
+		t.Fatalf("unexpected fingerprint %+v", fp)
+	}
+	if fp.IP != host || fp.Port != port || fp.CheckedAt.IsZero() {
+		t.Fatalf("fingerprint not annotated: %+v", fp)
+	}
+	if limiter.acquired == 0 || limiter.acquired != limiter.released {
+		t.Fatalf("limiter acquired %d, released %d", limiter.acquired, limiter.released)
+	}
+}
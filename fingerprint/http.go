@@ -0,0 +1,219 @@
+package fingerprint
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// 优先按 HTTPS 探测的端口
+var tlsFirstPorts = map[string]bool{"443": true, "8443": true, "9443": true, "6443": true, "10250": true}
+
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "TLS1.0",
+	tls.VersionTLS11: "TLS1.1",
+	tls.VersionTLS12: "TLS1.2",
+	tls.VersionTLS13: "TLS1.3",
+}
+
+// httpGet 对固定地址发起请求，不跟随跳转，证书不做校验
+func httpGet(ctx context.Context, addr, scheme, path string, timeout time.Duration) (*http.Response, []byte, error) {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			d := net.Dialer{Timeout: timeout}
+			return d.DialContext(ctx, network, addr)
+		},
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		DisableKeepAlives: true,
+	}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+addr+path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", "attack-surface-fingerprint")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	return resp, body, nil
+}
+
+// schemes 按端口决定 http/https 的尝试顺序
+func schemes(addr string) []string {
+	_, port, _ := net.SplitHostPort(addr)
+	if tlsFirstPorts[port] {
+		return []string{"https", "http"}
+	}
+	return []string{"http", "https"}
+}
+
+// splitProduct 拆分 "nginx/1.18.0 (Ubuntu)" 形式的产品与版本
+func splitProduct(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, " "); i > 0 {
+		s = s[:i]
+	}
+	product, version, _ := strings.Cut(s, "/")
+	return product, version
+}
+
+type httpProbe struct{}
+
+func (httpProbe) Name() string { return "http" }
+
+func (httpProbe) Ports() []int {
+	return []int{80, 443, 8000, 8008, 8080, 8081, 8443, 8888, 9000, 9090, 9443, 3000, 5000, 5601}
+}
+
+func (httpProbe) Probe(ctx context.Context, addr string, timeout time.Duration) (*cloud.Fingerprint, error) {
+	var lastErr error
+	for _, scheme := range schemes(addr) {
+		resp, body, err := httpGet(ctx, addr, scheme, "/", timeout)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if scheme == "http" && plainHTTPToHTTPS(resp, body) {
+			continue
+		}
+		fp := &cloud.Fingerprint{
+			Service: scheme,
+			TLS:     scheme == "https",
+			Banner:  resp.Header.Get("Server"),
+			Detail:  map[string]string{"status": resp.Status},
+		}
+		if resp.TLS != nil {
+			fp.Detail["tlsVersion"] = tlsVersions[resp.TLS.Version]
+		}
+		if v := resp.Header.Get("X-Powered-By"); v != "" {
+			fp.Detail["poweredBy"] = v
+		}
+		switch {
+		case resp.Header.Get("X-Jenkins") != "":
+			fp.Product, fp.Version = "Jenkins", resp.Header.Get("X-Jenkins")
+		case resp.Header.Get("X-Grafana-Version") != "":
+			fp.Product, fp.Version = "Grafana", resp.Header.Get("X-Grafana-Version")
+		case fp.Banner != "":
+			fp.Product, fp.Version = splitProduct(fp.Banner)
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusProxyAuthRequired || resp.Header.Get("WWW-Authenticate") != "":
+			fp.AuthRequired = boolPtr(true)
+		case resp.StatusCode == http.StatusForbidden && fp.Product == "Jenkins":
+			fp.AuthRequired = boolPtr(true)
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			fp.AuthRequired = boolPtr(false)
+		}
+		return fp, nil
+	}
+	return nil, lastErr
+}
+
+// plainHTTPToHTTPS 判断是否为明文请求发到 HTTPS 端口的 400 应答（nginx、Go 等），此时应改用 https 识别
+func plainHTTPToHTTPS(resp *http.Response, body []byte) bool {
+	return resp.StatusCode == http.StatusBadRequest && bytes.Contains(bytes.ToLower(body), []byte("https"))
+}
+
+type elasticsearchProbe struct{}
+
+func (elasticsearchProbe) Name() string { return "elasticsearch" }
+
+func (elasticsearchProbe) Ports() []int { return []int{9200, 9201} }
+
+func (elasticsearchProbe) Probe(ctx context.Context, addr string, timeout time.Duration) (*cloud.Fingerprint, error) {
+	var lastErr error
+	for _, scheme := range []string{"http", "https"} {
+		resp, body, err := httpGet(ctx, addr, scheme, "/", timeout)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		fp := &cloud.Fingerprint{Product: "Elasticsearch", TLS: scheme == "https"}
+		if resp.StatusCode == http.StatusUnauthorized {
+			if resp.Header.Get("X-Elastic-Product") == "" && !strings.Contains(resp.Header.Get("WWW-Authenticate"), `realm="security"`) {
+				return nil, nil
+			}
+			fp.AuthRequired = boolPtr(true)
+			return fp, nil
+		}
+		var info struct {
+			Tagline string `json:"tagline"`
+			Cluster string `json:"cluster_name"`
+			Version struct {
+				Number       string `json:"number"`
+				Distribution string `json:"distribution"`
+			} `json:"version"`
+		}
+		if json.Unmarshal(body, &info) != nil || info.Version.Number == "" {
+			return nil, nil
+		}
+		if info.Tagline != "You Know, for Search" && resp.Header.Get("X-Elastic-Product") == "" {
+			return nil, nil
+		}
+		if info.Version.Distribution == "opensearch" {
+			fp.Product = "OpenSearch"
+		}
+		fp.Version = info.Version.Number
+		fp.AuthRequired = boolPtr(false)
+		if info.Cluster != "" {
+			fp.Detail = map[string]string{"cluster": info.Cluster}
+		}
+		return fp, nil
+	}
+	return nil, lastErr
+}
+
+// tlsProbe 非 HTTP 的 TLS 服务（LDAPS、IMAPS 等），记录协商结果与证书主题
+type tlsProbe struct{}
+
+func (tlsProbe) Name() string { return "tls" }
+
+func (tlsProbe) Ports() []int { return []int{465, 636, 993, 995, 5986} }
+
+func (tlsProbe) Probe(ctx context.Context, addr string, timeout time.Duration) (*cloud.Fingerprint, error) {
+	conn, err := dial(ctx, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	host, _, _ := net.SplitHostPort(addr)
+	tc := tls.Client(conn, &tls.Config{InsecureSkipVerify: true, ServerName: host})
+	if err := tc.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+	state := tc.ConnectionState()
+	fp := &cloud.Fingerprint{
+		TLS: true,
+		Detail: map[string]string{
+			"tlsVersion": tlsVersions[state.Version],
+			"cipher":     tls.CipherSuiteName(state.CipherSuite),
+		},
+	}
+	if state.NegotiatedProtocol != "" {
+		fp.Detail["alpn"] = state.NegotiatedProtocol
+	}
+	if len(state.PeerCertificates) > 0 {
+		fp.Detail["subject"] = state.PeerCertificates[0].Subject.CommonName
+		fp.Detail["issuer"] = state.PeerCertificates[0].Issuer.CommonName
+	}
+	return fp, nil
+}
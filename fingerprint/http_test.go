@@ -0,0 +1,102 @@
+package fingerprint
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPProbe(t *testing.T) {
+	for name, tc := range map[string]struct {
+		header  map[string]string
+		status  int
+		product string
+		version string
+		auth    *bool
+	}{
+		"nginx":   {map[string]string{"Server": "nginx/1.24.0 (Ubuntu)"}, http.StatusOK, "nginx", "1.24.0", boolPtr(false)},
+		"basic":   {map[string]string{"WWW-Authenticate": `Basic realm="admin"`}, http.StatusUnauthorized, "", "", boolPtr(true)},
+		"jenkins": {map[string]string{"X-Jenkins": "2.440.1"}, http.StatusForbidden, "Jenkins", "2.440.1", boolPtr(true)},
+		"grafana": {map[string]string{"X-Grafana-Version": "10.3.1"}, http.StatusFound, "Grafana", "10.3.1", nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tc.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tc.status)
+			}))
+			defer srv.Close()
+			fp := probe(t, httpProbe{}, strings.TrimPrefix(srv.URL, "http://"))
+			if fp == nil || fp.Service != "http" || fp.TLS || fp.Product != tc.product || fp.Version != tc.version {
+				t.Fatalf("unexpected fingerprint %+v", fp)
+			}
+			if fmt.Sprint(deref(fp.AuthRequired)) != fmt.Sprint(deref(tc.auth)) {
+				t.Fatalf("AuthRequired = %v, want %v", deref(fp.AuthRequired), deref(tc.auth))
+			}
+		})
+	}
+}
+
+func TestHTTPProbeTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "Apache/2.4.58")
+	}))
+	defer srv.Close()
+	fp := probe(t, httpProbe{}, strings.TrimPrefix(srv.URL, "https://"))
+	wantAuth(t, fp, false)
+	if fp.Service != "https" || !fp.TLS || fp.Product != "Apache" || fp.Detail["tlsVersion"] == "" {
+		t.Fatalf("unexpected fingerprint %+v", fp)
+	}
+}
+
+func TestElasticsearchProbe(t *testing.T) {
+	for name, tc := range map[string]struct {
+		status  int
+		header  map[string]string
+		body    string
+		product string
+		auth    bool
+	}{
+		"open": {http.StatusOK, nil,
+			`{"cluster_name":"logs","version":{"number":"8.12.1"},"tagline":"You Know, for Search"}`, "Elasticsearch", false},
+		"opensearch": {http.StatusOK, nil,
+			`{"cluster_name":"logs","version":{"number":"2.11.0","distribution":"opensearch"},"tagline":"The OpenSearch Project: https://opensearch.org/"}`, "", false},
+		"secured": {http.StatusUnauthorized, map[string]string{"WWW-Authenticate": `Basic realm="security" charset="UTF-8"`}, "", "Elasticsearch", true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tc.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+			defer srv.Close()
+			fp, err := elasticsearchProbe{}.Probe(t.Context(), strings.TrimPrefix(srv.URL, "http://"), testTimeout)
+			if tc.product == "" {
+				// OpenSearch 的 tagline 不同且没有 X-Elastic-Product，不识别为 Elasticsearch
+				if fp != nil {
+					t.Fatalf("unexpected fingerprint %+v", fp)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantAuth(t, fp, tc.auth)
+			if fp.Product != tc.product {
+				t.Fatalf("unexpected fingerprint %+v", fp)
+			}
+		})
+	}
+}
+
+func deref(b *bool) interface{} {
+	if b == nil {
+		return nil
+	}
+	return *b
+}
@@ -0,0 +1,86 @@
+package fingerprint
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// X.224 Connection Request，携带 RDP_NEG_REQ 请求 TLS 与 CredSSP（NLA）
+var rdpNegotiationRequest = []byte{
+	0x03, 0x00, 0x00, 0x13, // TPKT
+	0x0e, 0xe0, 0x00, 0x00, 0x00, 0x00, 0x00, // X.224 CR
+	0x01, 0x00, 0x08, 0x00, 0x03, 0x00, 0x00, 0x00, // RDP_NEG_REQ: PROTOCOL_SSL | PROTOCOL_HYBRID
+}
+
+// RDP_NEG_RSP 中服务端选择的安全协议
+var rdpProtocols = map[byte]string{
+	0x00: "rdp",
+	0x01: "tls",
+	0x02: "nla",
+	0x08: "nla-ex",
+}
+
+type rdpProbe struct{}
+
+func (rdpProbe) Name() string { return "rdp" }
+
+func (rdpProbe) Ports() []int { return []int{3389} }
+
+// Probe 发送协商请求，服务端选择 NLA 时登录前即需凭证，否则登录界面直接暴露
+func (rdpProbe) Probe(ctx context.Context, addr string, timeout time.Duration) (*cloud.Fingerprint, error) {
+	conn, err := dial(ctx, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write(rdpNegotiationRequest); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, 19)
+	n, err := io.ReadAtLeast(conn, resp, 11)
+	if err != nil {
+		return nil, err
+	}
+	// TPKT 版本 3，X.224 Connection Confirm (0xd0)
+	if resp[0] != 0x03 || resp[5]&0xf0 != 0xd0 {
+		return nil, nil
+	}
+	fp := &cloud.Fingerprint{Product: "Microsoft Terminal Services", Detail: map[string]string{}}
+	if n < 19 {
+		// 旧版本不支持协商，只能使用标准 RDP 安全层
+		fp.Detail["security"] = "rdp"
+		fp.AuthRequired = boolPtr(false)
+		return fp, nil
+	}
+	switch resp[11] {
+	case 0x02:
+		proto := rdpProtocols[resp[15]]
+		fp.Detail["security"] = proto
+		fp.AuthRequired = boolPtr(proto == "nla" || proto == "nla-ex")
+	case 0x03:
+		// RDP_NEG_FAILURE，如服务端要求 NLA 而客户端未提供
+		fp.Detail["negotiationFailure"] = rdpFailure(resp[15])
+		fp.AuthRequired = boolPtr(resp[15] == 0x05)
+	}
+	return fp, nil
+}
+
+func rdpFailure(code byte) string {
+	switch code {
+	case 0x01:
+		return "SSL_REQUIRED_BY_SERVER"
+	case 0x02:
+		return "SSL_NOT_ALLOWED_BY_SERVER"
+	case 0x03:
+		return "SSL_CERT_NOT_ON_SERVER"
+	case 0x04:
+		return "INCONSISTENT_FLAGS"
+	case 0x05:
+		return "HYBRID_REQUIRED_BY_SERVER"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,57 @@
+package fingerprint
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+// rdpServer 校验协商请求后返回 X.224 Connection Confirm，neg 为空时模拟不支持协商的旧版本
+func rdpServer(t *testing.T, neg []byte) string {
+	return serve(t, func(conn net.Conn) {
+		req := make([]byte, len(rdpNegotiationRequest))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+		if !bytes.Equal(req, rdpNegotiationRequest) {
+			t.Errorf("unexpected negotiation request %x", req)
+			return
+		}
+		cc := []byte{0x06, 0xd0, 0x00, 0x00, 0x12, 0x34, 0x00}
+		resp := []byte{0x03, 0x00, 0x00, byte(4 + len(cc) + len(neg))}
+		conn.Write(append(append(resp, cc...), neg...))
+	})
+}
+
+func TestRDPNegotiation(t *testing.T) {
+	for name, tc := range map[string]struct {
+		neg      []byte
+		auth     bool
+		key, val string
+	}{
+		"nla":      {[]byte{0x02, 0x00, 0x08, 0x00, 0x02, 0x00, 0x00, 0x00}, true, "security", "nla"},
+		"tls":      {[]byte{0x02, 0x00, 0x08, 0x00, 0x01, 0x00, 0x00, 0x00}, false, "security", "tls"},
+		"legacy":   {nil, false, "security", "rdp"},
+		"hybrid":   {[]byte{0x03, 0x00, 0x08, 0x00, 0x05, 0x00, 0x00, 0x00}, true, "negotiationFailure", "HYBRID_REQUIRED_BY_SERVER"},
+		"ssl-only": {[]byte{0x03, 0x00, 0x08, 0x00, 0x01, 0x00, 0x00, 0x00}, false, "negotiationFailure", "SSL_REQUIRED_BY_SERVER"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			fp := probe(t, rdpProbe{}, rdpServer(t, tc.neg))
+			wantAuth(t, fp, tc.auth)
+			if fp.Detail[tc.key] != tc.val {
+				t.Fatalf("detail %v, want %s=%s", fp.Detail, tc.key, tc.val)
+			}
+		})
+	}
+}
+
+func TestRDPNotRDP(t *testing.T) {
+	addr := serve(t, func(conn net.Conn) {
+		io.ReadFull(conn, make([]byte, len(rdpNegotiationRequest)))
+		conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
+	})
+	if fp := probe(t, rdpProbe{}, addr); fp != nil {
+		t.Fatalf("unexpected fingerprint %+v", fp)
+	}
+}
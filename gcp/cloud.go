@@ -0,0 +1,94 @@
+package gcp
+
+import (
+	"github.com/colin-404/logx"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/xidp/protocols"
+)
+
+const ProviderName = "gcp"
+
+// 采集器写入 gcp_info 的资源路径
+const (
+	PathInstance       = "/info/gcp/instance"
+	PathFirewall       = "/info/gcp/firewall"
+	PathForwardingRule = "/info/gcp/forwarding-rule"
+	PathAddress        = "/info/gcp/address"
+
+	PathInstanceAttackSurface = "/protocols/external-attack-surface/gcp-instance"
+)
+
+type GCPCloud struct {
+	*cloud.Store
+}
+
+func init() {
+	cloud.Register(ProviderName, func() cloud.CloudProvider { return NewGCPCloud() })
+}
+
+func NewGCPCloud() *GCPCloud {
+	// 连接 Mongo 并获取 gcp_info 集合
+	return &GCPCloud{
+		Store: cloud.NewStore("gcp_info"),
+	}
+}
+
+func (c *GCPCloud) Name() string {
+	return ProviderName
+}
+
+// ListAssets 返回 Compute 实例、转发规则与未绑定的外部 IP
+func (c *GCPCloud) ListAssets() ([]*cloud.Asset, error) {
+	firewalls, err := c.loadFirewalls()
+	if err != nil {
+		return nil, err
+	}
+	instances, err := c.GetInstances(firewalls)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := c.GetForwardingRules()
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := c.GetReservedAddresses()
+	if err != nil {
+		return nil, err
+	}
+
+	out := append(instances, rules...)
+	out = append(out, addresses...)
+	var exposed int
+	for _, a := range out {
+		if cloud.IsWideExposure(a.Exposure) {
+			exposed++
+		}
+	}
+	logx.Infof("gcp attack surface summary: instances=%d, forwardingRules=%d, addresses=%d, firewalls=%d, exposed=%d",
+		len(instances), len(rules), len(addresses), len(firewalls), exposed)
+	return out, nil
+}
+
+func (c *GCPCloud) PublicIPs() (map[string][]string, error) {
+	assets, err := c.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.PublicIPsOf(assets), nil
+}
+
+func (c *GCPCloud) FirewallRules() (map[string][]cloud.Rule, error) {
+	assets, err := c.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.RulesOf(assets), nil
+}
+
+func (c *GCPCloud) Collect() ([]*protocols.XID, error) {
+	assets, err := c.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.AssetXIDs(assets, PathInstanceAttackSurface), nil
+}
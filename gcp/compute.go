@@ -0,0 +1,315 @@
+package gcp
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// 默认优先级，数值越小越优先
+const defaultPriority = 1000
+
+type fwEntry struct {
+	protocol string
+	from, to int
+}
+
+// firewall VPC 防火墙规则
+type firewall struct {
+	name         string
+	network      string
+	priority     int
+	deny         bool
+	sourceRanges []string
+	sourceTags   []string
+	sourceSAs    []string
+	targetTags   []string
+	targetSAs    []string
+	entries      []fwEntry
+}
+
+// instance 防火墙匹配所需的实例信息
+type instance struct {
+	payload    map[string]interface{}
+	id         string
+	network    string
+	tags       []string
+	accounts   []string
+	internalIP []string
+}
+
+func (c *GCPCloud) loadFirewalls() ([]*firewall, error) {
+	records, err := c.ListByPath(PathFirewall)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathFirewall, err)
+	}
+	var out []*firewall
+	for _, record := range records {
+		payload := cloud.PayloadOf(record)
+		name := cloud.String(payload, "name")
+		if name == "" || cloud.Bool(payload, "disabled") {
+			continue
+		}
+		// 只关心入方向
+		if d := cloud.String(payload, "direction"); d != "" && !strings.EqualFold(d, "INGRESS") {
+			continue
+		}
+		fw := &firewall{
+			name:         name,
+			network:      lastSegment(cloud.String(payload, "network")),
+			priority:     defaultPriority,
+			sourceRanges: cloud.Strings(payload, "sourceRanges"),
+			sourceTags:   cloud.Strings(payload, "sourceTags"),
+			sourceSAs:    cloud.Strings(payload, "sourceServiceAccounts"),
+			targetTags:   cloud.Strings(payload, "targetTags"),
+			targetSAs:    cloud.Strings(payload, "targetServiceAccounts"),
+		}
+		if p, ok := cloud.Int(payload, "priority"); ok {
+			fw.priority = p
+		}
+		entries := cloud.Maps(payload, "allowed")
+		if denied := cloud.Maps(payload, "denied"); len(denied) > 0 {
+			fw.deny = true
+			entries = denied
+		}
+		for _, e := range entries {
+			fw.entries = append(fw.entries, parseEntries(e)...)
+		}
+		out = append(out, fw)
+	}
+	// 按优先级排序，同优先级 deny 先于 allow
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].priority != out[j].priority {
+			return out[i].priority < out[j].priority
+		}
+		return out[i].deny && !out[j].deny
+	})
+	return out, nil
+}
+
+// GetInstances 解析 Compute 实例并计算生效的防火墙规则
+func (c *GCPCloud) GetInstances(firewalls []*firewall) ([]*cloud.Asset, error) {
+	records, err := c.ListByPath(PathInstance)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathInstance, err)
+	}
+
+	var instances []*instance
+	for _, record := range records {
+		payload := cloud.PayloadOf(record)
+		if cloud.String(payload, "name") == "" || strings.EqualFold(cloud.String(payload, "status"), "TERMINATED") {
+			continue
+		}
+		inst := &instance{
+			payload: payload,
+			id:      cloud.FirstString(cloud.String(payload, "selfLink"), cloud.String(payload, "id"), cloud.String(payload, "name")),
+			tags:    cloud.Strings(cloud.Map(payload, "tags"), "items"),
+		}
+		for _, sa := range cloud.Maps(payload, "serviceAccounts") {
+			inst.accounts = cloud.AppendUnique(inst.accounts, cloud.String(sa, "email"))
+		}
+		for _, nic := range cloud.Maps(payload, "networkInterfaces") {
+			if inst.network == "" {
+				inst.network = lastSegment(cloud.String(nic, "network"))
+			}
+			if ip := cloud.String(nic, "networkIP"); ip != "" {
+				inst.internalIP = cloud.AppendUnique(inst.internalIP, ip)
+			}
+		}
+		instances = append(instances, inst)
+	}
+
+	out := make([]*cloud.Asset, 0, len(instances))
+	for _, inst := range instances {
+		out = append(out, parseInstance(inst, instances, firewalls))
+	}
+	return out, nil
+}
+
+func parseInstance(inst *instance, all []*instance, firewalls []*firewall) *cloud.Asset {
+	payload := inst.payload
+	as := &cloud.Asset{
+		Provider:     ProviderName,
+		InstanceID:   inst.id,
+		InstanceName: cloud.String(payload, "name"),
+		ResourceType: "instance",
+		Region:       regionFromZone(cloud.String(payload, "zone")),
+		VpcID:        inst.network,
+		Tags:         cloud.Tags(cloud.AnyCase(payload, "labels")),
+		PrivateIPs:   inst.internalIP,
+	}
+	for _, nic := range cloud.Maps(payload, "networkInterfaces") {
+		if as.SubnetID == "" {
+			as.SubnetID = lastSegment(cloud.String(nic, "subnetwork"))
+		}
+		for _, ac := range cloud.Maps(nic, "accessConfigs") {
+			if ip := cloud.String(ac, "natIP"); ip != "" {
+				as.PublicIPs = cloud.AppendUnique(as.PublicIPs, ip)
+			}
+		}
+		for _, ac := range cloud.Maps(nic, "ipv6AccessConfigs") {
+			if ip := cloud.String(ac, "externalIpv6"); ip != "" {
+				as.IPv6s = cloud.AppendUnique(as.IPv6s, ip)
+			}
+		}
+	}
+	sort.Strings(as.PublicIPs)
+	as.Public = len(as.PublicIPs) > 0 || len(as.IPv6s) > 0
+
+	var applicable []*firewall
+	for _, fw := range firewalls {
+		if fw.appliesTo(inst) {
+			applicable = append(applicable, fw)
+			as.GroupIDs = cloud.AppendUnique(as.GroupIDs, fw.name)
+		}
+	}
+	for i, fw := range applicable {
+		if fw.deny {
+			continue
+		}
+		for _, e := range fw.entries {
+			if shadowedByDeny(e, applicable[:i]) {
+				continue
+			}
+			as.Rules = append(as.Rules, fw.rule(e, all))
+		}
+	}
+	as.Evaluate()
+	return as
+}
+
+// appliesTo 判断防火墙是否作用于实例：同一网络，且目标标签/服务账号匹配或未指定目标
+func (fw *firewall) appliesTo(inst *instance) bool {
+	if fw.network != "" && inst.network != "" && fw.network != inst.network {
+		return false
+	}
+	switch {
+	case len(fw.targetTags) > 0:
+		return intersects(fw.targetTags, inst.tags)
+	case len(fw.targetSAs) > 0:
+		return intersects(fw.targetSAs, inst.accounts)
+	default:
+		return true
+	}
+}
+
+// rule 转换为统一的规则结构，来源标签/服务账号展开为匹配实例的内网地址
+func (fw *firewall) rule(e fwEntry, all []*instance) cloud.Rule {
+	r := cloud.Rule{
+		GroupID:  fw.name,
+		FromPort: e.from,
+		ToPort:   e.to,
+		Protocol: e.protocol,
+		Iprange:  fw.sourceRanges,
+	}
+	for _, tag := range fw.sourceTags {
+		r.SourceGroups = append(r.SourceGroups, resolveSource("tag:"+tag, all, func(i *instance) bool { return contains(i.tags, tag) }))
+	}
+	for _, sa := range fw.sourceSAs {
+		r.SourceGroups = append(r.SourceGroups, resolveSource("serviceAccount:"+sa, all, func(i *instance) bool { return contains(i.accounts, sa) }))
+	}
+	r.Exposure = cloud.ClassifyCIDRs(r.Sources())
+	return r
+}
+
+func resolveSource(id string, all []*instance, match func(*instance) bool) cloud.GroupReference {
+	ref := cloud.GroupReference{GroupID: id, Members: []string{}, IPs: []string{}}
+	for _, i := range all {
+		if !match(i) {
+			continue
+		}
+		ref.Resolved = true
+		ref.Members = cloud.AppendUnique(ref.Members, i.id)
+		for _, ip := range i.internalIP {
+			ref.IPs = cloud.AppendUnique(ref.IPs, ip)
+		}
+	}
+	return ref
+}
+
+// shadowedByDeny 更高优先级的 deny 规则对任意来源拒绝了相同端口时，allow 规则不再生效
+func shadowedByDeny(e fwEntry, higher []*firewall) bool {
+	for _, fw := range higher {
+		if !fw.deny || !contains(fw.sourceRanges, cloud.ExposureIprange) {
+			continue
+		}
+		for _, d := range fw.entries {
+			if (d.protocol == "-1" || d.protocol == e.protocol) && d.from <= e.from && d.to >= e.to {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseEntries 解析 {IPProtocol, ports} 条目，未指定端口时覆盖全部端口
+func parseEntries(e map[string]interface{}) []fwEntry {
+	proto := strings.ToLower(cloud.String(e, "IPProtocol"))
+	if proto == "all" || proto == "" {
+		proto = "-1"
+	}
+	ports := cloud.Strings(e, "ports")
+	if len(ports) == 0 {
+		return []fwEntry{{protocol: proto, from: 0, to: 65535}}
+	}
+	var out []fwEntry
+	for _, p := range ports {
+		from, to, ok := parsePortRange(p)
+		if ok {
+			out = append(out, fwEntry{protocol: proto, from: from, to: to})
+		}
+	}
+	return out
+}
+
+// parsePortRange 解析 "22" 或 "8000-9000"
+func parsePortRange(s string) (int, int, bool) {
+	lo, hi, found := strings.Cut(strings.TrimSpace(s), "-")
+	from, err := strconv.Atoi(lo)
+	if err != nil {
+		return 0, 0, false
+	}
+	if !found {
+		return from, from, true
+	}
+	to, err := strconv.Atoi(hi)
+	if err != nil {
+		return 0, 0, false
+	}
+	return from, to, true
+}
+
+// lastSegment 取资源 URL 的最后一段，如 .../global/networks/default -> default
+func lastSegment(url string) string {
+	return url[strings.LastIndex(url, "/")+1:]
+}
+
+// regionFromZone 从 zone（如 us-central1-a）推出区域
+func regionFromZone(zone string) string {
+	zone = lastSegment(zone)
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return zone
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func intersects(a, b []string) bool {
+	for _, s := range a {
+		if contains(b, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package gcp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// GetForwardingRules 解析负载均衡转发规则，EXTERNAL* 方案的前端对公网开放
+func (c *GCPCloud) GetForwardingRules() ([]*cloud.Asset, error) {
+	records, err := c.ListByPath(PathForwardingRule)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathForwardingRule, err)
+	}
+	var out []*cloud.Asset
+	for _, record := range records {
+		payload := cloud.PayloadOf(record)
+		ip := cloud.String(payload, "IPAddress")
+		if ip == "" {
+			continue
+		}
+		name := cloud.String(payload, "name")
+		as := &cloud.Asset{
+			Provider:     ProviderName,
+			InstanceID:   cloud.FirstString(cloud.String(payload, "selfLink"), cloud.String(payload, "id"), name),
+			InstanceName: name,
+			ResourceType: "forwarding-rule",
+			Region:       lastSegment(cloud.String(payload, "region")),
+			VpcID:        lastSegment(cloud.String(payload, "network")),
+			SubnetID:     lastSegment(cloud.String(payload, "subnetwork")),
+			Tags:         cloud.Tags(cloud.AnyCase(payload, "labels")),
+			Public:       strings.HasPrefix(strings.ToUpper(cloud.String(payload, "loadBalancingScheme")), "EXTERNAL"),
+		}
+		if as.Region == "" {
+			as.Region = "global"
+		}
+		if as.Public {
+			as.PublicIPs = []string{ip}
+		} else {
+			as.PrivateIPs = []string{ip}
+		}
+
+		proto := strings.ToLower(cloud.String(payload, "IPProtocol"))
+		var entries []fwEntry
+		if pr := cloud.String(payload, "portRange"); pr != "" {
+			if from, to, ok := parsePortRange(pr); ok {
+				entries = append(entries, fwEntry{protocol: proto, from: from, to: to})
+			}
+		}
+		for _, p := range cloud.Strings(payload, "ports") {
+			if from, to, ok := parsePortRange(p); ok {
+				entries = append(entries, fwEntry{protocol: proto, from: from, to: to})
+			}
+		}
+		if len(entries) == 0 || cloud.Bool(payload, "allPorts") {
+			entries = []fwEntry{{protocol: proto, from: 0, to: 65535}}
+		}
+		// 外部负载均衡不受 VPC 防火墙约束，前端端口对任意来源开放
+		if as.Public {
+			for _, e := range entries {
+				as.Rules = append(as.Rules, cloud.Rule{
+					GroupID:  name,
+					FromPort: e.from,
+					ToPort:   e.to,
+					Protocol: e.protocol,
+					Iprange:  []string{cloud.ExposureIprange},
+					Exposure: cloud.ExposureInternet,
+				})
+			}
+		}
+		as.Evaluate()
+		out = append(out, as)
+	}
+	return out, nil
+}
+
+// GetReservedAddresses 返回已预留但未绑定资源的外部 IP，便于发现悬空地址
+func (c *GCPCloud) GetReservedAddresses() ([]*cloud.Asset, error) {
+	records, err := c.ListByPath(PathAddress)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathAddress, err)
+	}
+	var out []*cloud.Asset
+	for _, record := range records {
+		payload := cloud.PayloadOf(record)
+		ip := cloud.String(payload, "address")
+		if ip == "" || !strings.EqualFold(cloud.String(payload, "addressType"), "EXTERNAL") {
+			continue
+		}
+		if !strings.EqualFold(cloud.String(payload, "status"), "RESERVED") {
+			continue
+		}
+		name := cloud.String(payload, "name")
+		region := lastSegment(cloud.String(payload, "region"))
+		if region == "" {
+			region = "global"
+		}
+		out = append(out, &cloud.Asset{
+			Provider:     ProviderName,
+			InstanceID:   cloud.FirstString(cloud.String(payload, "selfLink"), cloud.String(payload, "id"), name),
+			InstanceName: name,
+			ResourceType: "address",
+			Region:       region,
+			Tags:         cloud.Tags(cloud.AnyCase(payload, "labels")),
+			PublicIPs:    []string{ip},
+			Public:       true,
+			Exposure:     cloud.ExposureNone,
+		})
+	}
+	return out, nil
+}
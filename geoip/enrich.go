@@ -0,0 +1,201 @@
+package geoip
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+const RuleUnexpectedSource = "geo-unexpected-admin-source"
+
+// 默认的管理端口：SSH、RDP、WinRM、VNC
+var defaultAdminPorts = []int{22, 3389, 5985, 5986, 5900}
+
+// Enricher 为规则来源地址段与资产公网地址标注国家与 ASN，
+// 配置了预期国家或 ASN 时，对管理端口放行到预期之外来源的规则产生问题
+type Enricher struct {
+	*Reader
+	AdminPorts        []int
+	ExpectedCountries []string
+	ExpectedASNs      []uint
+}
+
+// NewEnricherFromViper 读取 GeoIP 配置段
+//
+//	GeoIP:
+//	  country_db: /opt/geoip/GeoLite2-Country.mmdb
+//	  asn_db: /opt/geoip/GeoLite2-ASN.mmdb
+//	  admin_ports: [22, 3389]
+//	  expected_countries: [US, DE]
+//	  expected_asns: [16509, 3356]
+func NewEnricherFromViper() (*Enricher, error) {
+	r, err := Open(viper.GetString("GeoIP.country_db"), viper.GetString("GeoIP.asn_db"))
+	if err != nil {
+		return nil, err
+	}
+	e := &Enricher{Reader: r, AdminPorts: viper.GetIntSlice("GeoIP.admin_ports")}
+	if len(e.AdminPorts) == 0 {
+		e.AdminPorts = defaultAdminPorts
+	}
+	for _, c := range viper.GetStringSlice("GeoIP.expected_countries") {
+		e.ExpectedCountries = append(e.ExpectedCountries, strings.ToUpper(c))
+	}
+	for _, v := range viper.GetStringSlice("GeoIP.expected_asns") {
+		n, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(v), "AS"), 10, 32)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("invalid GeoIP.expected_asns entry %q", v)
+		}
+		e.ExpectedASNs = append(e.ExpectedASNs, uint(n))
+	}
+	return e, nil
+}
+
+// EnrichAssets 标注规则中的公网来源地址段（0.0.0.0/0 与内网地址段除外）以及资产自身的公网地址
+func (e *Enricher) EnrichAssets(assets []*cloud.Asset) {
+	var annotated int
+	for _, a := range assets {
+		a.Geo = nil
+		for _, ip := range append(append([]string{}, a.PublicIPs...), a.IPv6s...) {
+			if info := e.Lookup(ip); info != nil {
+				a.Geo = append(a.Geo, *info)
+			}
+		}
+		for i := range a.Rules {
+			r := &a.Rules[i]
+			r.Geo = nil
+			for _, src := range r.Sources() {
+				switch cloud.ClassifyCIDR(src) {
+				case cloud.ExposureRestricted, cloud.ExposureBroad:
+				default:
+					continue
+				}
+				if info := e.Lookup(src); info != nil {
+					r.Geo = append(r.Geo, *info)
+					annotated++
+				}
+			}
+		}
+	}
+	logx.Infof("geoip summary: assets=%d, annotated sources=%d", len(assets), annotated)
+}
+
+// Evaluate 管理端口放行的来源国家或 ASN 任一不在预期范围内时产生问题，未配置预期时不产生问题
+func (e *Enricher) Evaluate(assets []*cloud.Asset) []*finding.Finding {
+	if len(e.ExpectedCountries) == 0 && len(e.ExpectedASNs) == 0 {
+		return nil
+	}
+	var out []*finding.Finding
+	for _, a := range assets {
+		if !a.Public {
+			continue
+		}
+		for _, r := range a.Rules {
+			port, ok := e.adminPort(r)
+			if !ok {
+				continue
+			}
+			for _, g := range r.Geo {
+				reason := e.unexpected(g)
+				if reason == "" {
+					continue
+				}
+				target := fmt.Sprintf("%s %s/%d-%d from %s", r.GroupID, r.Protocol, r.FromPort, r.ToPort, g.Address)
+				f := finding.New(RuleUnexpectedSource, finding.SeverityHigh, a, target,
+					"Admin port open to unexpected network",
+					fmt.Sprintf("port %d is allowed from %s (%s)", port, g.Address, reason))
+				f.GroupID, f.FromPort, f.ToPort = r.GroupID, r.FromPort, r.ToPort
+				f.Sources = []string{g.Address}
+				f.Evidence["country"] = cloud.FirstString(g.Country, strings.Join(g.Countries, ","))
+				f.Evidence["asn"] = strconv.FormatUint(uint64(g.ASN), 10)
+				if len(g.ASNs) > 1 {
+					f.Evidence["asn"] = joinASNs(g.ASNs)
+				}
+				f.Evidence["org"] = g.Org
+				f.Evidence["network"] = g.Network
+				out = append(out, f)
+			}
+		}
+	}
+	return out
+}
+
+// adminPort 返回规则放行的第一个管理端口
+func (e *Enricher) adminPort(r cloud.Rule) (int, bool) {
+	if r.Exposure == cloud.ExposureNone {
+		return 0, false
+	}
+	for _, p := range e.AdminPorts {
+		if r.Covers(p) {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+// unexpected 返回来源不在预期范围内的原因，地址段跨多个国家或 ASN 时逐个判断；
+// 国家或 ASN 未知时不判断对应项，网段过多未能遍历完时同样视为不在预期内
+func (e *Enricher) unexpected(g cloud.GeoInfo) string {
+	var reasons []string
+	countries := g.Countries
+	if len(countries) == 0 && g.Country != "" {
+		countries = []string{g.Country}
+	}
+	for _, c := range countries {
+		if len(e.ExpectedCountries) > 0 && !contains(e.ExpectedCountries, c) {
+			reasons = append(reasons, "country "+c)
+		}
+	}
+	asns := g.ASNs
+	if len(asns) == 0 && g.ASN != 0 {
+		asns = []uint{g.ASN}
+	}
+	for _, n := range asns {
+		if len(e.ExpectedASNs) > 0 && !containsASN(e.ExpectedASNs, n) {
+			reasons = append(reasons, strings.TrimSpace(fmt.Sprintf("AS%d %s", n, orgOf(g, n))))
+		}
+	}
+	if g.Partial {
+		reasons = append(reasons, "spans too many networks to verify")
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// orgOf 只有单一 ASN 时记录了组织名
+func orgOf(g cloud.GeoInfo, n uint) string {
+	if g.ASN == n {
+		return g.Org
+	}
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsASN(list []uint, n uint) bool {
+	for _, v := range list {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
+func joinASNs(list []uint) string {
+	parts := make([]string, len(list))
+	for i, n := range list {
+		parts[i] = strconv.FormatUint(uint64(n), 10)
+	}
+	return strings.Join(parts, ",")
+}
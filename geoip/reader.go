@@ -0,0 +1,223 @@
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// countryRecord GeoLite2/GeoIP2 Country 与 City 库共用的国家字段
+type countryRecord struct {
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	// 部分地址（如匿名代理）只有注册国家
+	RegisteredCountry struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"registered_country"`
+}
+
+// asnRecord GeoLite2/GeoIP2 ASN 库字段
+type asnRecord struct {
+	Number uint   `maxminddb:"autonomous_system_number"`
+	Org    string `maxminddb:"autonomous_system_organization"`
+}
+
+// Reader 同时查询国家库与 ASN 库，结果按地址缓存
+type Reader struct {
+	country *maxminddb.Reader
+	asn     *maxminddb.Reader
+
+	mu    sync.Mutex
+	cache map[string]*cloud.GeoInfo
+}
+
+// Open 打开 MaxMind 格式的国家库（Country 或 City）与 ASN 库，路径为空的库跳过，至少需要一个
+func Open(countryPath, asnPath string) (*Reader, error) {
+	if countryPath == "" && asnPath == "" {
+		return nil, errors.New("geoip requires a country or asn database")
+	}
+	r := &Reader{cache: map[string]*cloud.GeoInfo{}}
+	var err error
+	if countryPath != "" {
+		if r.country, err = maxminddb.Open(countryPath); err != nil {
+			return nil, fmt.Errorf("open %s: %w", countryPath, err)
+		}
+	}
+	if asnPath != "" {
+		if r.asn, err = maxminddb.Open(asnPath); err != nil {
+			r.Close()
+			return nil, fmt.Errorf("open %s: %w", asnPath, err)
+		}
+	}
+	return r, nil
+}
+
+func (r *Reader) Close() error {
+	var errs []error
+	for _, db := range []*maxminddb.Reader{r.country, r.asn} {
+		if db != nil {
+			errs = append(errs, db.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Lookup 查询单个地址或地址段，非公网地址或两个库都未命中时返回 nil
+func (r *Reader) Lookup(address string) *cloud.GeoInfo {
+	address = strings.TrimSpace(address)
+	r.mu.Lock()
+	info, ok := r.cache[address]
+	r.mu.Unlock()
+	if ok {
+		return info
+	}
+	info = r.lookup(address)
+	r.mu.Lock()
+	r.cache[address] = info
+	r.mu.Unlock()
+	return info
+}
+
+func (r *Reader) lookup(address string) *cloud.GeoInfo {
+	prefix, ok := parsePrefix(address)
+	if !ok {
+		return nil
+	}
+	addr := prefix.Addr()
+	if prefix.IsSingleIP() && cloud.ClassOf(addr) != cloud.AddressPublic {
+		return nil
+	}
+	if !prefix.IsSingleIP() && cloud.ClassifyCIDR(prefix.String()) == cloud.ExposurePrivate {
+		return nil
+	}
+	info := &cloud.GeoInfo{Address: address}
+	var found bool
+	if r.country != nil {
+		res := r.country.Lookup(addr)
+		var rec countryRecord
+		switch {
+		case res.Prefix().Bits() > prefix.Bits():
+			found = r.countriesWithin(prefix, info) || found
+		case res.Found() && res.Decode(&rec) == nil:
+			found = true
+			info.Network = res.Prefix().String()
+			info.Country, info.CountryName = rec.code()
+			if info.Country != "" {
+				info.Countries = []string{info.Country}
+			}
+		}
+	}
+	if r.asn != nil {
+		res := r.asn.Lookup(addr)
+		var rec asnRecord
+		switch {
+		case res.Prefix().Bits() > prefix.Bits():
+			found = r.asnsWithin(prefix, info) || found
+		case res.Found() && res.Decode(&rec) == nil:
+			found = true
+			info.ASN, info.Org = rec.Number, rec.Org
+			info.Network = cloud.FirstString(info.Network, res.Prefix().String())
+			if info.ASN != 0 {
+				info.ASNs = []uint{info.ASN}
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return info
+}
+
+// maxNetworks 地址段内最多遍历的 MMDB 网段数，超过后标记为部分结果
+const maxNetworks = 4096
+
+// countriesWithin 查询的地址段大于 MMDB 网段时汇总其中全部网段的国家，唯一时才填写 Country
+func (r *Reader) countriesWithin(prefix netip.Prefix, info *cloud.GeoInfo) bool {
+	names := map[string]string{}
+	n := 0
+	for res := range r.country.NetworksWithin(prefix) {
+		if n++; n > maxNetworks {
+			info.Partial = true
+			break
+		}
+		var rec countryRecord
+		if res.Decode(&rec) != nil {
+			continue
+		}
+		if code, name := rec.code(); code != "" {
+			info.Countries = cloud.AppendUnique(info.Countries, code)
+			names[code] = name
+		}
+	}
+	sort.Strings(info.Countries)
+	if len(info.Countries) == 1 && !info.Partial {
+		info.Country, info.CountryName = info.Countries[0], names[info.Countries[0]]
+	}
+	info.Network = prefix.String()
+	return n > 0
+}
+
+// asnsWithin 同 countriesWithin，唯一时才填写 ASN 与组织名
+func (r *Reader) asnsWithin(prefix netip.Prefix, info *cloud.GeoInfo) bool {
+	orgs := map[uint]string{}
+	n := 0
+	for res := range r.asn.NetworksWithin(prefix) {
+		if n++; n > maxNetworks {
+			info.Partial = true
+			break
+		}
+		var rec asnRecord
+		if res.Decode(&rec) != nil || rec.Number == 0 {
+			continue
+		}
+		if !slices.Contains(info.ASNs, rec.Number) {
+			info.ASNs = append(info.ASNs, rec.Number)
+			orgs[rec.Number] = rec.Org
+		}
+	}
+	slices.Sort(info.ASNs)
+	if len(info.ASNs) == 1 && !info.Partial {
+		info.ASN, info.Org = info.ASNs[0], orgs[info.ASNs[0]]
+	}
+	info.Network = prefix.String()
+	return n > 0
+}
+
+// code 国家代码与英文名，没有国家时取注册国家
+func (rec countryRecord) code() (string, string) {
+	if rec.Country.ISOCode != "" {
+		return rec.Country.ISOCode, rec.Country.Names["en"]
+	}
+	return rec.RegisteredCountry.ISOCode, rec.RegisteredCountry.Names["en"]
+}
+
+// parsePrefix 解析地址段，单个地址按 /32 或 /128 处理
+func parsePrefix(s string) (netip.Prefix, bool) {
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, false
+		}
+		p = p.Masked()
+		if p.Addr().Is4In6() && p.Bits() >= 96 {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+		return p, true
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), true
+}
@@ -0,0 +1,170 @@
+package ipranges
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+const (
+	RuleOutsideExpected = "ip-outside-expected-ranges"
+	RuleNonPublic       = "ip-non-public-address"
+)
+
+// File IPRanges.files 中的一项，format 为空时按 provider 推断
+type File struct {
+	Provider string `mapstructure:"provider"`
+	Format   string `mapstructure:"format"`
+	Path     string `mapstructure:"path"`
+}
+
+// Classifier 对资产公网地址分类并匹配云厂商地址段。
+// 地址落在 ExpectedCIDRs、资产所属厂商或 ExpectedProviders 的地址段内时视为预期
+type Classifier struct {
+	Table             *Table
+	ExpectedCIDRs     []netip.Prefix
+	ExpectedProviders []string
+}
+
+// NewClassifierFromViper 读取 IPRanges 配置段，地址段文件需预先下载到本地
+//
+//	IPRanges:
+//	  files:
+//	    - {provider: aws, path: /opt/ipranges/ip-ranges.json}
+//	    - {provider: gcp, path: /opt/ipranges/cloud.json}
+//	    - {provider: azure, path: /opt/ipranges/ServiceTags_Public.json}
+//	    - {provider: cloudflare, format: cidr, path: /opt/ipranges/ips-v4}
+//	  expected_providers: [cloudflare]
+//	  expected_cidrs: [198.51.100.0/24]
+func NewClassifierFromViper() (*Classifier, error) {
+	var files []File
+	if err := viper.UnmarshalKey("IPRanges.files", &files); err != nil {
+		return nil, fmt.Errorf("parse IPRanges.files: %w", err)
+	}
+	c := &Classifier{Table: NewTable(), ExpectedProviders: viper.GetStringSlice("IPRanges.expected_providers")}
+	for _, f := range files {
+		format := f.Format
+		if format == "" {
+			format = FormatOf(f.Provider)
+		}
+		ranges, err := LoadFile(f.Path, format, strings.ToLower(f.Provider))
+		if err != nil {
+			return nil, err
+		}
+		logx.Infof("loaded %d %s ip ranges from %s", len(ranges), f.Provider, f.Path)
+		c.Table.Add(ranges...)
+	}
+	c.Table.Sort()
+	for _, s := range viper.GetStringSlice("IPRanges.expected_cidrs") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IPRanges.expected_cidrs entry %q", s)
+		}
+		c.ExpectedCIDRs = append(c.ExpectedCIDRs, p.Masked())
+	}
+	return c, nil
+}
+
+// Classify 对单个地址分类并匹配地址段
+func (c *Classifier) Classify(ip, provider string) cloud.AddressInfo {
+	info := cloud.AddressInfo{IP: ip, Class: cloud.AddressInvalid}
+	addr, ok := cloud.ParseAddress(ip)
+	if !ok {
+		return info
+	}
+	info.IP = addr.String()
+	info.Class = cloud.ClassOf(addr)
+	if info.Class != cloud.AddressPublic {
+		return info
+	}
+	r, found := c.Table.Lookup(addr)
+	if found {
+		info.Provider, info.Service, info.Region, info.Prefix = r.Provider, r.Service, r.Region, r.Prefix.String()
+	}
+	info.Expected = c.expected(addr, provider, r, found)
+	return info
+}
+
+// expected 未加载资产所属厂商的地址段且未配置 expected_cidrs 时无从判断，视为预期
+func (c *Classifier) expected(addr netip.Addr, provider string, r Range, found bool) bool {
+	for _, p := range c.ExpectedCIDRs {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	if found && (strings.EqualFold(r.Provider, provider) || containsFold(c.ExpectedProviders, r.Provider)) {
+		return true
+	}
+	return !c.Table.Has(provider) && len(c.ExpectedCIDRs) == 0
+}
+
+// ClassifyAssets 为资产的公网地址填充 Addresses
+func (c *Classifier) ClassifyAssets(assets []*cloud.Asset) {
+	var total, unexpected int
+	for _, a := range assets {
+		a.Addresses = nil
+		for _, ip := range a.PublicIPs {
+			a.Addresses = append(a.Addresses, c.Classify(ip, a.Provider))
+		}
+		for _, ip := range a.IPv6s {
+			// IPv6 字段同时包含内网地址（如 ULA），只记录公网地址
+			if info := c.Classify(ip, a.Provider); info.Class == cloud.AddressPublic {
+				a.Addresses = append(a.Addresses, info)
+			}
+		}
+		for _, info := range a.Addresses {
+			total++
+			if info.Class == cloud.AddressPublic && !info.Expected {
+				unexpected++
+			}
+		}
+	}
+	logx.Infof("ip range summary: ranges=%d, addresses=%d, unexpected=%d", c.Table.Len(), total, unexpected)
+}
+
+// Evaluate 公网地址字段中出现非公网地址，或公网地址不在预期地址段内时产生问题
+func (c *Classifier) Evaluate(assets []*cloud.Asset) []*finding.Finding {
+	var out []*finding.Finding
+	for _, a := range assets {
+		for _, info := range a.Addresses {
+			var f *finding.Finding
+			switch {
+			case info.Class != cloud.AddressPublic:
+				f = finding.New(RuleNonPublic, finding.SeverityLow, a, info.IP,
+					"Non-public address reported as public IP",
+					fmt.Sprintf("%s is classified as %s", info.IP, info.Class))
+			case !info.Expected:
+				owner := "no known provider range"
+				if info.Provider != "" {
+					owner = fmt.Sprintf("%s range %s", info.Provider, info.Prefix)
+				}
+				f = finding.New(RuleOutsideExpected, finding.SeverityMedium, a, info.IP,
+					"Public IP outside expected ranges",
+					fmt.Sprintf("%s belongs to %s, expected %s ranges", info.IP, owner, a.Provider))
+			default:
+				continue
+			}
+			f.Evidence["class"] = string(info.Class)
+			f.Evidence["provider"] = info.Provider
+			f.Evidence["service"] = info.Service
+			f.Evidence["region"] = info.Region
+			f.Evidence["prefix"] = info.Prefix
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package ipranges
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+// 地址段文件格式
+const (
+	FormatAWS   = "aws"   // https://ip-ranges.amazonaws.com/ip-ranges.json
+	FormatGCP   = "gcp"   // https://www.gstatic.com/ipranges/cloud.json
+	FormatAzure = "azure" // ServiceTags_Public_*.json
+	FormatCIDR  = "cidr"  // 每行一个 CIDR，如 https://www.cloudflare.com/ips-v4
+)
+
+// FormatOf 未指定格式时按厂商名推断，其余厂商按 CIDR 列表解析
+func FormatOf(provider string) string {
+	switch strings.ToLower(provider) {
+	case FormatAWS, FormatGCP, FormatAzure:
+		return strings.ToLower(provider)
+	default:
+		return FormatCIDR
+	}
+}
+
+// LoadFile 解析本地缓存的地址段文件
+func LoadFile(path, format, provider string) ([]Range, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ranges []Range
+	switch format {
+	case FormatAWS:
+		ranges, err = parseAWS(data, provider)
+	case FormatGCP:
+		ranges, err = parseGCP(data, provider)
+	case FormatAzure:
+		ranges, err = parseAzure(data, provider)
+	case FormatCIDR:
+		ranges, err = parseCIDR(data, provider)
+	default:
+		return nil, fmt.Errorf("unknown ip range format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return ranges, nil
+}
+
+// parseAWS 同一地址段会以 AMAZON 与具体服务（EC2、CLOUDFRONT 等）各出现一次，保留具体服务
+func parseAWS(data []byte, provider string) ([]Range, error) {
+	var doc struct {
+		Prefixes []struct {
+			IPPrefix string `json:"ip_prefix"`
+			Region   string `json:"region"`
+			Service  string `json:"service"`
+		} `json:"prefixes"`
+		IPv6Prefixes []struct {
+			IPv6Prefix string `json:"ipv6_prefix"`
+			Region     string `json:"region"`
+			Service    string `json:"service"`
+		} `json:"ipv6_prefixes"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	byPrefix := map[netip.Prefix]int{}
+	var out []Range
+	add := func(cidr, region, service string) {
+		p, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return
+		}
+		if i, ok := byPrefix[p]; ok {
+			if out[i].Service == "AMAZON" && service != "AMAZON" {
+				out[i].Service = service
+			}
+			return
+		}
+		byPrefix[p] = len(out)
+		out = append(out, Range{Prefix: p, Provider: provider, Service: service, Region: region})
+	}
+	for _, p := range doc.Prefixes {
+		add(p.IPPrefix, p.Region, p.Service)
+	}
+	for _, p := range doc.IPv6Prefixes {
+		add(p.IPv6Prefix, p.Region, p.Service)
+	}
+	return out, nil
+}
+
+func parseGCP(data []byte, provider string) ([]Range, error) {
+	var doc struct {
+		Prefixes []struct {
+			IPv4Prefix string `json:"ipv4Prefix"`
+			IPv6Prefix string `json:"ipv6Prefix"`
+			Service    string `json:"service"`
+			Scope      string `json:"scope"`
+		} `json:"prefixes"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	var out []Range
+	for _, p := range doc.Prefixes {
+		prefix, err := netip.ParsePrefix(p.IPv4Prefix + p.IPv6Prefix)
+		if err != nil {
+			continue
+		}
+		out = append(out, Range{Prefix: prefix, Provider: provider, Service: p.Service, Region: p.Scope})
+	}
+	return out, nil
+}
+
+// parseAzure 服务标签中 AzureCloud.<region> 只有区域没有服务名，具体服务的标签带 systemService
+func parseAzure(data []byte, provider string) ([]Range, error) {
+	var doc struct {
+		Values []struct {
+			Name       string `json:"name"`
+			Properties struct {
+				Region          string   `json:"region"`
+				SystemService   string   `json:"systemService"`
+				AddressPrefixes []string `json:"addressPrefixes"`
+			} `json:"properties"`
+		} `json:"values"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	var out []Range
+	for _, v := range doc.Values {
+		for _, cidr := range v.Properties.AddressPrefixes {
+			prefix, err := netip.ParsePrefix(cidr)
+			if err != nil {
+				continue
+			}
+			out = append(out, Range{Prefix: prefix, Provider: provider, Service: v.Properties.SystemService, Region: v.Properties.Region})
+		}
+	}
+	return out, nil
+}
+
+// parseCIDR 忽略空行与 # 注释，单个地址按 /32 或 /128 处理
+func parseCIDR(data []byte, provider string) ([]Range, error) {
+	var out []Range
+	sc := bufio.NewScanner(strings.NewReader(string(data)))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(line)
+		if err != nil {
+			addr, aerr := netip.ParseAddr(line)
+			if aerr != nil {
+				return nil, fmt.Errorf("line %d: invalid cidr %q", n, line)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		out = append(out, Range{Prefix: prefix, Provider: provider})
+	}
+	return out, sc.Err()
+}
@@ -0,0 +1,62 @@
+package ipranges
+
+import (
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// Range 云厂商公布的地址段
+type Range struct {
+	Prefix   netip.Prefix
+	Provider string
+	Service  string
+	Region   string
+}
+
+// Table 按最长前缀匹配查询地址所属的地址段
+type Table struct {
+	ranges    []Range
+	providers map[string]int
+}
+
+func NewTable() *Table {
+	return &Table{providers: map[string]int{}}
+}
+
+// Add 添加地址段，添加完成后需调用 Sort
+func (t *Table) Add(ranges ...Range) {
+	for _, r := range ranges {
+		r.Prefix = r.Prefix.Masked()
+		t.ranges = append(t.ranges, r)
+		t.providers[strings.ToLower(r.Provider)]++
+	}
+}
+
+// Sort 掩码长的在前；掩码相同时带服务名的在前，优先命中更具体的记录
+func (t *Table) Sort() {
+	sort.SliceStable(t.ranges, func(i, j int) bool {
+		a, b := t.ranges[i], t.ranges[j]
+		if a.Prefix.Bits() != b.Prefix.Bits() {
+			return a.Prefix.Bits() > b.Prefix.Bits()
+		}
+		return a.Service != "" && b.Service == ""
+	})
+}
+
+// Lookup 返回包含该地址的最具体的地址段
+func (t *Table) Lookup(addr netip.Addr) (Range, bool) {
+	for _, r := range t.ranges {
+		if r.Prefix.Contains(addr) {
+			return r, true
+		}
+	}
+	return Range{}, false
+}
+
+// Has 是否加载了该厂商的地址段
+func (t *Table) Has(provider string) bool {
+	return t.providers[strings.ToLower(provider)] > 0
+}
+
+func (t *Table) Len() int { return len(t.ranges) }
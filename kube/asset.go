@@ -0,0 +1,92 @@
+package kube
+
+import (
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/xidp/protocols"
+)
+
+// ProviderName 集群暴露对象在资产清单中的厂商标识
+const ProviderName = "kubernetes"
+
+// Asset 转为统一的资产记录，Account 为集群 context，命名空间与集群写入标签供归属与策略使用。
+// 每个端口生成一条规则：负载均衡端口按负载均衡的暴露程度，NodePort 按节点防火墙计算的暴露程度
+func (s *Surface) Asset() *cloud.Asset {
+	as := &cloud.Asset{
+		Provider:     ProviderName,
+		Account:      s.Cluster,
+		InstanceID:   s.ID(),
+		InstanceName: s.Namespace + "/" + s.Name,
+		ResourceType: strings.ToLower(s.Kind),
+		Tags:         map[string]string{"kubernetes.io/cluster": s.Cluster, "kubernetes.io/namespace": s.Namespace},
+		Public:       s.Exposure != cloud.ExposureNone && s.Exposure != cloud.ExposurePrivate,
+	}
+	if len(s.Hosts) > 0 {
+		as.Endpoint = s.Hosts[0]
+	}
+	for _, address := range s.Addresses {
+		if addr, ok := cloud.ParseAddress(address); !ok {
+			as.Endpoint = cloud.FirstString(as.Endpoint, address)
+		} else if cloud.ClassOf(addr) == cloud.AddressPublic {
+			as.PublicIPs = cloud.AppendUnique(as.PublicIPs, addr.String())
+		} else {
+			as.PrivateIPs = cloud.AppendUnique(as.PrivateIPs, addr.String())
+		}
+	}
+
+	var sources []string
+	switch {
+	case s.lbExposure == cloud.ExposureNone || s.lbExposure == cloud.ExposurePrivate:
+	case len(s.SourceRanges) > 0:
+		sources = s.SourceRanges
+	default:
+		sources = []string{cloud.ExposureIprange}
+	}
+	// 节点关联只记录暴露程度，完全开放时才能确定来源为 0.0.0.0/0
+	nodeExposure := cloud.ExposureNone
+	for _, l := range s.LinkedAssets {
+		if l.Match == MatchNode {
+			nodeExposure = cloud.MaxExposure(nodeExposure, l.Exposure)
+		}
+	}
+	var nodeSources []string
+	if nodeExposure == cloud.ExposureInternet {
+		nodeSources = []string{cloud.ExposureIprange}
+	}
+	// NodePort 类型 Service 的 port 只在集群内可达
+	clusterOnly := s.Kind == KindService && s.Type == "NodePort"
+	for _, p := range s.Ports {
+		if p.Port > 0 && !clusterOnly && s.lbExposure != cloud.ExposureNone {
+			as.Rules = append(as.Rules, cloud.Rule{
+				FromPort: p.Port,
+				ToPort:   p.Port,
+				Protocol: p.Protocol,
+				Iprange:  sources,
+				Exposure: s.lbExposure,
+			})
+		}
+		if p.NodePort > 0 && nodeExposure != cloud.ExposureNone {
+			as.Rules = append(as.Rules, cloud.Rule{
+				FromPort: p.NodePort,
+				ToPort:   p.NodePort,
+				Protocol: p.Protocol,
+				Iprange:  nodeSources,
+				Exposure: nodeExposure,
+			})
+		}
+	}
+	as.Evaluate()
+	return as
+}
+
+// AssetsOf 从集群攻击面 XID 中取回暴露对象并转为资产记录，合并到云资产清单
+func AssetsOf(xids []*protocols.XID) []*cloud.Asset {
+	var out []*cloud.Asset
+	for _, x := range xids {
+		if s, ok := x.Payload.(*Surface); ok {
+			out = append(out, s.Asset())
+		}
+	}
+	return out
+}
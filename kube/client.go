@@ -0,0 +1,74 @@
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Client 只读的 Kubernetes REST 客户端
+type Client struct {
+	// Cluster kubeconfig 中的 context 名称，作为集群标识输出
+	Cluster    string
+	Server     string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// errNotFound 资源类型未安装（如 Gateway API CRD）
+var errNotFound = fmt.Errorf("resource not found")
+
+// List 分页拉取集合资源的全部 items
+func (c *Client) List(path string) ([]map[string]interface{}, error) {
+	var out []map[string]interface{}
+	cont := ""
+	for {
+		u := c.Server + path + "?limit=500"
+		if cont != "" {
+			u += "&continue=" + url.QueryEscape(cont)
+		}
+		var page struct {
+			Items    []map[string]interface{} `json:"items"`
+			Metadata struct {
+				Continue string `json:"continue"`
+			} `json:"metadata"`
+		}
+		if err := c.get(u, &page); err != nil {
+			return nil, err
+		}
+		out = append(out, page.Items...)
+		if page.Metadata.Continue == "" {
+			return out, nil
+		}
+		cont = page.Metadata.Continue
+	}
+}
+
+func (c *Client) get(u string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("get %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("get %s: %w", u, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get %s: http %d: %s", u, resp.StatusCode, body)
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,408 @@
+package kube
+
+import (
+	"errors"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/xidp/protocols"
+)
+
+const PathKubeAttackSurface = "/protocols/external-attack-surface/kubernetes"
+
+// 暴露对象类型
+const (
+	KindService   = "Service"
+	KindIngress   = "Ingress"
+	KindHTTPRoute = "HTTPRoute"
+)
+
+// 与云资产关联的方式
+const (
+	MatchLoadBalancer = "load-balancer"
+	MatchNode         = "node"
+)
+
+// 声明内网负载均衡的注解，值为空表示只要存在即视为内网
+var internalAnnotations = map[string]string{
+	"service.beta.kubernetes.io/aws-load-balancer-internal":                 "",
+	"service.beta.kubernetes.io/aws-load-balancer-scheme":                   "internal",
+	"networking.gke.io/load-balancer-type":                                  "internal",
+	"cloud.google.com/load-balancer-type":                                   "internal",
+	"service.beta.kubernetes.io/azure-load-balancer-internal":               "true",
+	"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-address-type":    "intranet",
+	"service.kubernetes.io/qcloud-loadbalancer-internal-subnetid":           "",
+	"service.beta.kubernetes.io/oci-load-balancer-internal":                 "true",
+	"service.beta.kubernetes.io/openstack-internal-load-balancer":           "true",
+	"service.beta.kubernetes.io/huaweicloud-load-balancer-internal-address": "",
+}
+
+type Port struct {
+	Name     string `json:"name,omitempty"`
+	Port     int    `json:"port"`
+	NodePort int    `json:"nodePort,omitempty"`
+	Protocol string `json:"protocol"`
+}
+
+// AssetLink 关联到的云资产（负载均衡或节点实例）
+type AssetLink struct {
+	Provider     string         `json:"provider"`
+	InstanceID   string         `json:"instanceId"`
+	ResourceType string         `json:"resourceType"`
+	Match        string         `json:"match"`
+	Exposure     cloud.Exposure `json:"exposure"`
+}
+
+// path /protocols/external-attack-surface/kubernetes
+type Surface struct {
+	Cluster      string         `json:"cluster"`
+	Kind         string         `json:"kind"`
+	Namespace    string         `json:"namespace"`
+	Name         string         `json:"name"`
+	Type         string         `json:"type,omitempty"`
+	Hosts        []string       `json:"hosts,omitempty"`
+	Paths        []string       `json:"paths,omitempty"`
+	TLSHosts     []string       `json:"tlsHosts,omitempty"`
+	Ports        []Port         `json:"ports"`
+	Addresses    []string       `json:"addresses"`
+	SourceRanges []string       `json:"sourceRanges,omitempty"`
+	Internal     bool           `json:"internal"`
+	LinkedAssets []AssetLink    `json:"linkedAssets"`
+	Exposure     cloud.Exposure `json:"exposure"`
+
+	// lbExposure 仅经负载均衡的暴露程度，不含 NodePort
+	lbExposure cloud.Exposure
+}
+
+// ID 集群内唯一标识 cluster/kind/namespace/name
+func (s *Surface) ID() string {
+	return strings.Join([]string{s.Cluster, s.Kind, s.Namespace, s.Name}, "/")
+}
+
+type node struct {
+	name        string
+	externalIPs []string
+	asset       *cloud.Asset
+}
+
+// inventory 云资产索引，按 IP、域名与实例 ID 查找
+type inventory struct {
+	byIP   map[string]*cloud.Asset
+	byHost map[string]*cloud.Asset
+	byID   map[string]*cloud.Asset
+}
+
+func newInventory(assets []*cloud.Asset) *inventory {
+	inv := &inventory{
+		byIP:   map[string]*cloud.Asset{},
+		byHost: map[string]*cloud.Asset{},
+		byID:   map[string]*cloud.Asset{},
+	}
+	for _, a := range assets {
+		for _, ip := range append(append([]string{}, a.PublicIPs...), a.PrivateIPs...) {
+			inv.byIP[ip] = a
+		}
+		if a.Endpoint != "" {
+			inv.byHost[strings.ToLower(a.Endpoint)] = a
+		}
+		id := strings.ToLower(a.InstanceID)
+		inv.byID[id] = a
+		inv.byID[id[strings.LastIndex(id, "/")+1:]] = a
+	}
+	return inv
+}
+
+// lookup 按负载均衡地址（IP 或域名）查找云资产
+func (inv *inventory) lookup(address string) *cloud.Asset {
+	if a, ok := inv.byIP[address]; ok {
+		return a
+	}
+	return inv.byHost[strings.ToLower(address)]
+}
+
+// lookupNode 按 providerID（aws:///az/i-xxx、gce://project/zone/name、azure:///subscriptions/...）查找节点实例
+func (inv *inventory) lookupNode(providerID string, ips []string) *cloud.Asset {
+	if providerID != "" {
+		id := strings.ToLower(providerID)
+		if i := strings.Index(id, "://"); i >= 0 {
+			id = id[i+3:]
+		}
+		if a, ok := inv.byID[id]; ok {
+			return a
+		}
+		if a, ok := inv.byID[id[strings.LastIndex(id, "/")+1:]]; ok {
+			return a
+		}
+	}
+	for _, ip := range ips {
+		if a, ok := inv.byIP[ip]; ok {
+			return a
+		}
+	}
+	return nil
+}
+
+type Collector struct {
+	client *Client
+}
+
+func NewCollector(client *Client) *Collector {
+	return &Collector{client: client}
+}
+
+// CollectFromConfig 按 Kubernetes 配置段遍历集群，contexts 为空时只采集 current-context
+func CollectFromConfig(assets []*cloud.Asset) ([]*protocols.XID, error) {
+	contexts := viper.GetStringSlice("Kubernetes.contexts")
+	if len(contexts) == 0 {
+		contexts = []string{""}
+	}
+	var out []*protocols.XID
+	for _, ctx := range contexts {
+		client, err := LoadKubeconfig(viper.GetString("Kubernetes.kubeconfig"), ctx)
+		if err != nil {
+			return nil, err
+		}
+		items, err := NewCollector(client).Collect(assets)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, SurfaceXIDs(items)...)
+	}
+	return out, nil
+}
+
+// Collect 采集 Service、Ingress 与 Gateway API 路由，并关联已有云资产
+func (c *Collector) Collect(assets []*cloud.Asset) ([]*Surface, error) {
+	inv := newInventory(assets)
+	nodes, err := c.nodes(inv)
+	if err != nil {
+		return nil, err
+	}
+	services, err := c.services(inv, nodes)
+	if err != nil {
+		return nil, err
+	}
+	ingresses, err := c.ingresses(inv)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := c.httpRoutes(inv)
+	if err != nil {
+		return nil, err
+	}
+
+	out := append(services, ingresses...)
+	out = append(out, routes...)
+	var exposed int
+	for _, s := range out {
+		if cloud.IsWideExposure(s.Exposure) {
+			exposed++
+		}
+	}
+	logx.Infof("kubernetes attack surface summary: cluster=%s, nodes=%d, services=%d, ingresses=%d, routes=%d, exposed=%d",
+		c.client.Cluster, len(nodes), len(services), len(ingresses), len(routes), exposed)
+	return out, nil
+}
+
+// SurfaceXIDs 将集群暴露对象封装为攻击面 XID
+func SurfaceXIDs(items []*Surface) []*protocols.XID {
+	out := make([]*protocols.XID, 0, len(items))
+	for _, s := range items {
+		out = append(out, cloud.NewAttackSurfaceXID(s.ID(), "k8s-"+strings.ToLower(s.Kind), PathKubeAttackSurface, s))
+	}
+	return out
+}
+
+func (c *Collector) nodes(inv *inventory) ([]*node, error) {
+	items, err := c.client.List("/api/v1/nodes")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*node, 0, len(items))
+	for _, item := range items {
+		n := &node{name: cloud.String(cloud.Map(item, "metadata"), "name")}
+		var ips []string
+		for _, addr := range cloud.Maps(cloud.Map(item, "status"), "addresses") {
+			ip := cloud.String(addr, "address")
+			switch cloud.String(addr, "type") {
+			case "ExternalIP":
+				n.externalIPs = cloud.AppendUnique(n.externalIPs, ip)
+				ips = append(ips, ip)
+			case "InternalIP":
+				ips = append(ips, ip)
+			}
+		}
+		n.asset = inv.lookupNode(cloud.String(cloud.Map(item, "spec"), "providerID"), ips)
+		if n.asset != nil {
+			// 节点自身未上报外部地址时使用云资产的公网 IP
+			for _, ip := range n.asset.PublicIPs {
+				n.externalIPs = cloud.AppendUnique(n.externalIPs, ip)
+			}
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func (c *Collector) services(inv *inventory, nodes []*node) ([]*Surface, error) {
+	items, err := c.client.List("/api/v1/services")
+	if err != nil {
+		return nil, err
+	}
+	var out []*Surface
+	for _, item := range items {
+		spec := cloud.Map(item, "spec")
+		svcType := cloud.String(spec, "type")
+		if svcType != "LoadBalancer" && svcType != "NodePort" {
+			continue
+		}
+		meta := cloud.Map(item, "metadata")
+		s := &Surface{
+			Cluster:      c.client.Cluster,
+			Kind:         KindService,
+			Namespace:    cloud.String(meta, "namespace"),
+			Name:         cloud.String(meta, "name"),
+			Type:         svcType,
+			Addresses:    []string{},
+			LinkedAssets: []AssetLink{},
+			Exposure:     cloud.ExposureNone,
+		}
+		for _, p := range cloud.Maps(spec, "ports") {
+			port := Port{Name: cloud.String(p, "name"), Protocol: strings.ToLower(cloud.FirstString(cloud.String(p, "protocol"), "TCP"))}
+			port.Port, _ = cloud.Int(p, "port")
+			port.NodePort, _ = cloud.Int(p, "nodePort")
+			s.Ports = append(s.Ports, port)
+		}
+
+		if svcType == "LoadBalancer" {
+			s.SourceRanges = cloud.Strings(spec, "loadBalancerSourceRanges")
+			s.Internal = isInternal(cloud.Map(meta, "annotations"))
+			s.linkLoadBalancer(inv, cloud.Maps(cloud.Map(cloud.Map(item, "status"), "loadBalancer"), "ingress"))
+		}
+		// LoadBalancer 类型同样分配 NodePort，节点有公网地址时可绕过负载均衡直接访问
+		for _, n := range nodes {
+			s.linkNode(n)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// linkLoadBalancer 记录负载均衡地址并关联云资产，按注解、来源限制与地址类型判断暴露程度
+func (s *Surface) linkLoadBalancer(inv *inventory, ingress []map[string]interface{}) {
+	var public bool
+	for _, lb := range ingress {
+		address := cloud.FirstString(cloud.String(lb, "ip"), cloud.String(lb, "hostname"))
+		if address == "" {
+			continue
+		}
+		s.Addresses = cloud.AppendUnique(s.Addresses, address)
+		if !isPrivateAddress(address) {
+			public = true
+		}
+		if a := inv.lookup(address); a != nil {
+			s.LinkedAssets = append(s.LinkedAssets, AssetLink{
+				Provider:     a.Provider,
+				InstanceID:   a.InstanceID,
+				ResourceType: a.ResourceType,
+				Match:        MatchLoadBalancer,
+				Exposure:     a.Exposure,
+			})
+			if !a.Public {
+				s.Internal = true
+			}
+		}
+	}
+	exposure := cloud.ExposureNone
+	switch {
+	case !public || s.Internal:
+		if len(s.Addresses) > 0 {
+			exposure = cloud.ExposurePrivate
+		}
+	case len(s.SourceRanges) > 0:
+		exposure = cloud.ClassifyCIDRs(s.SourceRanges)
+	default:
+		exposure = cloud.ExposureInternet
+	}
+	s.lbExposure = cloud.MaxExposure(s.lbExposure, exposure)
+	s.Exposure = cloud.MaxExposure(s.Exposure, exposure)
+}
+
+// linkNode 判断 NodePort 是否经节点公网地址暴露，已知节点实例时按其防火墙规则计算
+func (s *Surface) linkNode(n *node) {
+	if len(n.externalIPs) == 0 {
+		return
+	}
+	exposure := cloud.ExposureNone
+	for _, p := range s.Ports {
+		if p.NodePort == 0 {
+			continue
+		}
+		if n.asset == nil {
+			// 缺少防火墙信息时按可从公网访问处理
+			exposure = cloud.ExposureInternet
+			break
+		}
+		for _, r := range n.asset.Rules {
+			if (r.Protocol == "-1" || r.Protocol == p.Protocol) && r.Covers(p.NodePort) {
+				exposure = cloud.MaxExposure(exposure, r.Exposure)
+			}
+		}
+	}
+	if exposure == cloud.ExposureNone {
+		return
+	}
+	for _, ip := range n.externalIPs {
+		s.Addresses = cloud.AppendUnique(s.Addresses, ip)
+	}
+	if n.asset != nil {
+		s.LinkedAssets = append(s.LinkedAssets, AssetLink{
+			Provider:     n.asset.Provider,
+			InstanceID:   n.asset.InstanceID,
+			ResourceType: n.asset.ResourceType,
+			Match:        MatchNode,
+			Exposure:     exposure,
+		})
+	}
+	s.Exposure = cloud.MaxExposure(s.Exposure, exposure)
+}
+
+func isInternal(annotations map[string]interface{}) bool {
+	for key, want := range internalAnnotations {
+		v, ok := annotations[key]
+		if !ok {
+			continue
+		}
+		s, _ := v.(string)
+		if want == "" || strings.EqualFold(s, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPrivateAddress 地址为内网 IP 时返回 true，域名视为公网
+func isPrivateAddress(address string) bool {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return false
+	}
+	return cloud.ClassifyCIDR(addr.String()) == cloud.ExposurePrivate
+}
+
+// optional 可选资源（如未安装的 CRD）返回 404 时按空处理
+func optional(items []map[string]interface{}, err error) ([]map[string]interface{}, error) {
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	}
+	return items, err
+}
+
+func sortedStrings(in []string) []string {
+	sort.Strings(in)
+	return in
+}
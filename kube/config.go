@@ -0,0 +1,229 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kubeconfig 只解析访问 API Server 所需的字段
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string      `yaml:"token"`
+			TokenFile             string      `yaml:"tokenFile"`
+			ClientCertificate     string      `yaml:"client-certificate"`
+			ClientCertificateData string      `yaml:"client-certificate-data"`
+			ClientKey             string      `yaml:"client-key"`
+			ClientKeyData         string      `yaml:"client-key-data"`
+			Exec                  *execConfig `yaml:"exec"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// LoadKubeconfig 读取 kubeconfig 并为指定 context 创建客户端，context 为空时使用 current-context
+func LoadKubeconfig(path, context string) (*Client, error) {
+	if path == "" {
+		home, _ := os.UserHomeDir()
+		path = filepath.Join(home, ".kube", "config")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read kubeconfig: %w", err)
+	}
+	var cfg kubeconfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse kubeconfig %s: %w", path, err)
+	}
+	if context == "" {
+		context = cfg.CurrentContext
+	}
+	base := filepath.Dir(path)
+
+	var clusterName, userName string
+	for _, c := range cfg.Contexts {
+		if c.Name == context {
+			clusterName, userName = c.Context.Cluster, c.Context.User
+		}
+	}
+	if clusterName == "" {
+		return nil, fmt.Errorf("kubeconfig context %q not found", context)
+	}
+
+	tlsConfig := &tls.Config{}
+	client := &Client{Cluster: context}
+	for _, c := range cfg.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		client.Server = strings.TrimRight(c.Cluster.Server, "/")
+		tlsConfig.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify
+		ca, err := readData(c.Cluster.CertificateAuthorityData, c.Cluster.CertificateAuthority, base)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s ca: %w", clusterName, err)
+		}
+		if len(ca) > 0 {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("cluster %s ca: no certificates found", clusterName)
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+	if client.Server == "" {
+		return nil, fmt.Errorf("kubeconfig cluster %q not found", clusterName)
+	}
+
+	for _, u := range cfg.Users {
+		if u.Name != userName {
+			continue
+		}
+		client.Token = u.User.Token
+		if client.Token == "" && u.User.TokenFile != "" {
+			token, err := os.ReadFile(resolve(u.User.TokenFile, base))
+			if err != nil {
+				return nil, fmt.Errorf("user %s token: %w", userName, err)
+			}
+			client.Token = strings.TrimSpace(string(token))
+		}
+		cert, err := readData(u.User.ClientCertificateData, u.User.ClientCertificate, base)
+		if err != nil {
+			return nil, fmt.Errorf("user %s certificate: %w", userName, err)
+		}
+		key, err := readData(u.User.ClientKeyData, u.User.ClientKey, base)
+		if err != nil {
+			return nil, fmt.Errorf("user %s key: %w", userName, err)
+		}
+		// exec 凭据插件（aws eks get-token、gke-gcloud-auth-plugin、kubelogin 等）在加载时执行一次，
+		// 返回的令牌覆盖扫描期间的请求
+		if u.User.Exec != nil && client.Token == "" && len(cert) == 0 {
+			cred, err := u.User.Exec.run(base)
+			if err != nil {
+				return nil, fmt.Errorf("user %s exec: %w", userName, err)
+			}
+			client.Token = cred.Token
+			cert, key = []byte(cred.ClientCertificateData), []byte(cred.ClientKeyData)
+		}
+		if len(cert) > 0 && len(key) > 0 {
+			pair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				return nil, fmt.Errorf("user %s key pair: %w", userName, err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{pair}
+		}
+	}
+
+	client.HTTPClient = &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+	return client, nil
+}
+
+// readData 优先使用 base64 内联数据，否则读取相对 kubeconfig 目录的文件
+func readData(inline, file, base string) ([]byte, error) {
+	if inline != "" {
+		return base64.StdEncoding.DecodeString(inline)
+	}
+	if file == "" {
+		return nil, nil
+	}
+	return os.ReadFile(resolve(file, base))
+}
+
+func resolve(file, base string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(base, file)
+}
+
+// execTimeout exec 凭据插件的最长执行时间
+const execTimeout = time.Minute
+
+// execConfig users[].user.exec，client.authentication.k8s.io 凭据插件配置
+type execConfig struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Command    string   `yaml:"command"`
+	Args       []string `yaml:"args"`
+	Env        []struct {
+		Name  string `yaml:"name"`
+		Value string `yaml:"value"`
+	} `yaml:"env"`
+}
+
+// execStatus ExecCredential.status，证书与私钥为 PEM 文本
+type execStatus struct {
+	Token                 string `json:"token"`
+	ClientCertificateData string `json:"clientCertificateData"`
+	ClientKeyData         string `json:"clientKeyData"`
+}
+
+// run 以非交互方式执行插件并解析标准输出中的 ExecCredential；含路径分隔符的相对命令相对 kubeconfig 目录解析
+func (e *execConfig) run(base string) (*execStatus, error) {
+	if e.Command == "" {
+		return nil, fmt.Errorf("command is empty")
+	}
+	command := e.Command
+	if strings.ContainsRune(command, filepath.Separator) {
+		command = resolve(command, base)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), execTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, command, e.Args...)
+	info, _ := json.Marshal(map[string]interface{}{
+		"apiVersion": e.APIVersion,
+		"kind":       "ExecCredential",
+		"spec":       map[string]interface{}{"interactive": false},
+	})
+	cmd.Env = append(os.Environ(), "KUBERNETES_EXEC_INFO="+string(info))
+	for _, env := range e.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", e.Command, err, strings.TrimSpace(stderr.String()))
+	}
+	var cred struct {
+		Status *execStatus `json:"status"`
+	}
+	if err := json.Unmarshal(out, &cred); err != nil {
+		return nil, fmt.Errorf("%s: parse ExecCredential: %w", e.Command, err)
+	}
+	if cred.Status == nil || (cred.Status.Token == "" && cred.Status.ClientCertificateData == "") {
+		return nil, fmt.Errorf("%s: ExecCredential has no token or client certificate", e.Command)
+	}
+	return cred.Status, nil
+}
@@ -0,0 +1,173 @@
+package kube
+
+import (
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// ingress-nginx 的来源白名单注解
+var sourceRangeAnnotations = []string{
+	"nginx.ingress.kubernetes.io/whitelist-source-range",
+	"nginx.ingress.kubernetes.io/allowlist-source-range",
+}
+
+func (c *Collector) ingresses(inv *inventory) ([]*Surface, error) {
+	items, err := optional(c.client.List("/apis/networking.k8s.io/v1/ingresses"))
+	if err != nil {
+		return nil, err
+	}
+	var out []*Surface
+	for _, item := range items {
+		meta := cloud.Map(item, "metadata")
+		spec := cloud.Map(item, "spec")
+		annotations := cloud.Map(meta, "annotations")
+		s := &Surface{
+			Cluster:      c.client.Cluster,
+			Kind:         KindIngress,
+			Namespace:    cloud.String(meta, "namespace"),
+			Name:         cloud.String(meta, "name"),
+			Type:         cloud.FirstString(cloud.String(spec, "ingressClassName"), cloud.String(annotations, "kubernetes.io/ingress.class")),
+			Addresses:    []string{},
+			LinkedAssets: []AssetLink{},
+			Exposure:     cloud.ExposureNone,
+			Internal:     isInternal(annotations),
+		}
+		for _, rule := range cloud.Maps(spec, "rules") {
+			if host := cloud.String(rule, "host"); host != "" {
+				s.Hosts = cloud.AppendUnique(s.Hosts, host)
+			}
+			for _, p := range cloud.Maps(cloud.Map(rule, "http"), "paths") {
+				s.Paths = cloud.AppendUnique(s.Paths, cloud.FirstString(cloud.String(p, "path"), "/"))
+			}
+		}
+		for _, tls := range cloud.Maps(spec, "tls") {
+			for _, host := range cloud.Strings(tls, "hosts") {
+				s.TLSHosts = cloud.AppendUnique(s.TLSHosts, host)
+			}
+		}
+		s.Ports = []Port{{Name: "http", Port: 80, Protocol: "tcp"}}
+		if len(cloud.Maps(spec, "tls")) > 0 {
+			s.Ports = append(s.Ports, Port{Name: "https", Port: 443, Protocol: "tcp"})
+		}
+		for _, key := range sourceRangeAnnotations {
+			for _, cidr := range strings.Split(cloud.String(annotations, key), ",") {
+				if cidr = strings.TrimSpace(cidr); cidr != "" {
+					s.SourceRanges = cloud.AppendUnique(s.SourceRanges, cidr)
+				}
+			}
+		}
+		s.linkLoadBalancer(inv, cloud.Maps(cloud.Map(cloud.Map(item, "status"), "loadBalancer"), "ingress"))
+		s.Hosts = sortedStrings(s.Hosts)
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+type gatewayListener struct {
+	name     string
+	hostname string
+	port     int
+	protocol string
+}
+
+type gateway struct {
+	addresses []map[string]interface{}
+	listeners []gatewayListener
+	internal  bool
+}
+
+// httpRoutes 采集 Gateway API 的 HTTPRoute，地址与端口来自其挂载的 Gateway 监听器
+func (c *Collector) httpRoutes(inv *inventory) ([]*Surface, error) {
+	gws, err := optional(c.client.List("/apis/gateway.networking.k8s.io/v1/gateways"))
+	if err != nil {
+		return nil, err
+	}
+	gateways := map[string]*gateway{}
+	for _, item := range gws {
+		meta := cloud.Map(item, "metadata")
+		gw := &gateway{internal: isInternal(cloud.Map(meta, "annotations"))}
+		for _, addr := range cloud.Maps(cloud.Map(item, "status"), "addresses") {
+			v := cloud.String(addr, "value")
+			if v == "" {
+				continue
+			}
+			// 统一为 Service 状态中 ip/hostname 的形式，复用负载均衡关联逻辑
+			key := "ip"
+			if strings.EqualFold(cloud.String(addr, "type"), "Hostname") {
+				key = "hostname"
+			}
+			gw.addresses = append(gw.addresses, map[string]interface{}{key: v})
+		}
+		for _, l := range cloud.Maps(cloud.Map(item, "spec"), "listeners") {
+			port, _ := cloud.Int(l, "port")
+			gw.listeners = append(gw.listeners, gatewayListener{
+				name:     cloud.String(l, "name"),
+				hostname: cloud.String(l, "hostname"),
+				port:     port,
+				protocol: strings.ToUpper(cloud.String(l, "protocol")),
+			})
+		}
+		gateways[cloud.String(meta, "namespace")+"/"+cloud.String(meta, "name")] = gw
+	}
+
+	routes, err := optional(c.client.List("/apis/gateway.networking.k8s.io/v1/httproutes"))
+	if err != nil {
+		return nil, err
+	}
+	var out []*Surface
+	for _, item := range routes {
+		meta := cloud.Map(item, "metadata")
+		spec := cloud.Map(item, "spec")
+		s := &Surface{
+			Cluster:      c.client.Cluster,
+			Kind:         KindHTTPRoute,
+			Namespace:    cloud.String(meta, "namespace"),
+			Name:         cloud.String(meta, "name"),
+			Hosts:        cloud.Strings(spec, "hostnames"),
+			Addresses:    []string{},
+			LinkedAssets: []AssetLink{},
+			Exposure:     cloud.ExposureNone,
+		}
+		for _, rule := range cloud.Maps(spec, "rules") {
+			for _, m := range cloud.Maps(rule, "matches") {
+				if p := cloud.String(cloud.Map(m, "path"), "value"); p != "" {
+					s.Paths = cloud.AppendUnique(s.Paths, p)
+				}
+			}
+		}
+		for _, ref := range cloud.Maps(spec, "parentRefs") {
+			if kind := cloud.String(ref, "kind"); kind != "" && kind != "Gateway" {
+				continue
+			}
+			ns := cloud.FirstString(cloud.String(ref, "namespace"), s.Namespace)
+			name := cloud.String(ref, "name")
+			gw, ok := gateways[ns+"/"+name]
+			if !ok {
+				continue
+			}
+			s.Type = cloud.FirstString(s.Type, ns+"/"+name)
+			section := cloud.String(ref, "sectionName")
+			for _, l := range gw.listeners {
+				if section != "" && l.name != section {
+					continue
+				}
+				if l.protocol != "HTTP" && l.protocol != "HTTPS" {
+					continue
+				}
+				s.Ports = append(s.Ports, Port{Name: l.name, Port: l.port, Protocol: "tcp"})
+				if len(cloud.Strings(spec, "hostnames")) == 0 && l.hostname != "" {
+					s.Hosts = cloud.AppendUnique(s.Hosts, l.hostname)
+				}
+				if l.protocol == "HTTPS" {
+					s.TLSHosts = cloud.AppendUnique(s.TLSHosts, cloud.FirstString(l.hostname, "*"))
+				}
+			}
+			s.Internal = s.Internal || gw.internal
+			s.linkLoadBalancer(inv, gw.addresses)
+		}
+		s.Hosts = sortedStrings(s.Hosts)
+		out = append(out, s)
+	}
+	return out, nil
+}
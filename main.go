@@ -1,21 +1,54 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/colin-404/logx"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/alert"
+	_ "github.com/xid-protocol/attack-surface/aliyun"
+	"github.com/xid-protocol/attack-surface/api"
 	"github.com/xid-protocol/attack-surface/aws"
+	_ "github.com/xid-protocol/attack-surface/azure"
+	"github.com/xid-protocol/attack-surface/certs"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/export"
+	"github.com/xid-protocol/attack-surface/finding"
+	"github.com/xid-protocol/attack-surface/fingerprint"
+	_ "github.com/xid-protocol/attack-surface/gcp"
+	"github.com/xid-protocol/attack-surface/geoip"
+	"github.com/xid-protocol/attack-surface/ipranges"
+	"github.com/xid-protocol/attack-surface/kube"
+	"github.com/xid-protocol/attack-surface/notify"
+	"github.com/xid-protocol/attack-surface/owner"
+	"github.com/xid-protocol/attack-surface/policy"
+	"github.com/xid-protocol/attack-surface/query"
+	"github.com/xid-protocol/attack-surface/risk"
+	_ "github.com/xid-protocol/attack-surface/tencent"
+	_ "github.com/xid-protocol/attack-surface/ticket"
+	"github.com/xid-protocol/attack-surface/verify"
+	"github.com/xid-protocol/attack-surface/webscan"
 	"github.com/xid-protocol/xidp/biz"
 )
 
 var sig = make(chan os.Signal, 1)
 
+// 命令行过滤与分组，优先于 Export.filter 与 Export.group_by
+var (
+	filterExpr = flag.String("filter", "", `filter expression, e.g. "tag:env=prod AND team:payments AND port:22"`)
+	groupBy    = flag.String("group-by", "", "group results by provider, account, region, vpc, team, owner, severity or tag:<key>")
+)
+
+// snapshot 最近一次扫描结果，供查询接口使用
+var snapshot = &api.Snapshot{}
+
 func initConfig() string {
 	confPath := flag.String("c", "/opt/xidp/conf/config.yml", "config file path")
 	flag.Parse()
@@ -72,16 +105,227 @@ func main() {
 	result := awsCloud.GetAllEC2Info()
 	logx.Infof("result: %d", len(result))
 	aws.GetPublicIP(result)
-	// go ServerStart()
+
+	// CIS 网络控制项报告，输出到 CIS.output_dir，默认当前目录；CIS.filter 可按账号、VPC 等筛选
+	if viper.GetBool("CIS.enabled") {
+		report, err := awsCloud.CISNetworkReport()
+		if err != nil {
+			logx.Errorf("cis network report error: %v", err)
+		} else if f, err := query.Parse(viper.GetString("CIS.filter")); err != nil {
+			logx.Errorf("parse CIS.filter error: %v", err)
+		} else {
+			report.Filter(f)
+			if err := report.Export(cloud.FirstString(viper.GetString("CIS.output_dir"), ".")); err != nil {
+				logx.Errorf("export cis report error: %v", err)
+			}
+		}
+	}
+
+	// 按配置依次采集各云厂商的攻击面，默认只采集 aws
+	providers := viper.GetStringSlice("Cloud.providers")
+	if len(providers) == 0 {
+		providers = []string{aws.ProviderName}
+	}
+	var inventory []*cloud.Asset
+	var findings []*finding.Finding
+	// 记录失败的厂商与阶段，告警生命周期据此避免把漏采的问题标记为已解决
+	var coverage alert.Coverage
+	for _, name := range providers {
+		// aws 复用上面已创建的实例，避免重复连接
+		var p cloud.CloudProvider = awsCloud
+		if name != aws.ProviderName {
+			p = cloud.New(name)
+		}
+		if p == nil {
+			logx.Errorf("unknown cloud provider: %s, registered: %v", name, cloud.Names())
+			coverage.FailedProviders = append(coverage.FailedProviders, name)
+			continue
+		}
+		xids, err := p.Collect()
+		if err != nil {
+			logx.Errorf("collect %s attack surface error: %v", name, err)
+			coverage.FailedProviders = append(coverage.FailedProviders, name)
+			continue
+		}
+		logx.Infof("%s attack surface: %d", name, len(xids))
+		inventory = append(inventory, cloud.AssetsOf(xids)...)
+		findings = append(findings, finding.Of(xids)...)
+	}
+
+	// Kubernetes 暴露面依赖云资产清单关联负载均衡与节点，合并后与云资产一起参与探测、评分与策略
+	if viper.IsSet("Kubernetes") {
+		xids, err := kube.CollectFromConfig(inventory)
+		if err != nil {
+			logx.Errorf("collect kubernetes attack surface error: %v", err)
+			coverage.FailedProviders = append(coverage.FailedProviders, kube.ProviderName)
+		} else {
+			logx.Infof("kubernetes attack surface: %d", len(xids))
+			inventory = append(inventory, kube.AssetsOf(xids)...)
+		}
+	}
+
+	// 归属需在生成问题之前解析，问题与通知会带上负责团队
+	var owners *owner.Resolver
+	if viper.IsSet("Ownership") {
+		resolver, err := owner.NewResolverFromViper()
+		if err != nil {
+			logx.Errorf("load ownership config error: %v", err)
+		} else {
+			owners = resolver
+			owners.ResolveAssets(inventory)
+		}
+	}
+
+	// GeoIP 标注来源地址段与公网地址的国家与 ASN，策略可通过 asset.rules[].geo 引用
+	if viper.GetBool("GeoIP.enabled") {
+		enricher, err := geoip.NewEnricherFromViper()
+		if err != nil {
+			logx.Errorf("init geoip error: %v", err)
+			coverage.FailedStages = append(coverage.FailedStages, "geoip")
+		} else {
+			enricher.EnrichAssets(inventory)
+			findings = append(findings, enricher.Evaluate(inventory)...)
+			enricher.Close()
+		}
+	}
+
+	// 公网地址分类并匹配本地缓存的云厂商地址段，标注服务与区域
+	if viper.GetBool("IPRanges.enabled") {
+		classifier, err := ipranges.NewClassifierFromViper()
+		if err != nil {
+			logx.Errorf("init ip ranges error: %v", err)
+			coverage.FailedStages = append(coverage.FailedStages, "ipranges")
+		} else {
+			classifier.ClassifyAssets(inventory)
+			findings = append(findings, classifier.Evaluate(inventory)...)
+		}
+	}
+
+	// 可选的主动探测，只对 Verify.scope 范围内的地址发起连接
+	if viper.GetBool("Verify.enabled") {
+		verifier, err := verify.NewVerifier(verify.ConfigFromViper())
+		if err != nil {
+			logx.Errorf("init verifier error: %v", err)
+			coverage.FailedStages = append(coverage.FailedStages, "verify")
+		} else {
+			verifier.VerifyAssets(context.Background(), inventory)
+			// 服务识别只针对已验证开放的端口
+			if viper.GetBool("Fingerprint.enabled") {
+				engine := fingerprint.NewEngineFromViper()
+				engine.Limiter = verifier
+				engine.FingerprintAssets(context.Background(), inventory)
+			}
+			// 证书采集同样只针对已验证开放的端口，Route53 域名用于 SNI 与 SAN 核对
+			if viper.GetBool("Certs.enabled") {
+				names, err := awsCloud.Route53Names()
+				if err != nil {
+					logx.Errorf("load route53 names error: %v", err)
+					coverage.FailedStages = append(coverage.FailedStages, "certs")
+				}
+				findings = append(findings, certs.NewScannerFromViper().ScanAssets(context.Background(), inventory, names)...)
+			}
+			// 页面、安全响应头与敏感路径探测只针对识别为 Web 服务的开放端口
+			if viper.GetBool("Webscan.enabled") {
+				findings = append(findings, webscan.NewScannerFromViper().ScanAssets(context.Background(), inventory)...)
+			}
+		}
+	}
+
+	// 安全组清理清单与暴露问题一起输出
+	if viper.GetBool("Cleanup.enabled") {
+		cleanup, err := awsCloud.SecurityGroupCleanup()
+		if err != nil {
+			logx.Errorf("security group cleanup error: %v", err)
+			coverage.FailedStages = append(coverage.FailedStages, "cleanup")
+		}
+		findings = append(findings, cleanup...)
+	}
+
+	// 按风险评分排序，后续输出按该顺序展示；敏感端口与大范围端口的暴露作为内置问题输出
+	model := risk.NewModelFromViper()
+	model.ScoreAssets(inventory)
+	risk.Sort(inventory)
+	findings = append(findings, model.Evaluate(inventory)...)
+
+	pipe := &pipeline{inventory: inventory, base: findings, owners: owners, coverage: coverage}
+	if viper.GetBool("Alert.enabled") {
+		pipe.tracker = alert.NewTrackerFromViper()
+	}
+	// 通知先写入 outbox，失败的消息由后台按退避策略重试
+	if viper.GetBool("Notify.enabled") {
+		notifier, err := notify.NewNotifierFromViper()
+		if err != nil {
+			logx.Errorf("init notifier error: %v", err)
+		} else {
+			pipe.notifier = notifier
+			interval := time.Duration(viper.GetInt("Notify.flush_interval_seconds")) * time.Second
+			go notifier.Run(context.Background(), interval)
+		}
+	}
+
+	// 策略在评分之后求值，表达式可以引用 asset.risk；热加载时替换策略违规并重新发布
+	var engine *policy.Engine
+	var violations []*finding.Finding
+	if len(viper.GetStringSlice("Policy.files")) > 0 {
+		loaded, err := policy.NewEngineFromViper()
+		if err != nil {
+			logx.Errorf("load policy error: %v", err)
+			pipe.coverage.FailedStages = append(pipe.coverage.FailedStages, "policy")
+		} else {
+			engine = loaded
+			violations = engine.Evaluate(inventory)
+		}
+	}
+	findings = pipe.publish(violations)
+	if engine != nil && viper.GetBool("Policy.hot_reload") {
+		go func() {
+			if err := engine.Watch(context.Background(), func() { pipe.reload(engine) }); err != nil {
+				logx.Errorf("watch policy error: %v", err)
+			}
+		}()
+	}
+
+	// 过滤与分组同时作用于日志汇总与导出，查询接口按请求参数另行过滤
+	if f, err := query.Parse(cloud.FirstString(*filterExpr, viper.GetString("Export.filter"))); err != nil {
+		logx.Errorf("parse filter error: %v", err)
+	} else if res, err := query.Run(f, cloud.FirstString(*groupBy, viper.GetString("Export.group_by")), inventory, findings); err != nil {
+		logx.Errorf("query error: %v", err)
+	} else {
+		logx.Infof("filter %q: assets=%d, findings=%d", res.Filter, len(res.Assets), len(res.Findings))
+		for _, g := range res.Groups {
+			logx.Infof("  %s=%s: assets=%d, findings=%d, maxRisk=%d, maxSeverity=%s",
+				res.GroupBy, g.Key, g.Assets, g.Findings, g.MaxRisk, g.MaxSeverity)
+		}
+		if viper.GetBool("Export.enabled") {
+			if err := export.Export(cloud.FirstString(viper.GetString("Export.output_dir"), "."), res); err != nil {
+				logx.Errorf("export attack surface error: %v", err)
+			}
+		}
+	}
+	if viper.GetBool("Server.enabled") {
+		var ack api.Acknowledger
+		if pipe.tracker != nil {
+			ack = pipe
+		}
+		go ServerStart(ack)
+	}
 	//go sealsuite.SealsuiteAcountInit()
 	//go accounts.AccountMonitor()
 	<-sig
+	if err := cloud.CloseStores(); err != nil {
+		logx.Errorf("close mongo error: %v", err)
+	}
 }
 
-func ServerStart() {
+// ServerStart 启动查询接口，ack 不为空时（开启告警生命周期）同时注册告警确认接口
+func ServerStart(ack api.Acknowledger) {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.Default()
 	biz.RegisterRouter(router)
+	api.RegisterRouter(router, snapshot)
+	if ack != nil {
+		api.RegisterAlertRouter(router, ack)
+	}
 
 	//获取端口配置，如果获取不到，则退出
 	port := viper.GetInt("Server.port")
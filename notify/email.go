@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// email SMTP 邮件，服务器支持时自动使用 STARTTLS，不支持 465 端口的隐式 TLS
+type email struct {
+	name     string
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+func newEmail(name string, cfg map[string]interface{}) (Sink, error) {
+	port, ok := cloud.Int(cfg, "port")
+	if !ok {
+		port = 25
+	}
+	e := &email{
+		name:     name,
+		host:     cloud.String(cfg, "host"),
+		username: cloud.String(cfg, "username"),
+		password: cloud.String(cfg, "password"),
+		from:     cloud.String(cfg, "from"),
+		to:       cloud.Strings(cfg, "to"),
+	}
+	if e.host == "" || e.from == "" || len(e.to) == 0 {
+		return nil, errors.New("email sink requires host, from and to")
+	}
+	e.addr = net.JoinHostPort(e.host, strconv.Itoa(port))
+	return e, nil
+}
+
+func (e *email) Name() string { return e.name }
+
+func (e *email) Send(ctx context.Context, msg *Message) error {
+	var auth smtp.Auth
+	if e.username != "" {
+		auth = smtp.PlainAuth("", e.username, e.password, e.host)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", encodeSubject(msg.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Text, "\n", "\r\n"))
+
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(e.addr, auth, e.from, e.to, []byte(b.String())) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// encodeSubject 去掉换行防止头注入，非 ASCII 标题按 RFC 2047 编码
+func encodeSubject(title string) string {
+	title = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(title)
+	return mime.QEncoding.Encode("utf-8", title)
+}
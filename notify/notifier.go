@@ -0,0 +1,334 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// 重试默认值
+const (
+	defaultMaxAttempts = 5
+	defaultBackoff     = 30 * time.Second
+	maxBackoff         = time.Hour
+)
+
+// Notifier 把问题渲染为消息写入发件箱，再按路由发送到各 sink，失败按指数退避重试
+type Notifier struct {
+	Outbox      Outbox
+	Routes      []Route
+	MaxAttempts int
+	Backoff     time.Duration
+	// Digest 大于 0 时开启摘要模式，消息先暂存，每个 sink 每个周期合并发送一次
+	Digest time.Duration
+
+	sinks     map[string]Sink
+	templates map[string]*templates
+	digest    *templates
+}
+
+// NewNotifierFromViper 读取 Notify 配置段
+//
+//	Notify:
+//	  sinks:
+//	    - {name: sec-slack, type: slack, url: https://hooks.slack.com/...}
+//	    - {name: soc, type: webhook, url: https://soc/hook, secret: xxx}
+//	  routes:
+//	    - {sinks: [sec-slack], min_severity: high}
+//	  templates: {title: "...", text: "..."}
+//	  retry: {max_attempts: 5, backoff_seconds: 30}
+//	  digest: {interval_minutes: 60, title: "...", text: "..."}
+func NewNotifierFromViper() (*Notifier, error) {
+	var sinkConfigs []map[string]interface{}
+	if err := viper.UnmarshalKey("Notify.sinks", &sinkConfigs); err != nil {
+		return nil, fmt.Errorf("parse Notify.sinks: %w", err)
+	}
+	var routes []Route
+	if err := viper.UnmarshalKey("Notify.routes", &routes); err != nil {
+		return nil, fmt.Errorf("parse Notify.routes: %w", err)
+	}
+	n := &Notifier{
+		Outbox:      NewMongoOutboxFromViper(),
+		Routes:      routes,
+		MaxAttempts: viper.GetInt("Notify.retry.max_attempts"),
+		Backoff:     time.Duration(viper.GetInt("Notify.retry.backoff_seconds")) * time.Second,
+		Digest:      time.Duration(viper.GetInt("Notify.digest.interval_minutes")) * time.Minute,
+	}
+	if err := n.SetDigestTemplates(viper.GetString("Notify.digest.title"), viper.GetString("Notify.digest.text")); err != nil {
+		return nil, err
+	}
+	title := cloud.FirstString(viper.GetString("Notify.templates.title"), defaultTitle)
+	text := cloud.FirstString(viper.GetString("Notify.templates.text"), defaultText)
+	for _, cfg := range sinkConfigs {
+		if err := n.AddSink(cfg, title, text); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// AddSink 按配置创建 sink，sink 级的 title_template/text_template 覆盖全局模板
+func (n *Notifier) AddSink(cfg map[string]interface{}, title, text string) error {
+	kind := cloud.String(cfg, "type")
+	name := cloud.FirstString(cloud.String(cfg, "name"), kind)
+	s, err := build(kind, name, cfg)
+	if err != nil {
+		return fmt.Errorf("sink %s: %w", name, err)
+	}
+	t, err := parseTemplates(name,
+		cloud.FirstString(cloud.String(cfg, "title_template"), title),
+		cloud.FirstString(cloud.String(cfg, "text_template"), text))
+	if err != nil {
+		return fmt.Errorf("sink %s template: %w", name, err)
+	}
+	if n.sinks == nil {
+		n.sinks = map[string]Sink{}
+		n.templates = map[string]*templates{}
+	}
+	if _, ok := n.sinks[name]; ok {
+		return fmt.Errorf("duplicate sink name %s", name)
+	}
+	n.sinks[name] = s
+	n.templates[name] = t
+	return nil
+}
+
+// SetDigestTemplates 设置摘要模板，为空时使用默认模板
+func (n *Notifier) SetDigestTemplates(title, text string) error {
+	t, err := parseTemplates("digest",
+		cloud.FirstString(title, defaultDigestTitle),
+		cloud.FirstString(text, defaultDigestText))
+	if err != nil {
+		return fmt.Errorf("digest template: %w", err)
+	}
+	n.digest = t
+	return nil
+}
+
+// sinksFor 返回问题路由到的 sink；未配置路由时发送到全部 sink
+func (n *Notifier) sinksFor(f *finding.Finding) []string {
+	var out []string
+	if len(n.Routes) == 0 {
+		for name := range n.sinks {
+			out = append(out, name)
+		}
+		return out
+	}
+	for _, r := range n.Routes {
+		if !r.matches(f) {
+			continue
+		}
+		for _, name := range r.Sinks {
+			if _, ok := n.sinks[name]; ok {
+				out = cloud.AppendUnique(out, name)
+			}
+		}
+	}
+	return out
+}
+
+// Notify 为每个未豁免的问题按路由生成消息写入发件箱，然后尝试发送。
+// 启用生命周期跟踪时传入的是状态发生变化的问题，同一问题的同一状态只通知一次
+func (n *Notifier) Notify(ctx context.Context, findings []*finding.Finding) {
+	var queued int
+	for _, f := range finding.Actionable(findings) {
+		for _, name := range n.sinksFor(f) {
+			msg, err := n.templates[name].render(f)
+			if err != nil {
+				logx.Errorf("render notification for %s error: %v", name, err)
+				continue
+			}
+			id := finding.Key(name, f.ID, string(f.State), f.DetectedAt.Format(time.RFC3339Nano))
+			if err := n.enqueue(name, msg, id, n.statusFor(name)); err != nil {
+				logx.Errorf("enqueue notification error: %v", err)
+				continue
+			}
+			queued++
+		}
+	}
+	logx.Infof("notification queued: %d", queued)
+	n.Flush(ctx)
+}
+
+// statusFor 摘要模式下除 Direct sink 外的消息先暂存
+func (n *Notifier) statusFor(sink string) string {
+	if n.Digest <= 0 {
+		return StatusPending
+	}
+	if d, ok := n.sinks[sink].(Direct); ok && d.Direct() {
+		return StatusPending
+	}
+	return StatusDigest
+}
+
+// Enqueue 写入一条待发送消息，id 相同的消息已存在时不重复写入
+func (n *Notifier) Enqueue(sink string, msg *Message, id string) error {
+	return n.enqueue(sink, msg, id, StatusPending)
+}
+
+func (n *Notifier) enqueue(sink string, msg *Message, id, status string) error {
+	existing, err := n.Outbox.Get(id)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	return n.Outbox.Put(&OutboxItem{
+		ID:          id,
+		Sink:        sink,
+		Message:     *msg,
+		Status:      status,
+		NextAttempt: now,
+		CreatedAt:   now,
+	})
+}
+
+// Flush 合并到期的摘要，再发送发件箱中到期的消息，sink 已从配置中移除的消息保留待处理
+func (n *Notifier) Flush(ctx context.Context) {
+	if n.Digest > 0 {
+		n.flushDigest(time.Now().UTC())
+	}
+	items, err := n.Outbox.List(StatusPending)
+	if err != nil {
+		logx.Errorf("load notification outbox error: %v", err)
+		return
+	}
+	now := time.Now().UTC()
+	var sent, failed int
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return
+		}
+		s, ok := n.sinks[item.Sink]
+		if !ok || item.NextAttempt.After(now) {
+			continue
+		}
+		item.Attempts++
+		if err := s.Send(ctx, &item.Message); err != nil {
+			item.LastError = err.Error()
+			item.NextAttempt = now.Add(n.backoff(item.Attempts))
+			if item.Attempts >= n.maxAttempts() {
+				item.Status = StatusFailed
+			}
+			failed++
+			logx.Errorf("send notification %s via %s error (attempt %d): %v", item.ID, item.Sink, item.Attempts, err)
+		} else {
+			item.Status = StatusSent
+			item.SentAt = time.Now().UTC()
+			item.LastError = ""
+			sent++
+		}
+		if err := n.Outbox.Put(item); err != nil {
+			logx.Errorf("update notification outbox error: %v", err)
+		}
+	}
+	if sent > 0 || failed > 0 {
+		logx.Infof("notification summary: sent=%d, failed=%d", sent, failed)
+	}
+}
+
+// flushDigest 某个 sink 最早暂存的消息超过一个摘要周期时，把该 sink 的暂存消息合并为一条待发送消息
+func (n *Notifier) flushDigest(now time.Time) {
+	if n.digest == nil {
+		if err := n.SetDigestTemplates("", ""); err != nil {
+			logx.Errorf("%v", err)
+			return
+		}
+	}
+	items, err := n.Outbox.List(StatusDigest)
+	if err != nil {
+		logx.Errorf("load notification digest error: %v", err)
+		return
+	}
+	bySink := map[string][]*OutboxItem{}
+	var sinks []string
+	for _, item := range items {
+		if _, ok := n.sinks[item.Sink]; !ok {
+			continue
+		}
+		if _, ok := bySink[item.Sink]; !ok {
+			sinks = append(sinks, item.Sink)
+		}
+		bySink[item.Sink] = append(bySink[item.Sink], item)
+	}
+	for _, sink := range sinks {
+		batch := bySink[sink]
+		since := batch[0].CreatedAt
+		for _, item := range batch {
+			if item.CreatedAt.Before(since) {
+				since = item.CreatedAt
+			}
+		}
+		if now.Sub(since) < n.Digest {
+			continue
+		}
+		d := &Digest{Sink: sink, Since: since, Until: now}
+		for _, item := range batch {
+			if item.Message.Finding != nil {
+				d.Findings = append(d.Findings, item.Message.Finding)
+			}
+		}
+		finding.Sort(d.Findings)
+		msg, err := n.digest.execute(d)
+		if err != nil {
+			logx.Errorf("render digest for %s error: %v", sink, err)
+			continue
+		}
+		if err := n.Enqueue(sink, msg, finding.Key(sink, "digest", since.Format(time.RFC3339Nano))); err != nil {
+			logx.Errorf("enqueue digest for %s error: %v", sink, err)
+			continue
+		}
+		for _, item := range batch {
+			item.Status = StatusBatched
+			if err := n.Outbox.Put(item); err != nil {
+				logx.Errorf("update notification outbox error: %v", err)
+			}
+		}
+		logx.Infof("notification digest for %s: %d items", sink, len(batch))
+	}
+}
+
+// Run 定期重试发件箱，直到 ctx 结束
+func (n *Notifier) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.Flush(ctx)
+		}
+	}
+}
+
+func (n *Notifier) maxAttempts() int {
+	if n.MaxAttempts <= 0 {
+		return defaultMaxAttempts
+	}
+	return n.MaxAttempts
+}
+
+// backoff 第 n 次失败后的等待时间，按 2 的幂增长，最长一小时
+func (n *Notifier) backoff(attempts int) time.Duration {
+	base := n.Backoff
+	if base <= 0 {
+		base = defaultBackoff
+	}
+	d := time.Duration(float64(base) * math.Pow(2, float64(attempts-1)))
+	if d > maxBackoff || d <= 0 {
+		return maxBackoff
+	}
+	return d
+}
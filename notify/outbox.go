@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/xidp/protocols"
+	"github.com/xid-protocol/xidp/xdb"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const PathOutbox = "/protocols/external-attack-surface/notification"
+
+// 默认的发件箱集合
+const defaultCollection = "attack_surface_outbox"
+
+// 发件箱状态
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"  // 超过最大重试次数
+	StatusDigest  = "digest"  // 等待合并进摘要
+	StatusBatched = "batched" // 已合并进摘要消息
+)
+
+// OutboxItem 待发送的通知，发送前先落库，重启后继续重试
+type OutboxItem struct {
+	ID          string    `json:"id" bson:"id"`
+	Sink        string    `json:"sink" bson:"sink"`
+	Message     Message   `json:"message" bson:"message"`
+	Status      string    `json:"status" bson:"status"`
+	Attempts    int       `json:"attempts" bson:"attempts"`
+	NextAttempt time.Time `json:"nextAttempt" bson:"nextAttempt"`
+	LastError   string    `json:"lastError,omitempty" bson:"lastError,omitempty"`
+	CreatedAt   time.Time `json:"createdAt" bson:"createdAt"`
+	SentAt      time.Time `json:"sentAt,omitempty" bson:"sentAt,omitempty"`
+}
+
+// Outbox 发件箱存储
+type Outbox interface {
+	Put(item *OutboxItem) error
+	Get(id string) (*OutboxItem, error)
+	List(status string) ([]*OutboxItem, error)
+}
+
+// MongoOutbox 每条通知为一个 XID，状态同时写入 metadata.extra 以便只拉取待发送记录
+type MongoOutbox struct {
+	*cloud.Store
+}
+
+// NewMongoOutboxFromViper 集合名读取 Notify.collection
+func NewMongoOutboxFromViper() *MongoOutbox {
+	return &MongoOutbox{Store: cloud.NewStore(cloud.FirstString(viper.GetString("Notify.collection"), defaultCollection))}
+}
+
+func (o *MongoOutbox) Put(item *OutboxItem) error {
+	x := cloud.NewAttackSurfaceXID(item.ID, "notification", PathOutbox, item)
+	x.Metadata.Extra = map[string]any{"status": item.Status, "sink": item.Sink}
+	return o.DBClient.Upsert(o.Ctx, x)
+}
+
+// Get 不存在时返回 nil
+func (o *MongoOutbox) Get(id string) (*OutboxItem, error) {
+	x, err := o.DBClient.GetByXid(o.Ctx, PathOutbox, protocols.GenerateXid(id))
+	if err == xdb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeItem(cloud.PayloadOf(x))
+}
+
+// List 按创建时间升序返回指定状态的消息
+func (o *MongoOutbox) List(status string) ([]*OutboxItem, error) {
+	q := xdb.Query{
+		Path:         PathOutbox,
+		AttributesEq: map[string]any{"status": status},
+		PageSize:     100,
+		SortBy:       "createdAt",
+		SortAsc:      true,
+	}
+	var out []*OutboxItem
+	for {
+		items, next, err := o.DBClient.List(o.Ctx, q)
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", PathOutbox, err)
+		}
+		for _, x := range items {
+			item, err := decodeItem(cloud.PayloadOf(x))
+			if err == nil && item.Status == status {
+				out = append(out, item)
+			}
+		}
+		if next == "" {
+			break
+		}
+		q.AfterCursor = &next
+	}
+	return out, nil
+}
+
+func decodeItem(payload map[string]interface{}) (*OutboxItem, error) {
+	if payload == nil {
+		return nil, fmt.Errorf("empty payload")
+	}
+	b, err := bson.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var item OutboxItem
+	if err := bson.Unmarshal(b, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
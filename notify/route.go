@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// Route 按严重程度、规则、厂商与负责团队把问题路由到 sink，未配置的条件不参与匹配
+type Route struct {
+	Sinks       []string `mapstructure:"sinks"`
+	MinSeverity string   `mapstructure:"min_severity"`
+	Severities  []string `mapstructure:"severities"`
+	Rules       []string `mapstructure:"rules"`
+	Providers   []string `mapstructure:"providers"`
+	Teams       []string `mapstructure:"teams"`
+}
+
+func (r Route) matches(f *finding.Finding) bool {
+	if min := finding.ParseSeverity(r.MinSeverity); min != "" && f.Severity.Rank() < min.Rank() {
+		return false
+	}
+	if len(r.Severities) > 0 && !containsFold(r.Severities, string(f.Severity)) {
+		return false
+	}
+	if len(r.Rules) > 0 && !finding.MatchRule(r.Rules, f.Rule) {
+		return false
+	}
+	if len(r.Providers) > 0 && !containsFold(r.Providers, f.Provider) {
+		return false
+	}
+	if len(r.Teams) > 0 && (f.Owner == nil || !containsFold(r.Teams, f.Owner.Team)) {
+		return false
+	}
+	return true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
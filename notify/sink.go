@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// Message 渲染后的通知内容，Finding 供需要结构化数据的 sink 使用
+type Message struct {
+	Title   string           `json:"title" bson:"title"`
+	Text    string           `json:"text" bson:"text"`
+	Finding *finding.Finding `json:"finding,omitempty" bson:"finding,omitempty"`
+}
+
+// Sink 通知渠道
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Direct 由需要逐条处理问题的 sink 实现（如工单），摘要模式下这类 sink 的消息不合并
+type Direct interface {
+	Direct() bool
+}
+
+// Builder 根据配置创建 sink，cfg 为 Notify.sinks 中的一项
+type Builder func(name string, cfg map[string]interface{}) (Sink, error)
+
+var (
+	mu       sync.RWMutex
+	builders = map[string]Builder{}
+)
+
+// Register 注册 sink 类型，重复注册会覆盖
+func Register(kind string, b Builder) {
+	mu.Lock()
+	defer mu.Unlock()
+	builders[kind] = b
+}
+
+// Kinds 返回已注册的 sink 类型
+func Kinds() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]string, 0, len(builders))
+	for k := range builders {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func build(kind, name string, cfg map[string]interface{}) (Sink, error) {
+	mu.RLock()
+	b, ok := builders[kind]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown sink type %q, registered: %v", kind, Kinds())
+	}
+	return b(name, cfg)
+}
+
+func init() {
+	Register("slack", newSlack)
+	Register("webhook", newWebhook)
+	Register("feishu", newFeishu)
+	Register("lark", newFeishu)
+	Register("dingtalk", newDingTalk)
+	Register("email", newEmail)
+}
+
+// httpClient sink 共用的 HTTP 客户端
+var httpClient = &http.Client{Timeout: 10 * time.Second}
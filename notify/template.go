@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// 默认模板，数据为 *finding.Finding
+const (
+	defaultTitle = `[{{upper .Severity}}]{{if .State}} [{{upper .State}}]{{end}} {{.Title}}`
+	defaultText  = `Resource: {{.Provider}} {{.ResourceType}} {{.ResourceID}}{{if .ResourceName}} ({{.ResourceName}}){{end}}
+{{if .State}}State: {{.State}}
+{{end}}{{with .Owner}}Owner: {{.Team}}{{if .Owner}} / {{.Owner}}{{end}}{{if .Slack}} {{.Slack}}{{end}}
+{{end}}{{if .Region}}Region: {{.Region}}
+{{end}}{{if .Target}}Target: {{.Target}}
+{{end}}Rule: {{.Rule}}
+{{if .Detail}}Detail: {{.Detail}}
+{{end}}Detected: {{.DetectedAt.Format "2006-01-02 15:04:05 MST"}}`
+)
+
+// 默认摘要模板，数据为 *Digest
+const (
+	defaultDigestTitle = `Attack surface digest: {{len .Findings}} changes`
+	defaultDigestText  = `{{.Since.Format "2006-01-02 15:04"}} - {{.Until.Format "2006-01-02 15:04 MST"}}
+{{range .Findings}}- [{{upper .Severity}}]{{if .State}} {{.State}}{{end}} {{.Title}} ({{.Provider}} {{.ResourceID}}{{if .Target}} {{.Target}}{{end}}){{with .Owner}} @{{.Team}}{{end}}
+{{end}}`
+)
+
+// Digest 摘要模板数据，Findings 按严重程度排序
+type Digest struct {
+	Sink     string
+	Since    time.Time
+	Until    time.Time
+	Findings []*finding.Finding
+}
+
+var funcs = template.FuncMap{
+	"upper": func(v interface{}) string {
+		return strings.ToUpper(fmt.Sprint(v))
+	},
+	"join": strings.Join,
+}
+
+// templates 标题与正文模板
+type templates struct {
+	title *template.Template
+	text  *template.Template
+}
+
+func parseTemplates(name, title, text string) (*templates, error) {
+	t := &templates{}
+	var err error
+	if t.title, err = template.New(name + "-title").Funcs(funcs).Parse(title); err != nil {
+		return nil, err
+	}
+	if t.text, err = template.New(name + "-text").Funcs(funcs).Parse(text); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// render 渲染单个问题
+func (t *templates) render(f *finding.Finding) (*Message, error) {
+	msg, err := t.execute(f)
+	if err != nil {
+		return nil, err
+	}
+	msg.Finding = f
+	return msg, nil
+}
+
+func (t *templates) execute(data interface{}) (*Message, error) {
+	var title, text strings.Builder
+	if err := t.title.Execute(&title, data); err != nil {
+		return nil, err
+	}
+	if err := t.text.Execute(&text, data); err != nil {
+		return nil, err
+	}
+	return &Message{Title: strings.TrimSpace(title.String()), Text: strings.TrimSpace(text.String())}, nil
+}
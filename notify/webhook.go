@@ -0,0 +1,214 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+// postJSON 发送 JSON 请求，非 2xx 时返回包含响应内容的错误
+func postJSON(ctx context.Context, endpoint string, body interface{}, headers map[string]string) ([]byte, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	out, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return out, fmt.Errorf("http %d: %s", resp.StatusCode, bytes.TrimSpace(out))
+	}
+	return out, nil
+}
+
+// slack Slack incoming webhook
+type slack struct {
+	name string
+	url  string
+}
+
+func newSlack(name string, cfg map[string]interface{}) (Sink, error) {
+	s := &slack{name: name, url: cloud.String(cfg, "url")}
+	if s.url == "" {
+		return nil, errors.New("slack sink requires url")
+	}
+	return s, nil
+}
+
+func (s *slack) Name() string { return s.name }
+
+func (s *slack) Send(ctx context.Context, msg *Message) error {
+	_, err := postJSON(ctx, s.url, map[string]string{"text": "*" + msg.Title + "*\n" + msg.Text}, nil)
+	return err
+}
+
+// webhook 通用 HTTP webhook，配置 secret 时以 HMAC-SHA256(timestamp + "." + body) 签名
+type webhook struct {
+	name    string
+	url     string
+	secret  string
+	headers map[string]string
+}
+
+func newWebhook(name string, cfg map[string]interface{}) (Sink, error) {
+	w := &webhook{name: name, url: cloud.String(cfg, "url"), secret: cloud.String(cfg, "secret"), headers: cloud.Tags(cloud.AnyCase(cfg, "headers"))}
+	if w.url == "" {
+		return nil, errors.New("webhook sink requires url")
+	}
+	return w, nil
+}
+
+func (w *webhook) Name() string { return w.name }
+
+func (w *webhook) Send(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{}
+	for k, v := range w.headers {
+		headers[k] = v
+	}
+	if w.secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		headers["X-Signature-Timestamp"] = ts
+		headers["X-Signature"] = "sha256=" + Signature(w.secret, ts, body)
+	}
+	_, err = postJSON(ctx, w.url, json.RawMessage(body), headers)
+	return err
+}
+
+// Signature 计算 webhook 签名，接收方以同样方式校验
+func Signature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// feishu 飞书/Lark 自定义机器人，secret 为签名校验密钥
+type feishu struct {
+	name   string
+	url    string
+	secret string
+}
+
+func newFeishu(name string, cfg map[string]interface{}) (Sink, error) {
+	f := &feishu{name: name, url: cloud.String(cfg, "url"), secret: cloud.String(cfg, "secret")}
+	if f.url == "" {
+		return nil, errors.New("feishu sink requires url")
+	}
+	return f, nil
+}
+
+func (f *feishu) Name() string { return f.name }
+
+func (f *feishu) Send(ctx context.Context, msg *Message) error {
+	body := map[string]interface{}{
+		"msg_type": "text",
+		"content":  map[string]string{"text": msg.Title + "\n" + msg.Text},
+	}
+	if f.secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		body["timestamp"] = ts
+		body["sign"] = botSign(ts, f.secret)
+	}
+	out, err := postJSON(ctx, f.url, body, nil)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if json.Unmarshal(out, &resp) == nil && resp.Code != 0 {
+		return fmt.Errorf("feishu error %d: %s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+// dingTalk 钉钉自定义机器人，secret 为加签密钥
+type dingTalk struct {
+	name   string
+	url    string
+	secret string
+}
+
+func newDingTalk(name string, cfg map[string]interface{}) (Sink, error) {
+	d := &dingTalk{name: name, url: cloud.String(cfg, "url"), secret: cloud.String(cfg, "secret")}
+	if d.url == "" {
+		return nil, errors.New("dingtalk sink requires url")
+	}
+	return d, nil
+}
+
+func (d *dingTalk) Name() string { return d.name }
+
+func (d *dingTalk) Send(ctx context.Context, msg *Message) error {
+	endpoint := d.url
+	if d.secret != "" {
+		ts := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		u, err := url.Parse(d.url)
+		if err != nil {
+			return err
+		}
+		q := u.Query()
+		q.Set("timestamp", ts)
+		q.Set("sign", dingTalkSign(ts, d.secret))
+		u.RawQuery = q.Encode()
+		endpoint = u.String()
+	}
+	body := map[string]interface{}{
+		"msgtype":  "markdown",
+		"markdown": map[string]string{"title": msg.Title, "text": "### " + msg.Title + "\n\n" + msg.Text},
+	}
+	out, err := postJSON(ctx, endpoint, body, nil)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if json.Unmarshal(out, &resp) == nil && resp.ErrCode != 0 {
+		return fmt.Errorf("dingtalk error %d: %s", resp.ErrCode, resp.ErrMsg)
+	}
+	return nil
+}
+
+// botSign 飞书签名：以 timestamp + "\n" + secret 为密钥对空内容做 HMAC-SHA256
+func botSign(timestamp, secret string) string {
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// dingTalkSign 钉钉签名：以 secret 为密钥对 timestamp + "\n" + secret 做 HMAC-SHA256
+func dingTalkSign(timestamp, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
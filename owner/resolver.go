@@ -0,0 +1,231 @@
+package owner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// 归属来源
+const (
+	SourceTag     = "tag"
+	SourceRule    = "rule"
+	SourceVPC     = "vpc"
+	SourceAccount = "account"
+	SourceDefault = "default"
+)
+
+// Match 规则的匹配条件，未填写的条件不参与匹配；Tags 的值为 * 时只要求标签存在
+type Match struct {
+	Provider     string            `mapstructure:"provider"`
+	Account      string            `mapstructure:"account"`
+	Region       string            `mapstructure:"region"`
+	VpcID        string            `mapstructure:"vpc"`
+	ResourceType string            `mapstructure:"resource_type"`
+	NamePrefix   string            `mapstructure:"name_prefix"`
+	Tags         map[string]string `mapstructure:"tags"`
+}
+
+// Rule 按条件指定归属，按配置顺序取第一条命中的规则
+type Rule struct {
+	Name        string `mapstructure:"name"`
+	Match       Match  `mapstructure:"match"`
+	Team        string `mapstructure:"team"`
+	Owner       string `mapstructure:"owner"`
+	Service     string `mapstructure:"service"`
+	Environment string `mapstructure:"environment"`
+}
+
+// Contact 团队目录中的联系方式
+type Contact struct {
+	Owner    string `mapstructure:"owner"`
+	Email    string `mapstructure:"email"`
+	Slack    string `mapstructure:"slack"`
+	Assignee string `mapstructure:"assignee"`
+}
+
+// Resolver 依次按标签、规则、VPC 映射、账号映射与默认团队推出归属，
+// 每个字段取第一个给出值的来源，最后用团队目录补全负责人与联系方式
+type Resolver struct {
+	// TagKeys 字段 -> 依次查找的标签 key，大小写不敏感
+	TagKeys     map[string][]string
+	Rules       []Rule
+	VPCs        map[string]string
+	Accounts    map[string]string
+	Directory   map[string]Contact
+	DefaultTeam string
+}
+
+// NewResolverFromViper 读取 Ownership 配置段
+//
+//	Ownership:
+//	  tag_keys: {team: [team, squad], owner: [owner], service: [service, app], environment: [env]}
+//	  rules:
+//	    - {name: payments-prefix, match: {provider: aws, name_prefix: pay-}, team: payments, service: checkout}
+//	  vpcs: {vpc-0abc: platform}
+//	  accounts: {"123456789012": payments}
+//	  teams:
+//	    payments: {owner: alice, email: pay@example.com, slack: "#pay-oncall", assignee: 5b10ac8d82e05b22cc7d4ef5}
+//	  default_team: secops
+func NewResolverFromViper() (*Resolver, error) {
+	r := &Resolver{
+		TagKeys: map[string][]string{
+			"team":        {"team", "squad", "department"},
+			"owner":       {"owner", "maintainer", "contact"},
+			"service":     {"service", "app", "application", "project"},
+			"environment": {"env", "environment", "stage"},
+		},
+		VPCs:        lowerKeys(viper.GetStringMapString("Ownership.vpcs")),
+		Accounts:    lowerKeys(viper.GetStringMapString("Ownership.accounts")),
+		Directory:   map[string]Contact{},
+		DefaultTeam: viper.GetString("Ownership.default_team"),
+	}
+	for field, keys := range viper.GetStringMapStringSlice("Ownership.tag_keys") {
+		if _, ok := r.TagKeys[field]; !ok {
+			return nil, fmt.Errorf("unknown Ownership.tag_keys field %q", field)
+		}
+		r.TagKeys[field] = keys
+	}
+	if err := viper.UnmarshalKey("Ownership.rules", &r.Rules); err != nil {
+		return nil, fmt.Errorf("parse Ownership.rules: %w", err)
+	}
+	var teams map[string]Contact
+	if err := viper.UnmarshalKey("Ownership.teams", &teams); err != nil {
+		return nil, fmt.Errorf("parse Ownership.teams: %w", err)
+	}
+	for name, c := range teams {
+		r.Directory[strings.ToLower(name)] = c
+	}
+	return r, nil
+}
+
+// ResolveAssets 为每个资产写入 Asset.Owner，需在生成问题之前调用，问题会复制资产的归属
+func (r *Resolver) ResolveAssets(assets []*cloud.Asset) {
+	var unowned int
+	for _, a := range assets {
+		a.Owner = r.Resolve(a)
+		if a.Owner == nil {
+			unowned++
+		}
+	}
+	logx.Infof("ownership summary: assets=%d, unowned=%d", len(assets), unowned)
+}
+
+// ResolveFindings 为没有归属的问题（如不关联资产的安全组问题）按问题字段补全归属
+func (r *Resolver) ResolveFindings(items []*finding.Finding) {
+	for _, f := range items {
+		if f.Owner != nil {
+			continue
+		}
+		f.Owner = r.Resolve(&cloud.Asset{
+			Provider:     f.Provider,
+			Account:      f.Account,
+			InstanceID:   f.ResourceID,
+			InstanceName: f.ResourceName,
+			ResourceType: f.ResourceType,
+			Region:       f.Region,
+			VpcID:        f.VpcID,
+			Tags:         f.Tags,
+		})
+	}
+}
+
+// Resolve 推出单个资产的归属，任何来源都没有给出值时返回 nil
+func (r *Resolver) Resolve(a *cloud.Asset) *cloud.Ownership {
+	o := &cloud.Ownership{}
+	set := func(source, team, owner, service, env string) {
+		if o.Team == "" && team != "" {
+			o.Team, o.Source = team, source
+		}
+		o.Owner = cloud.FirstString(o.Owner, owner)
+		o.Service = cloud.FirstString(o.Service, service)
+		o.Environment = cloud.FirstString(o.Environment, env)
+	}
+
+	set(SourceTag, r.tag(a, "team"), r.tag(a, "owner"), r.tag(a, "service"), r.tag(a, "environment"))
+	for _, rule := range r.Rules {
+		if rule.Match.matches(a) {
+			set(SourceRule+":"+cloud.FirstString(rule.Name, rule.Team), rule.Team, rule.Owner, rule.Service, rule.Environment)
+			break
+		}
+	}
+	if a.VpcID != "" {
+		set(SourceVPC, r.VPCs[strings.ToLower(a.VpcID)], "", "", "")
+	}
+	if account := accountOf(a); account != "" {
+		set(SourceAccount, r.Accounts[strings.ToLower(account)], "", "", "")
+	}
+	set(SourceDefault, r.DefaultTeam, "", "", "")
+
+	if c, ok := r.Directory[strings.ToLower(o.Team)]; ok {
+		o.Owner = cloud.FirstString(o.Owner, c.Owner)
+		o.Email, o.Slack, o.Assignee = c.Email, c.Slack, c.Assignee
+	}
+	if *o == (cloud.Ownership{}) {
+		return nil
+	}
+	return o
+}
+
+// tag 按 TagKeys 顺序取第一个非空的标签值
+func (r *Resolver) tag(a *cloud.Asset, field string) string {
+	for _, key := range r.TagKeys[field] {
+		for k, v := range a.Tags {
+			if strings.EqualFold(k, key) && v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+func (m Match) matches(a *cloud.Asset) bool {
+	if m.Provider != "" && !strings.EqualFold(m.Provider, a.Provider) {
+		return false
+	}
+	if m.Account != "" && m.Account != accountOf(a) {
+		return false
+	}
+	if m.Region != "" && !strings.EqualFold(m.Region, a.Region) {
+		return false
+	}
+	if m.VpcID != "" && !strings.EqualFold(m.VpcID, a.VpcID) {
+		return false
+	}
+	if m.ResourceType != "" && !strings.EqualFold(m.ResourceType, a.ResourceType) {
+		return false
+	}
+	if m.NamePrefix != "" && !strings.HasPrefix(strings.ToLower(a.InstanceName), strings.ToLower(m.NamePrefix)) {
+		return false
+	}
+	for key, want := range m.Tags {
+		var found bool
+		for k, v := range a.Tags {
+			if strings.EqualFold(k, key) && (want == "*" || strings.EqualFold(v, want)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func accountOf(a *cloud.Asset) string {
+	return cloud.FirstString(a.Account, cloud.AccountOf(a.InstanceID))
+}
+
+// lowerKeys viper 会把配置中的 key 转为小写，这里统一按小写查找
+func lowerKeys(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[strings.ToLower(k)] = v
+	}
+	return out
+}
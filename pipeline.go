@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/alert"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+	"github.com/xid-protocol/attack-surface/notify"
+	"github.com/xid-protocol/attack-surface/owner"
+	"github.com/xid-protocol/attack-surface/policy"
+	"github.com/xid-protocol/attack-surface/waiver"
+)
+
+// pipeline 问题产生之后的发布流程：归属、豁免、告警生命周期、通知与查询快照。
+// 策略热加载时用新的策略违规替换上一次的结果并重新发布，mu 保证同一时间只有一次发布
+type pipeline struct {
+	mu        sync.Mutex
+	inventory []*cloud.Asset
+	// base 策略之外的全部问题，热加载时保持不变
+	base     []*finding.Finding
+	owners   *owner.Resolver
+	coverage alert.Coverage
+	tracker  *alert.Tracker
+	notifier *notify.Notifier
+}
+
+// publish 合并策略违规后发布，返回本次的全部问题
+func (p *pipeline) publish(violations []*finding.Finding) []*finding.Finding {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.publishLocked(violations)
+}
+
+// reload 策略文件变化后重新求值并发布
+func (p *pipeline) reload(engine *policy.Engine) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	findings := p.publishLocked(engine.Evaluate(p.inventory))
+	logx.Infof("policy reloaded, findings republished: %d", len(findings))
+}
+
+func (p *pipeline) publishLocked(violations []*finding.Finding) []*finding.Finding {
+	// 每次发布使用副本，避免修改查询接口正在读取的上一次结果
+	findings := make([]*finding.Finding, 0, len(p.base)+len(violations))
+	for _, f := range append(append([]*finding.Finding{}, p.base...), violations...) {
+		c := *f
+		findings = append(findings, &c)
+	}
+	if p.owners != nil {
+		p.owners.ResolveFindings(findings)
+	}
+	// 豁免读取失败时已豁免的问题会被当作新问题告警，本次跳过生命周期与通知，由下次发布补上
+	if err := applyWaivers(findings); err != nil {
+		logx.Errorf("load waivers error, skip alert lifecycle and notification: %v", err)
+	} else {
+		logx.Infof("findings: %d, actionable: %d", len(finding.XIDs(findings)), len(finding.Actionable(findings)))
+		p.alert(findings)
+	}
+	snapshot.Set(p.inventory, findings)
+	return findings
+}
+
+// Acknowledge 人工确认问题，并将状态变化发送给通知渠道（如工单评论）
+func (p *pipeline) Acknowledge(id, by, note string) (*finding.Finding, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f, err := p.tracker.Acknowledge(id, by, note)
+	if err != nil {
+		return nil, err
+	}
+	logx.Infof("alert %s acknowledged by %s", id, by)
+	if p.notifier != nil {
+		p.notifier.Notify(context.Background(), []*finding.Finding{f})
+	}
+	return f, nil
+}
+
+// applyWaivers 豁免在全部问题产生之后应用，已豁免的问题保留记录但不再告警；每次发布重新读取豁免
+func applyWaivers(findings []*finding.Finding) error {
+	if !viper.GetBool("Waiver.enabled") {
+		return nil
+	}
+	waivers, err := waiver.NewStoreFromViper().List()
+	if err != nil {
+		return err
+	}
+	waiver.Apply(findings, waivers, time.Now())
+	return nil
+}
+
+// alert 生命周期跟踪开启时只通知状态变化（新增、重新出现、已解决），避免定时扫描重复告警。
+// 生命周期更新失败时不通知，否则全部问题会被重新发送；未发送的变化由发件箱与下次扫描补上
+func (p *pipeline) alert(findings []*finding.Finding) {
+	alerts := findings
+	if p.tracker != nil {
+		changed, err := p.tracker.Reconcile(findings, p.coverage, time.Now())
+		if err != nil {
+			logx.Errorf("reconcile alert lifecycle error, skip notification: %v", err)
+			return
+		}
+		alerts = changed
+	}
+	if p.notifier != nil {
+		p.notifier.Notify(context.Background(), alerts)
+	}
+}
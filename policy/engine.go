@@ -0,0 +1,333 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cel.dev/cel-go/cel"
+	"cel.dev/cel-go/common/types"
+	"cel.dev/cel-go/common/types/ref"
+	"cel.dev/cel-go/ext"
+	"github.com/colin-404/logx"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// 文件变化后等待该时间再重新加载，合并编辑器的多次写入
+const reloadDelay = 500 * time.Millisecond
+
+// Engine 从配置的文件加载策略并对资产模型求值，支持热加载
+type Engine struct {
+	// Patterns 文件、目录或 glob，目录下加载全部 .yaml/.yml
+	Patterns []string
+
+	env      *cel.Env
+	mu       sync.RWMutex
+	policies []*compiled
+}
+
+// NewEngineFromViper 读取 Policy.files 并加载策略
+func NewEngineFromViper() (*Engine, error) {
+	return NewEngine(viper.GetStringSlice("Policy.files"))
+}
+
+// NewEngine 创建引擎并立即加载一次，加载失败时返回错误
+func NewEngine(patterns []string) (*Engine, error) {
+	env, err := newEnv()
+	if err != nil {
+		return nil, err
+	}
+	e := &Engine{Patterns: patterns, env: env}
+	if err := e.Load(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// newEnv 表达式可用变量 asset、rule、vars，以及 cidrCovers、coversPort 和 CEL 网络、字符串、集合扩展
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("asset", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("rule", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("vars", cel.MapType(cel.StringType, cel.DynType)),
+		cel.OptionalTypes(),
+		ext.Strings(),
+		ext.Sets(),
+		ext.Lists(),
+		ext.Network(),
+		cel.Function("cidrCovers",
+			cel.Overload("cidr_covers_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(func(outer, inner ref.Val) ref.Val {
+					o, ok1 := outer.Value().(string)
+					i, ok2 := inner.Value().(string)
+					if !ok1 || !ok2 {
+						return types.NewErr("cidrCovers expects strings")
+					}
+					return types.Bool(cloud.CIDRCovers(o, i))
+				}))),
+		cel.Function("coversPort",
+			cel.Overload("covers_port_map_int", []*cel.Type{cel.MapType(cel.StringType, cel.DynType), cel.IntType}, cel.BoolType,
+				cel.BinaryBinding(func(rule, port ref.Val) ref.Val {
+					m, ok := rule.Value().(map[string]interface{})
+					p, ok2 := port.Value().(int64)
+					if !ok || !ok2 {
+						return types.NewErr("coversPort expects (rule, int)")
+					}
+					return types.Bool(ruleOf(m).Covers(int(p)))
+				}))),
+	)
+}
+
+// Load 重新解析全部策略文件，任一文件有误时保留当前策略并返回错误
+func (e *Engine) Load() error {
+	files, err := e.files()
+	if err != nil {
+		return err
+	}
+	var all []*compiled
+	seen := map[string]string{}
+	for _, f := range files {
+		policies, err := loadFile(e.env, f)
+		if err != nil {
+			return err
+		}
+		for _, p := range policies {
+			if prev, ok := seen[p.ID]; ok {
+				return fmt.Errorf("duplicate policy id %s in %s and %s", p.ID, prev, f)
+			}
+			seen[p.ID] = f
+		}
+		all = append(all, policies...)
+	}
+	e.mu.Lock()
+	e.policies = all
+	e.mu.Unlock()
+	logx.Infof("policy loaded: files=%d, policies=%d", len(files), len(all))
+	return nil
+}
+
+// files 展开配置的文件、目录与 glob，结果去重排序
+func (e *Engine) files() ([]string, error) {
+	var out []string
+	for _, pattern := range e.Patterns {
+		if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+			entries, err := os.ReadDir(pattern)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range entries {
+				if !entry.IsDir() && isPolicyFile(entry.Name()) {
+					out = cloud.AppendUnique(out, filepath.Join(pattern, entry.Name()))
+				}
+			}
+			continue
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("policy pattern %s: %w", pattern, err)
+		}
+		if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			return nil, fmt.Errorf("policy file %s not found", pattern)
+		}
+		for _, m := range matches {
+			out = cloud.AppendUnique(out, m)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func isPolicyFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// Watch 监听策略文件所在目录，变化后重新加载并回调 onReload，直到 ctx 结束
+func (e *Engine) Watch(ctx context.Context, onReload func()) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	dirs := map[string]bool{}
+	for _, pattern := range e.Patterns {
+		dir := pattern
+		if info, err := os.Stat(pattern); err != nil || !info.IsDir() {
+			dir = filepath.Dir(pattern)
+		}
+		if !dirs[dir] {
+			dirs[dir] = true
+			if err := w.Add(dir); err != nil {
+				return fmt.Errorf("watch %s: %w", dir, err)
+			}
+		}
+	}
+
+	var timer <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if isPolicyFile(ev.Name) && ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+				timer = time.After(reloadDelay)
+			}
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			logx.Errorf("policy watcher error: %v", err)
+		case <-timer:
+			timer = nil
+			if err := e.Load(); err != nil {
+				logx.Errorf("reload policy error, keep previous policies: %v", err)
+				continue
+			}
+			if onReload != nil {
+				onReload()
+			}
+		}
+	}
+}
+
+// Evaluate 对全部资产求值，返回违规；表达式求值出错时记录日志并视为未命中
+func (e *Engine) Evaluate(assets []*cloud.Asset) []*finding.Finding {
+	e.mu.RLock()
+	policies := e.policies
+	e.mu.RUnlock()
+
+	var out []*finding.Finding
+	for _, a := range assets {
+		am := toMap(a)
+		for _, p := range policies {
+			if p.Target == TargetAsset {
+				if p.violates(am, map[string]interface{}{}) {
+					out = append(out, p.finding(a, a.InstanceID, nil))
+				}
+				continue
+			}
+			for i, r := range a.Rules {
+				rm, _ := objectAt(am["rules"], i)
+				if rm == nil || !p.violates(am, rm) {
+					continue
+				}
+				out = append(out, p.finding(a, ruleTarget(r), &r))
+			}
+		}
+	}
+	finding.Sort(out)
+	logx.Infof("policy summary: policies=%d, violations=%d", len(policies), len(out))
+	return out
+}
+
+func (p *compiled) violates(asset, rule map[string]interface{}) bool {
+	vars := map[string]interface{}{"asset": asset, "rule": rule, "vars": p.vars}
+	if !p.eval(p.match, "match", vars) {
+		return false
+	}
+	return p.allow == nil || !p.eval(p.allow, "allow", vars)
+}
+
+func (p *compiled) eval(prg cel.Program, name string, vars map[string]interface{}) bool {
+	val, _, err := prg.Eval(vars)
+	if err != nil {
+		logx.Debugf("policy %s %s eval error: %v", p.ID, name, err)
+		return false
+	}
+	b, ok := val.Value().(bool)
+	return ok && b
+}
+
+func (p *compiled) finding(a *cloud.Asset, target string, r *cloud.Rule) *finding.Finding {
+	title := cloud.FirstString(p.Title, p.ID)
+	f := finding.New(p.ID, p.severity, a, target, title, p.Description)
+	f.Evidence["policyFile"] = p.file
+	f.Evidence["match"] = p.Match
+	if r != nil {
+		f.GroupID, f.FromPort, f.ToPort = r.GroupID, r.FromPort, r.ToPort
+		f.Sources = r.Sources()
+		f.Evidence["exposure"] = string(r.Exposure)
+	}
+	return f
+}
+
+// ruleTarget 规则的描述，如 sg-123 tcp/22-22
+func ruleTarget(r cloud.Rule) string {
+	return fmt.Sprintf("%s %s/%d-%d", r.GroupID, r.Protocol, r.FromPort, r.ToPort)
+}
+
+// toMap 以 JSON 字段名把资产转换为表达式可用的 map
+func toMap(a *cloud.Asset) map[string]interface{} {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return map[string]interface{}{}
+	}
+	m, _ := normalize(v).(map[string]interface{})
+	return m
+}
+
+// objectAt 取数组中第 i 个对象
+func objectAt(v interface{}, i int) (map[string]interface{}, bool) {
+	arr, ok := v.([]interface{})
+	if !ok || i >= len(arr) {
+		return nil, false
+	}
+	m, ok := arr[i].(map[string]interface{})
+	return m, ok
+}
+
+// normalize 整数值的 float64 转为 int64，使表达式可以直接与整数比较
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, item := range t {
+			t[k] = normalize(item)
+		}
+		return t
+	case []interface{}:
+		for i, item := range t {
+			t[i] = normalize(item)
+		}
+		return t
+	case float64:
+		if t == math.Trunc(t) && math.Abs(t) < 1<<53 {
+			return int64(t)
+		}
+		return t
+	case int:
+		return int64(t)
+	default:
+		return v
+	}
+}
+
+// ruleOf 从表达式中的 rule map 还原端口与协议
+func ruleOf(m map[string]interface{}) cloud.Rule {
+	r := cloud.Rule{}
+	r.Protocol, _ = m["protocol"].(string)
+	if v, ok := m["fromPort"].(int64); ok {
+		r.FromPort = int(v)
+	}
+	if v, ok := m["toPort"].(int64); ok {
+		r.ToPort = int(v)
+	}
+	return r
+}
@@ -0,0 +1,116 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"cel.dev/cel-go/cel"
+	"gopkg.in/yaml.v3"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// 策略的评估对象
+const (
+	TargetAsset = "asset"
+	TargetRule  = "rule"
+)
+
+// File 策略文件，vars 为文件内策略共享的变量（如办公网段）
+//
+//	vars:
+//	  office: ["203.0.113.0/24"]
+//	policies:
+//	  - id: public-ssh
+//	    severity: high
+//	    title: SSH exposed outside office network
+//	    match: 'rule.exposure != "private" && coversPort(rule, 22)'
+//	    allow: 'asset.tags[?"role"].orValue("") == "bastion" && rule.iprange.all(c, vars.office.exists(o, cidrCovers(o, c)))'
+type File struct {
+	Vars     map[string]interface{} `yaml:"vars"`
+	Policies []Policy               `yaml:"policies"`
+}
+
+// Policy 单条策略，match 命中且 allow 不成立时产生违规
+type Policy struct {
+	ID          string `yaml:"id"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Severity    string `yaml:"severity"`
+	// Target asset 对每个资产评估一次，rule（默认）对资产的每条入方向规则评估
+	Target  string `yaml:"target"`
+	Match   string `yaml:"match"`
+	Allow   string `yaml:"allow"`
+	Enabled *bool  `yaml:"enabled"`
+}
+
+// compiled 编译后的策略
+type compiled struct {
+	Policy
+	file     string
+	severity finding.Severity
+	vars     map[string]interface{}
+	match    cel.Program
+	allow    cel.Program
+}
+
+// loadFile 解析并编译单个策略文件，任意一条策略有误时整个文件加载失败
+func loadFile(env *cel.Env, path string) ([]*compiled, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	vars, _ := normalize(f.Vars).(map[string]interface{})
+	if vars == nil {
+		vars = map[string]interface{}{}
+	}
+	var out []*compiled
+	for i, p := range f.Policies {
+		if p.Enabled != nil && !*p.Enabled {
+			continue
+		}
+		if p.ID == "" {
+			return nil, fmt.Errorf("%s: policies[%d] missing id", path, i)
+		}
+		c := &compiled{Policy: p, file: path, vars: vars}
+		c.Target = strings.ToLower(cloud.FirstString(p.Target, TargetRule))
+		if c.Target != TargetAsset && c.Target != TargetRule {
+			return nil, fmt.Errorf("%s: policy %s: unknown target %q", path, p.ID, p.Target)
+		}
+		c.severity = finding.ParseSeverity(p.Severity)
+		if c.severity == "" {
+			c.severity = finding.SeverityMedium
+		}
+		if p.Match == "" {
+			return nil, fmt.Errorf("%s: policy %s missing match", path, p.ID)
+		}
+		if c.match, err = compile(env, p.Match); err != nil {
+			return nil, fmt.Errorf("%s: policy %s match: %w", path, p.ID, err)
+		}
+		if p.Allow != "" {
+			if c.allow, err = compile(env, p.Allow); err != nil {
+				return nil, fmt.Errorf("%s: policy %s allow: %w", path, p.ID, err)
+			}
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// compile 编译表达式并要求结果为 bool
+func compile(env *cel.Env, expr string) (cel.Program, error) {
+	ast, iss := env.Compile(expr)
+	if iss != nil && iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression must return bool, got %s", ast.OutputType())
+	}
+	return env.Program(ast)
+}
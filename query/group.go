@@ -0,0 +1,122 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// 没有取值时的分组名
+const noValue = "(none)"
+
+// Group 分组汇总，MaxRisk 为组内资产（或问题关联资产）的最高评分
+type Group struct {
+	Key         string         `json:"key"`
+	Assets      int            `json:"assets"`
+	Findings    int            `json:"findings"`
+	MaxRisk     int            `json:"maxRisk"`
+	MaxSeverity string         `json:"maxSeverity,omitempty"`
+	Severities  map[string]int `json:"severities,omitempty"`
+}
+
+// Result 过滤与分组的结果
+type Result struct {
+	Filter   string             `json:"filter,omitempty"`
+	GroupBy  string             `json:"groupBy,omitempty"`
+	Assets   []*cloud.Asset     `json:"assets"`
+	Findings []*finding.Finding `json:"findings"`
+	Groups   []*Group           `json:"groups,omitempty"`
+}
+
+// ValidGroupBy 校验分组字段：除 port、ip、risk、public 外的过滤字段，或 tag:key
+func ValidGroupBy(by string) error {
+	if by == "" {
+		return nil
+	}
+	if key, ok := strings.CutPrefix(by, "tag:"); ok && key != "" {
+		return nil
+	}
+	if kind, ok := fields[by]; ok && (kind == kindString || kind == kindOrdinal) {
+		return nil
+	}
+	return fmt.Errorf("cannot group by %q", by)
+}
+
+// Run 过滤资产与问题，by 不为空时按字段分组，分组按最高评分、问题数降序排列
+func Run(f *Filter, by string, assets []*cloud.Asset, findings []*finding.Finding) (*Result, error) {
+	if err := ValidGroupBy(by); err != nil {
+		return nil, err
+	}
+	res := &Result{Filter: f.String(), GroupBy: by, Assets: []*cloud.Asset{}, Findings: []*finding.Finding{}}
+	groups := map[string]*Group{}
+	group := func(r *Record) *Group {
+		key := r.groupKey(by)
+		g, ok := groups[key]
+		if !ok {
+			g = &Group{Key: key, Severities: map[string]int{}}
+			groups[key] = g
+		}
+		g.MaxRisk = max(g.MaxRisk, r.Risk)
+		return g
+	}
+
+	for _, a := range assets {
+		r := FromAsset(a)
+		if !f.Match(r) {
+			continue
+		}
+		res.Assets = append(res.Assets, a)
+		if by != "" {
+			group(r).Assets++
+		}
+	}
+	index := IndexAssets(assets)
+	for _, item := range findings {
+		r := FromFinding(item, index)
+		if !f.Match(r) {
+			continue
+		}
+		res.Findings = append(res.Findings, item)
+		if by != "" {
+			g := group(r)
+			g.Findings++
+			g.Severities[r.Severity]++
+			if rankOf("severity", r.Severity) > rankOf("severity", g.MaxSeverity) {
+				g.MaxSeverity = r.Severity
+			}
+		}
+	}
+
+	for _, g := range groups {
+		res.Groups = append(res.Groups, g)
+	}
+	sort.Slice(res.Groups, func(i, j int) bool {
+		a, b := res.Groups[i], res.Groups[j]
+		if a.MaxRisk != b.MaxRisk {
+			return a.MaxRisk > b.MaxRisk
+		}
+		if a.Findings != b.Findings {
+			return a.Findings > b.Findings
+		}
+		return a.Key < b.Key
+	})
+	return res, nil
+}
+
+func (r *Record) groupKey(by string) string {
+	var v string
+	if key, ok := strings.CutPrefix(by, "tag:"); ok {
+		v, _ = r.tag(key)
+	} else if by == "owner" {
+		v = cloud.FirstString(r.Owner, r.Team)
+	} else if values := r.text(by); len(values) > 0 {
+		v = values[0]
+	}
+	if v == "" {
+		return noValue
+	}
+	return v
+}
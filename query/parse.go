@@ -0,0 +1,316 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Filter 编译后的过滤表达式，语法：
+//
+//	tag:env=prod AND (port:22 OR port:3389) AND NOT team:sandbox
+//
+// 条件形如 field:value，value 可带比较符（>=、<=、>、<、!=、=），字符串支持 * 通配并且大小写不敏感；
+// tag:key=value 比较标签值，tag:key 只要求标签存在。相邻条件之间省略 AND 时按 AND 处理。
+// 空表达式匹配全部记录。
+type Filter struct {
+	expr string
+	root node
+}
+
+// Parse 编译过滤表达式
+func Parse(expr string) (*Filter, error) {
+	f := &Filter{expr: strings.TrimSpace(expr)}
+	if f.expr == "" {
+		return f, nil
+	}
+	tokens, err := tokenize(f.expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if f.root, err = p.or(); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q at token %d", p.tokens[p.pos], p.pos+1)
+	}
+	return f, nil
+}
+
+// MustParse 用于常量表达式，解析失败时 panic
+func MustParse(expr string) *Filter {
+	f, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func (f *Filter) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.expr
+}
+
+// Match 判断记录是否满足表达式
+func (f *Filter) Match(r *Record) bool {
+	if f == nil || f.root == nil {
+		return true
+	}
+	return f.root.eval(r)
+}
+
+type node interface {
+	eval(r *Record) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(r *Record) bool { return n.left.eval(r) && n.right.eval(r) }
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(r *Record) bool { return n.left.eval(r) || n.right.eval(r) }
+
+type notNode struct{ inner node }
+
+func (n notNode) eval(r *Record) bool { return !n.inner.eval(r) }
+
+// tokenize 按空白与括号切分，双引号内的内容保持完整
+func tokenize(s string) ([]string, error) {
+	var out []string
+	var cur strings.Builder
+	quoted := false
+	flush := func() {
+		if cur.Len() > 0 {
+			out = append(out, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, c := range s {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+			cur.WriteRune(c)
+		case unicode.IsSpace(c):
+			flush()
+		case c == '(' || c == ')':
+			flush()
+			out = append(out, string(c))
+		default:
+			cur.WriteRune(c)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	flush()
+	return out, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) keyword(words ...string) bool {
+	t := p.peek()
+	for _, w := range words {
+		if strings.EqualFold(t, w) {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR", "||") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t == "" || t == ")" || strings.EqualFold(t, "OR") || t == "||" {
+			return left, nil
+		}
+		p.keyword("AND", "&&")
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+func (p *parser) not() (node, error) {
+	if p.keyword("NOT", "!") {
+		inner, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.peek()
+	switch {
+	case t == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case t == "(":
+		p.pos++
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return n, nil
+	case t == ")":
+		return nil, fmt.Errorf("unexpected )")
+	}
+	p.pos++
+	return parseTerm(t)
+}
+
+// 比较符，按长度优先匹配
+var operators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// term 单个条件
+type term struct {
+	field string
+	tag   string // tag 条件的 key
+	op    string
+	value string
+	glob  *regexp.Regexp
+	num   int
+	upper int // 端口范围上界
+}
+
+func parseTerm(tok string) (node, error) {
+	field, rest, ok := strings.Cut(tok, ":")
+	if !ok || field == "" {
+		return nil, fmt.Errorf("invalid condition %q, expected field:value", tok)
+	}
+	t := &term{field: strings.ToLower(field)}
+	if t.field == "tag" {
+		key, value, found := strings.Cut(rest, "=")
+		t.op = "exists"
+		if found {
+			t.op = "="
+			if strings.HasSuffix(key, "!") {
+				key, t.op = strings.TrimSuffix(key, "!"), "!="
+			}
+		}
+		if key == "" {
+			return nil, fmt.Errorf("invalid condition %q, expected tag:key[=value]", tok)
+		}
+		t.tag = key
+		t.value = value
+		t.glob = globOf(value)
+		return t, nil
+	}
+	kind, ok := fields[t.field]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q, supported: %s", field, strings.Join(FieldNames(), ", "))
+	}
+	t.op = "="
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			t.op, rest = op, rest[len(op):]
+			break
+		}
+	}
+	if rest == "" {
+		return nil, fmt.Errorf("missing value in %q", tok)
+	}
+	t.value = rest
+	switch kind {
+	case kindString:
+		if t.op != "=" && t.op != "!=" {
+			return nil, fmt.Errorf("%s only supports = and !=", t.field)
+		}
+		t.glob = globOf(rest)
+	case kindNumber:
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects a number, got %q", t.field, rest)
+		}
+		t.num = n
+	case kindPort:
+		if t.op != "=" && t.op != "!=" {
+			return nil, fmt.Errorf("port only supports = and !=")
+		}
+		from, to, isRange := strings.Cut(rest, "-")
+		var err error
+		if t.num, err = strconv.Atoi(from); err != nil {
+			return nil, fmt.Errorf("invalid port %q", rest)
+		}
+		t.upper = t.num
+		if isRange {
+			if t.upper, err = strconv.Atoi(to); err != nil || t.upper < t.num {
+				return nil, fmt.Errorf("invalid port range %q", rest)
+			}
+		}
+	case kindOrdinal:
+		if t.num = rankOf(t.field, rest); t.num < 0 {
+			return nil, fmt.Errorf("unknown %s %q", t.field, rest)
+		}
+	case kindBool:
+		b, err := strconv.ParseBool(rest)
+		if err != nil || (t.op != "=" && t.op != "!=") {
+			return nil, fmt.Errorf("%s expects true or false", t.field)
+		}
+		t.num = 0
+		if b {
+			t.num = 1
+		}
+	case kindAddress:
+		if t.op != "=" && t.op != "!=" {
+			return nil, fmt.Errorf("ip only supports = and !=")
+		}
+		if _, err := parsePrefix(rest); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// globOf 把含 * 的值编译为大小写不敏感的正则，不含通配时返回 nil
+func globOf(s string) *regexp.Regexp {
+	if !strings.Contains(s, "*") {
+		return nil
+	}
+	return regexp.MustCompile("(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(s), `\*`, ".*") + "$")
+}
@@ -0,0 +1,331 @@
+package query
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// Record 过滤与分组使用的统一视图，资产、问题以及各导出报表的行都转换为 Record 求值
+type Record struct {
+	Provider     string
+	Account      string
+	Region       string
+	VpcID        string
+	ID           string
+	Name         string
+	ResourceType string
+	Team         string
+	Owner        string
+	Service      string
+	Environment  string
+	// Severity 问题的严重程度，资产为风险等级
+	Severity string
+	Rule     string
+	Status   string
+	Exposure string
+	Risk     int
+	Public   bool
+	Tags     map[string]string
+	// Ports 对外放行的端口范围
+	Ports [][2]int
+	IPs   []string
+}
+
+// FromAsset 资产视图，端口为有效放行的范围：有可达性分析时取可达的规则，否则取公网资产的全部规则
+func FromAsset(a *cloud.Asset) *Record {
+	r := &Record{
+		Provider:     a.Provider,
+		Account:      cloud.FirstString(a.Account, cloud.AccountOf(a.InstanceID)),
+		Region:       a.Region,
+		VpcID:        a.VpcID,
+		ID:           a.InstanceID,
+		Name:         a.InstanceName,
+		ResourceType: a.ResourceType,
+		Exposure:     string(a.Exposure),
+		Public:       a.Public,
+		Tags:         a.Tags,
+	}
+	r.setOwner(a.Owner)
+	if a.Risk != nil {
+		r.Risk, r.Severity = a.Risk.Score, a.Risk.Level
+	}
+	if a.Reachability != nil {
+		for _, reach := range a.Reachability {
+			if reach.Reachable {
+				r.Ports = append(r.Ports, portRange(reach.FromPort, reach.ToPort))
+			}
+		}
+	} else if a.Public {
+		for _, rule := range a.Rules {
+			if rule.Exposure != cloud.ExposureNone {
+				r.Ports = append(r.Ports, portRange(rule.FromPort, rule.ToPort))
+			}
+		}
+	}
+	r.IPs = append(append(append(r.IPs, a.PublicIPs...), a.PrivateIPs...), a.IPv6s...)
+	return r
+}
+
+// FromFinding 问题视图，risk 为关联资产的评分，调用方通过 assets 传入（可为空）
+func FromFinding(f *finding.Finding, assets map[string]*cloud.Asset) *Record {
+	r := &Record{
+		Provider:     f.Provider,
+		Account:      cloud.FirstString(f.Account, cloud.AccountOf(f.ResourceID)),
+		Region:       f.Region,
+		ID:           f.ResourceID,
+		Name:         f.ResourceName,
+		ResourceType: f.ResourceType,
+		Severity:     string(f.Severity),
+		Rule:         f.Rule,
+		Status:       string(f.Status),
+		Tags:         f.Tags,
+	}
+	if f.State != "" {
+		r.Status = string(f.State)
+	}
+	r.setOwner(f.Owner)
+	if f.FromPort > 0 || f.ToPort > 0 {
+		r.Ports = append(r.Ports, portRange(f.FromPort, f.ToPort))
+	}
+	if a, ok := assets[AssetKey(f.Provider, f.ResourceID)]; ok {
+		r.VpcID = a.VpcID
+		r.Public = a.Public
+		r.Exposure = string(a.Exposure)
+		r.IPs = append(append(r.IPs, a.PublicIPs...), a.PrivateIPs...)
+		if a.Risk != nil {
+			r.Risk = a.Risk.Score
+		}
+	}
+	return r
+}
+
+// AssetKey 按厂商与资源 ID 关联问题与资产
+func AssetKey(provider, id string) string {
+	return provider + "|" + id
+}
+
+// IndexAssets 建立 AssetKey -> 资产 的索引
+func IndexAssets(assets []*cloud.Asset) map[string]*cloud.Asset {
+	out := make(map[string]*cloud.Asset, len(assets))
+	for _, a := range assets {
+		out[AssetKey(a.Provider, a.InstanceID)] = a
+	}
+	return out
+}
+
+func (r *Record) setOwner(o *cloud.Ownership) {
+	if o == nil {
+		return
+	}
+	r.Team, r.Owner, r.Service, r.Environment = o.Team, o.Owner, o.Service, o.Environment
+}
+
+// portRange 0-0 或 -1 表示全部端口
+func portRange(from, to int) [2]int {
+	if from <= 0 && to <= 0 {
+		return [2]int{0, 65535}
+	}
+	if to < from {
+		to = from
+	}
+	return [2]int{from, to}
+}
+
+// 字段类型
+const (
+	kindString = iota
+	kindNumber
+	kindPort
+	kindOrdinal
+	kindBool
+	kindAddress
+)
+
+var fields = map[string]int{
+	"provider": kindString,
+	"account":  kindString,
+	"region":   kindString,
+	"vpc":      kindString,
+	"id":       kindString,
+	"name":     kindString,
+	"type":     kindString,
+	"team":     kindString,
+	"owner":    kindString,
+	"service":  kindString,
+	"env":      kindString,
+	"rule":     kindString,
+	"status":   kindString,
+	"severity": kindOrdinal,
+	"exposure": kindOrdinal,
+	"risk":     kindNumber,
+	"port":     kindPort,
+	"public":   kindBool,
+	"ip":       kindAddress,
+}
+
+// FieldNames 支持的过滤字段
+func FieldNames() []string {
+	out := []string{"tag"}
+	for k := range fields {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+var ordinals = map[string][]string{
+	"severity": {"info", "low", "medium", "high", "critical"},
+	"exposure": {"none", "private", "restricted", "broad", "internet"},
+}
+
+func rankOf(field, value string) int {
+	for i, v := range ordinals[field] {
+		if strings.EqualFold(v, value) {
+			return i
+		}
+	}
+	return -1
+}
+
+// text 字符串字段的取值；owner 同时匹配团队与负责人
+func (r *Record) text(field string) []string {
+	switch field {
+	case "provider":
+		return []string{r.Provider}
+	case "account":
+		return []string{r.Account}
+	case "region":
+		return []string{r.Region}
+	case "vpc":
+		return []string{r.VpcID}
+	case "id":
+		return []string{r.ID}
+	case "name":
+		return []string{r.Name}
+	case "type":
+		return []string{r.ResourceType}
+	case "team":
+		return []string{r.Team}
+	case "owner":
+		return []string{r.Team, r.Owner}
+	case "service":
+		return []string{r.Service}
+	case "env":
+		return []string{r.Environment}
+	case "rule":
+		return []string{r.Rule}
+	case "status":
+		return []string{r.Status}
+	case "severity":
+		return []string{r.Severity}
+	case "exposure":
+		return []string{r.Exposure}
+	}
+	return nil
+}
+
+// tag 标签 key 大小写不敏感
+func (r *Record) tag(key string) (string, bool) {
+	if v, ok := r.Tags[key]; ok {
+		return v, true
+	}
+	for k, v := range r.Tags {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func (t *term) eval(r *Record) bool {
+	if t.field == "tag" {
+		v, ok := r.tag(t.tag)
+		switch t.op {
+		case "exists":
+			return ok
+		case "!=":
+			return !ok || !t.matchText(v)
+		default:
+			return ok && t.matchText(v)
+		}
+	}
+	switch fields[t.field] {
+	case kindString:
+		var hit bool
+		for _, v := range r.text(t.field) {
+			if v != "" && t.matchText(v) {
+				hit = true
+				break
+			}
+		}
+		return hit == (t.op == "=")
+	case kindNumber:
+		return compare(r.Risk, t.op, t.num)
+	case kindOrdinal:
+		rank := rankOf(t.field, r.text(t.field)[0])
+		return rank >= 0 && compare(rank, t.op, t.num)
+	case kindPort:
+		var hit bool
+		for _, p := range r.Ports {
+			if p[0] <= t.upper && t.num <= p[1] {
+				hit = true
+				break
+			}
+		}
+		return hit == (t.op == "=")
+	case kindBool:
+		return (r.Public == (t.num == 1)) == (t.op == "=")
+	case kindAddress:
+		prefix, _ := parsePrefix(t.value)
+		var hit bool
+		for _, ip := range r.IPs {
+			if addr, err := netip.ParseAddr(ip); err == nil && prefix.Contains(addr) {
+				hit = true
+				break
+			}
+		}
+		return hit == (t.op == "=")
+	}
+	return false
+}
+
+func (t *term) matchText(v string) bool {
+	if t.glob != nil {
+		return t.glob.MatchString(v)
+	}
+	return strings.EqualFold(v, t.value)
+}
+
+func compare(a int, op string, b int) bool {
+	switch op {
+	case ">=":
+		return a >= b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case "<":
+		return a < b
+	case "!=":
+		return a != b
+	default:
+		return a == b
+	}
+}
+
+// parsePrefix 单个地址视为 /32 或 /128
+func parsePrefix(s string) (netip.Prefix, error) {
+	if p, err := netip.ParsePrefix(s); err == nil {
+		return p.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid ip or cidr %q", s)
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -0,0 +1,193 @@
+package risk
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// Entry 端口/协议到服务与基础风险的映射，Service 与服务识别结果的名称一致
+type Entry struct {
+	Protocol string
+	FromPort int
+	ToPort   int
+	Service  string
+	Risk     finding.Severity
+}
+
+// Catalog 按顺序匹配，配置中的条目排在内置条目之前
+type Catalog struct {
+	entries []Entry
+}
+
+// defaultEntries 内置的敏感端口目录
+var defaultEntries = []Entry{
+	{"tcp", 21, 21, "ftp", finding.SeverityHigh},
+	{"tcp", 22, 22, "ssh", finding.SeverityMedium},
+	{"tcp", 23, 23, "telnet", finding.SeverityCritical},
+	{"tcp", 25, 25, "smtp", finding.SeverityLow},
+	{"tcp", 53, 53, "dns", finding.SeverityMedium},
+	{"udp", 53, 53, "dns", finding.SeverityMedium},
+	{"tcp", 80, 80, "http", finding.SeverityLow},
+	{"tcp", 110, 110, "pop3", finding.SeverityMedium},
+	{"tcp", 111, 111, "rpcbind", finding.SeverityHigh},
+	{"udp", 111, 111, "rpcbind", finding.SeverityHigh},
+	{"tcp", 135, 135, "msrpc", finding.SeverityHigh},
+	{"tcp", 137, 139, "netbios", finding.SeverityHigh},
+	{"udp", 137, 139, "netbios", finding.SeverityHigh},
+	{"tcp", 143, 143, "imap", finding.SeverityMedium},
+	{"udp", 161, 161, "snmp", finding.SeverityHigh},
+	{"tcp", 389, 389, "ldap", finding.SeverityHigh},
+	{"udp", 389, 389, "ldap", finding.SeverityHigh},
+	{"tcp", 443, 443, "https", finding.SeverityLow},
+	{"tcp", 445, 445, "smb", finding.SeverityCritical},
+	{"tcp", 465, 465, "smtp", finding.SeverityLow},
+	{"tcp", 587, 587, "smtp", finding.SeverityLow},
+	{"tcp", 636, 636, "ldaps", finding.SeverityMedium},
+	{"tcp", 873, 873, "rsync", finding.SeverityHigh},
+	{"tcp", 993, 993, "imaps", finding.SeverityLow},
+	{"tcp", 995, 995, "pop3s", finding.SeverityLow},
+	{"tcp", 1080, 1080, "socks", finding.SeverityHigh},
+	{"tcp", 1433, 1433, "mssql", finding.SeverityCritical},
+	{"tcp", 1521, 1521, "oracle", finding.SeverityCritical},
+	{"tcp", 2049, 2049, "nfs", finding.SeverityCritical},
+	{"tcp", 2375, 2375, "docker", finding.SeverityCritical},
+	{"tcp", 2376, 2376, "docker-tls", finding.SeverityHigh},
+	{"tcp", 2379, 2380, "etcd", finding.SeverityCritical},
+	{"tcp", 3000, 3000, "http-dev", finding.SeverityMedium},
+	{"tcp", 3306, 3306, "mysql", finding.SeverityCritical},
+	{"tcp", 3389, 3389, "rdp", finding.SeverityCritical},
+	{"udp", 3389, 3389, "rdp", finding.SeverityCritical},
+	{"tcp", 4505, 4506, "saltstack", finding.SeverityCritical},
+	{"tcp", 5000, 5000, "http-dev", finding.SeverityMedium},
+	{"tcp", 5432, 5432, "postgres", finding.SeverityCritical},
+	{"tcp", 5601, 5601, "kibana", finding.SeverityHigh},
+	{"tcp", 5671, 5672, "amqp", finding.SeverityHigh},
+	{"tcp", 5900, 5903, "vnc", finding.SeverityCritical},
+	{"tcp", 5984, 5984, "couchdb", finding.SeverityCritical},
+	{"tcp", 5985, 5986, "winrm", finding.SeverityHigh},
+	{"tcp", 6379, 6380, "redis", finding.SeverityCritical},
+	{"tcp", 6443, 6443, "kubernetes-api", finding.SeverityHigh},
+	{"tcp", 7001, 7001, "weblogic", finding.SeverityHigh},
+	{"tcp", 8080, 8080, "http-alt", finding.SeverityMedium},
+	{"tcp", 8443, 8443, "https-alt", finding.SeverityLow},
+	{"tcp", 8500, 8500, "consul", finding.SeverityHigh},
+	{"tcp", 8888, 8888, "http-alt", finding.SeverityMedium},
+	{"tcp", 9092, 9092, "kafka", finding.SeverityHigh},
+	{"tcp", 9200, 9300, "elasticsearch", finding.SeverityCritical},
+	{"tcp", 10250, 10250, "kubelet", finding.SeverityCritical},
+	{"tcp", 11211, 11211, "memcached", finding.SeverityCritical},
+	{"udp", 11211, 11211, "memcached", finding.SeverityCritical},
+	{"tcp", 15672, 15672, "rabbitmq-management", finding.SeverityHigh},
+	{"tcp", 27017, 27019, "mongo", finding.SeverityCritical},
+	{"tcp", 50070, 50070, "hadoop", finding.SeverityHigh},
+}
+
+// DefaultCatalog 返回内置目录
+func DefaultCatalog() *Catalog {
+	return &Catalog{entries: append([]Entry{}, defaultEntries...)}
+}
+
+// CatalogFromViper 读取 Risk.catalog，格式为 [{port: "3389" 或 "8000-8100", protocol, service, risk}]
+// 配置条目优先匹配，可覆盖内置条目的服务名与风险等级
+func CatalogFromViper() *Catalog {
+	var raw []struct {
+		Port     string `mapstructure:"port"`
+		Protocol string `mapstructure:"protocol"`
+		Service  string `mapstructure:"service"`
+		Risk     string `mapstructure:"risk"`
+	}
+	c := DefaultCatalog()
+	if err := viper.UnmarshalKey("Risk.catalog", &raw); err != nil {
+		logx.Errorf("parse Risk.catalog error: %v", err)
+		return c
+	}
+	var custom []Entry
+	for _, r := range raw {
+		from, to, ok := parsePorts(r.Port)
+		sev := finding.ParseSeverity(r.Risk)
+		if !ok || sev == "" {
+			logx.Errorf("invalid Risk.catalog entry: port=%s, risk=%s", r.Port, r.Risk)
+			continue
+		}
+		proto := normalizeProtocol(r.Protocol)
+		if proto == "" || proto == "-1" {
+			proto = "tcp"
+		}
+		custom = append(custom, Entry{Protocol: proto, FromPort: from, ToPort: to, Service: strings.ToLower(r.Service), Risk: sev})
+	}
+	c.entries = append(custom, c.entries...)
+	return c
+}
+
+// Lookup 按协议与端口查找服务，协议为 -1 时匹配任意协议
+func (c *Catalog) Lookup(protocol string, port int) (Entry, bool) {
+	protocol = normalizeProtocol(protocol)
+	for _, e := range c.entries {
+		if (protocol == "-1" || e.Protocol == protocol) && port >= e.FromPort && port <= e.ToPort {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// ByService 按服务识别结果的名称查找，用于非标准端口上的服务
+func (c *Catalog) ByService(service string) (Entry, bool) {
+	service = strings.ToLower(service)
+	for _, e := range c.entries {
+		if e.Service == service {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Within 返回与端口范围有交集的条目
+func (c *Catalog) Within(protocol string, from, to int) []Entry {
+	protocol = normalizeProtocol(protocol)
+	var out []Entry
+	for _, e := range c.entries {
+		if (protocol == "-1" || e.Protocol == protocol) && e.FromPort <= to && e.ToPort >= from {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// normalizeProtocol 统一协议名，兼容协议号与 all
+func normalizeProtocol(p string) string {
+	switch strings.ToLower(strings.TrimSpace(p)) {
+	case "6", "tcp":
+		return "tcp"
+	case "17", "udp":
+		return "udp"
+	case "", "-1", "all", "*":
+		return "-1"
+	case "1", "icmp":
+		return "icmp"
+	case "58", "icmpv6", "ipv6-icmp":
+		return "icmpv6"
+	default:
+		return strings.ToLower(p)
+	}
+}
+
+// parsePorts 解析 "22" 或 "8000-9000"
+func parsePorts(s string) (int, int, bool) {
+	lo, hi, found := strings.Cut(strings.TrimSpace(s), "-")
+	from, err := strconv.Atoi(lo)
+	if err != nil {
+		return 0, 0, false
+	}
+	if !found {
+		return from, from, true
+	}
+	to, err := strconv.Atoi(hi)
+	if err != nil || to < from {
+		return 0, 0, false
+	}
+	return from, to, true
+}
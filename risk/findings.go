@@ -0,0 +1,98 @@
+package risk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/colin-404/logx"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// 内置的暴露问题规则
+const (
+	RuleExposedPort  = "exposed-port"
+	RuleExposedRange = "exposed-port-range"
+)
+
+// Evaluate 对公网或大范围公网可达的放行生成问题：目录中的敏感端口逐个报告，
+// 超过 wideRange 的端口范围单独报告；有可达性分析时以可达性为准，ICMP 等无端口协议不报告
+func (m *Model) Evaluate(assets []*cloud.Asset) []*finding.Finding {
+	var out []*finding.Finding
+	for _, a := range assets {
+		out = append(out, m.evaluate(a)...)
+	}
+	logx.Infof("exposure findings: assets=%d, findings=%d", len(assets), len(out))
+	return out
+}
+
+func (m *Model) evaluate(a *cloud.Asset) []*finding.Finding {
+	var out []*finding.Finding
+	seen := map[string]bool{}
+	add := func(rule string, sev finding.Severity, r exposed, proto string, from, to int, title, detail string) {
+		target := fmt.Sprintf("%s/%d", proto, from)
+		if to != from {
+			target = fmt.Sprintf("%s/%d-%d", proto, from, to)
+		}
+		if seen[target] {
+			return
+		}
+		seen[target] = true
+		f := finding.New(rule, sev, a, target, title, detail)
+		f.GroupID, f.FromPort, f.ToPort = r.groupID, from, to
+		f.Sources = r.sources
+		f.Evidence["exposure"] = string(r.exposure)
+		if len(r.sources) > 0 {
+			f.Evidence["sources"] = strings.Join(r.sources, ",")
+		}
+		if state := verifiedState(a, from, to); state != "" {
+			f.Evidence["state"] = state
+		}
+		out = append(out, f)
+	}
+
+	for _, r := range exposedRanges(a) {
+		if !cloud.IsWideExposure(r.exposure) || !hasPorts(r.protocol) {
+			continue
+		}
+		from, to := r.from, r.to
+		if from <= 0 && to <= 0 {
+			from, to = 0, 65535
+		}
+		proto := r.protocol
+		if proto == "-1" {
+			proto = "all"
+		}
+		if to-from+1 > wideRange {
+			sev := finding.SeverityHigh
+			if from <= 1 && to >= 65535 {
+				sev = finding.SeverityCritical
+			}
+			add(RuleExposedRange, sev, r, proto, from, to, "Wide port range exposed",
+				fmt.Sprintf("%s ports %d-%d are reachable from %s", proto, from, to, r.exposure))
+		}
+		for _, e := range m.Catalog.Within(r.protocol, from, to) {
+			if e.Risk.Rank() < m.MinFindingSeverity.Rank() {
+				continue
+			}
+			lo, hi := max(e.FromPort, from), min(e.ToPort, to)
+			add(RuleExposedPort, e.Risk, r, e.Protocol, lo, hi, "Sensitive port exposed",
+				fmt.Sprintf("%s (%s/%d) is reachable from %s", e.Service, e.Protocol, lo, r.exposure))
+		}
+	}
+	return out
+}
+
+// verifiedState 端口范围内主动探测的结果，任一地址开放即为 open
+func verifiedState(a *cloud.Asset, from, to int) string {
+	var state string
+	for _, c := range a.Verification {
+		if c.Port < from || c.Port > to {
+			continue
+		}
+		if state == "" || c.State == cloud.PortOpen {
+			state = c.State
+		}
+	}
+	return state
+}
@@ -0,0 +1,422 @@
+package risk
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// 端口范围超过该数量时视为大范围放行
+const wideRange = 1024
+
+// 每个额外的高风险端口加分及上限
+const (
+	extraPortPoints = 3
+	maxExtraPoints  = 15
+)
+
+// 评分结果中最多保留的端口数
+const maxPorts = 10
+
+// 未认证可访问的服务加分
+const unauthenticatedPoints = 15
+
+// 暴露范围的乘数
+var breadthWeights = map[cloud.Exposure]float64{
+	cloud.ExposureInternet:   1.0,
+	cloud.ExposureBroad:      0.85,
+	cloud.ExposureRestricted: 0.5,
+	cloud.ExposurePrivate:    0.15,
+}
+
+// 主动探测结果的乘数，未探测时取 unverifiedWeight
+var stateWeights = map[string]float64{
+	cloud.PortOpen:     1.0,
+	cloud.PortClosed:   0.3,
+	cloud.PortFiltered: 0.4,
+}
+
+const unverifiedWeight = 0.75
+
+// Model 评分模型，环境与重要性通过资产标签识别
+type Model struct {
+	Catalog *Catalog
+	// EnvTags 环境标签的 key，按顺序取第一个存在的
+	EnvTags    []string
+	EnvWeights map[string]float64
+	// DefaultEnvWeight 未打环境标签或取值不在 EnvWeights 中时的乘数
+	DefaultEnvWeight   float64
+	CriticalityTag     string
+	CriticalityWeights map[string]float64
+	// MinFindingSeverity 内置暴露问题的最低等级，目录中低于该等级的端口只参与评分
+	MinFindingSeverity finding.Severity
+}
+
+// NewModelFromViper 读取 Risk 配置段，未配置的项使用默认值
+func NewModelFromViper() *Model {
+	m := &Model{
+		Catalog:          CatalogFromViper(),
+		EnvTags:          []string{"env", "environment", "stage"},
+		DefaultEnvWeight: 0.9,
+		EnvWeights: map[string]float64{
+			"prod": 1.0, "production": 1.0, "prd": 1.0,
+			"staging": 0.8, "stage": 0.8, "uat": 0.8,
+			"test": 0.6, "qa": 0.6, "dev": 0.6, "development": 0.6, "sandbox": 0.5,
+		},
+		CriticalityTag: "criticality",
+		CriticalityWeights: map[string]float64{
+			"critical": 1.2, "high": 1.1, "medium": 1.0, "low": 0.8,
+		},
+		MinFindingSeverity: finding.SeverityMedium,
+	}
+	if tags := viper.GetStringSlice("Risk.env_tags"); len(tags) > 0 {
+		m.EnvTags = tags
+	}
+	if viper.IsSet("Risk.default_env_weight") {
+		m.DefaultEnvWeight = viper.GetFloat64("Risk.default_env_weight")
+	}
+	for k, v := range viper.GetStringMap("Risk.env_weights") {
+		m.EnvWeights[strings.ToLower(k)] = toFloat(v)
+	}
+	if tag := viper.GetString("Risk.criticality_tag"); tag != "" {
+		m.CriticalityTag = tag
+	}
+	for k, v := range viper.GetStringMap("Risk.criticality_weights") {
+		m.CriticalityWeights[strings.ToLower(k)] = toFloat(v)
+	}
+	if sev := finding.ParseSeverity(viper.GetString("Risk.finding_min_severity")); sev != "" {
+		m.MinFindingSeverity = sev
+	}
+	return m
+}
+
+// ScoreAssets 为每个资产计算评分并写回 Asset.Risk
+func (m *Model) ScoreAssets(assets []*cloud.Asset) {
+	var high int
+	for _, a := range assets {
+		a.Risk = m.Score(a)
+		if a.Risk.Score >= 60 {
+			high++
+		}
+	}
+	logx.Infof("risk summary: assets=%d, high=%d", len(assets), high)
+}
+
+// Score 取得分最高的暴露端口为基础，叠加其他高风险端口，再按环境与重要性标签调整
+func (m *Model) Score(a *cloud.Asset) *cloud.RiskScore {
+	rs := &cloud.RiskScore{Factors: []cloud.RiskFactor{}}
+	ports := m.portRisks(a)
+	if len(ports) == 0 {
+		rs.Level = levelOf(0)
+		return rs
+	}
+	sort.SliceStable(ports, func(i, j int) bool {
+		if ports[i].Score != ports[j].Score {
+			return ports[i].Score > ports[j].Score
+		}
+		return ports[i].Port < ports[j].Port
+	})
+	top := ports[0]
+	score := float64(top.Score)
+	rs.Factors = append(rs.Factors, cloud.RiskFactor{
+		Name:   "port",
+		Detail: fmt.Sprintf("%s/%d %s exposed to %s", top.Protocol, top.Port, top.Service, top.Exposure),
+		Weight: float64(top.Score),
+	})
+
+	var extra int
+	for _, p := range ports[1:] {
+		if p.Score >= 40 {
+			extra += extraPortPoints
+		}
+	}
+	if extra = min(extra, maxExtraPoints); extra > 0 {
+		score += float64(extra)
+		rs.Factors = append(rs.Factors, cloud.RiskFactor{Name: "additional-ports", Detail: fmt.Sprintf("%d more high risk ports", extra/extraPortPoints), Weight: float64(extra)})
+	}
+
+	env, envWeight := m.envWeight(a)
+	score *= envWeight
+	rs.Factors = append(rs.Factors, cloud.RiskFactor{Name: "environment", Detail: env, Weight: envWeight})
+
+	if crit, w, ok := m.criticalityWeight(a); ok {
+		score *= w
+		rs.Factors = append(rs.Factors, cloud.RiskFactor{Name: "criticality", Detail: crit, Weight: w})
+	}
+
+	rs.Score = int(math.Round(math.Min(score, 100)))
+	rs.Level = levelOf(rs.Score)
+	rs.Ports = ports[:min(len(ports), maxPorts)]
+	return rs
+}
+
+// exposed 资产对外放行的端口范围，有可达性分析时以可达性为准
+type exposed struct {
+	protocol string
+	from, to int
+	exposure cloud.Exposure
+	groupID  string
+	sources  []string
+}
+
+func exposedRanges(a *cloud.Asset) []exposed {
+	var out []exposed
+	if a.Reachability != nil {
+		for _, r := range a.Reachability {
+			if !r.Reachable {
+				continue
+			}
+			sources := r.Effective
+			if len(sources) == 0 {
+				sources = r.Sources
+			}
+			out = append(out, exposed{normalizeProtocol(r.Protocol), r.FromPort, r.ToPort, r.Exposure, r.GroupID, sources})
+		}
+		return out
+	}
+	if !a.Public {
+		return nil
+	}
+	for _, r := range a.Rules {
+		out = append(out, exposed{normalizeProtocol(r.Protocol), r.FromPort, r.ToPort, r.Exposure, r.GroupID, r.Sources()})
+	}
+	return out
+}
+
+// portRisks 对每个放行范围内的目录端口、已探测端口与范围起始端口评分，同一端口取最高分
+func (m *Model) portRisks(a *cloud.Asset) []cloud.PortRisk {
+	best := map[string]cloud.PortRisk{}
+	add := func(pr cloud.PortRisk) {
+		key := fmt.Sprintf("%s/%d", pr.Protocol, pr.Port)
+		if cur, ok := best[key]; !ok || pr.Score > cur.Score {
+			best[key] = pr
+		}
+	}
+	for _, r := range exposedRanges(a) {
+		if breadthWeights[r.exposure] == 0 {
+			continue
+		}
+		if !hasPorts(r.protocol) {
+			// ICMP 等没有端口的协议单独按低风险计，不当作全端口放行
+			add(cloud.PortRisk{
+				Protocol: r.protocol,
+				Service:  r.protocol,
+				Exposure: r.exposure,
+				Score:    int(math.Round(points(finding.SeverityLow) * breadthWeights[r.exposure])),
+			})
+			continue
+		}
+		from, to := r.from, r.to
+		if from <= 0 && to <= 0 {
+			from, to = 0, 65535
+		}
+		proto := r.protocol
+		if to-from+1 > wideRange {
+			// 大范围放行本身按高风险计，全端口放行按严重计
+			sev := finding.SeverityHigh
+			if from <= 1 && to >= 65535 {
+				sev = finding.SeverityCritical
+			}
+			rangeProto := proto
+			if rangeProto == "-1" {
+				rangeProto = "all"
+			}
+			add(cloud.PortRisk{
+				Port:     from,
+				Protocol: rangeProto,
+				Service:  fmt.Sprintf("range %d-%d", from, to),
+				Exposure: r.exposure,
+				Score:    int(math.Round(points(sev) * breadthWeights[r.exposure])),
+			})
+		}
+		ports := []int{from}
+		for _, e := range m.Catalog.Within(proto, from, to) {
+			ports = append(ports, max(e.FromPort, from))
+		}
+		for _, c := range a.Verification {
+			if c.Port >= from && c.Port <= to {
+				ports = append(ports, c.Port)
+			}
+		}
+		for _, p := range ports {
+			if p == 0 {
+				continue
+			}
+			add(m.portRisk(a, proto, p, r.exposure))
+		}
+	}
+	out := make([]cloud.PortRisk, 0, len(best))
+	for _, pr := range best {
+		out = append(out, pr)
+	}
+	return out
+}
+
+// portRisk 基础风险取端口目录与服务识别结果中较高者，乘以暴露范围与探测结果的权重
+func (m *Model) portRisk(a *cloud.Asset, proto string, port int, exposure cloud.Exposure) cloud.PortRisk {
+	pr := cloud.PortRisk{Port: port, Protocol: proto, Service: "unknown", Exposure: exposure}
+	if proto == "-1" {
+		pr.Protocol = "tcp"
+	}
+	base := points(finding.SeverityLow)
+	if e, ok := m.Catalog.Lookup(pr.Protocol, port); ok {
+		base, pr.Service = points(e.Risk), e.Service
+	}
+	// 同一端口在多个地址上的识别结果取风险最高的一个，只采用资产自身地址上的结果
+	addrs := addressSet(a)
+	var identified, unauthenticated bool
+	for _, fp := range a.Fingerprints {
+		if fp.Port != port || (fp.IP != "" && !addrs[fp.IP]) {
+			continue
+		}
+		if e, ok := m.Catalog.ByService(fp.Service); ok && points(e.Risk) > base {
+			base = points(e.Risk)
+			pr.Service, identified = fp.Service, true
+		}
+		if fp.Service != "" && !identified {
+			pr.Service, identified = fp.Service, true
+		}
+		if fp.AuthRequired != nil && !*fp.AuthRequired {
+			unauthenticated = true
+		}
+	}
+	if unauthenticated && base >= points(finding.SeverityMedium) {
+		base += unauthenticatedPoints
+	}
+
+	state := unverifiedWeight
+	for _, c := range a.Verification {
+		if c.Port != port || (c.IP != "" && !addrs[c.IP]) {
+			continue
+		}
+		// 多个地址中任一开放即视为开放
+		if w, ok := stateWeights[c.State]; ok && (pr.State == "" || c.State == cloud.PortOpen) {
+			pr.State, state = c.State, w
+		}
+	}
+	pr.Score = int(math.Round(math.Min(base*breadthWeights[exposure]*state, 100)))
+	return pr
+}
+
+// hasPorts 协议是否区分端口，-1 表示全部协议
+func hasPorts(proto string) bool {
+	return proto == "tcp" || proto == "udp" || proto == "-1"
+}
+
+// addressSet 资产的公网 IPv4 与 IPv6 地址
+func addressSet(a *cloud.Asset) map[string]bool {
+	out := map[string]bool{}
+	for _, ip := range append(append([]string{}, a.PublicIPs...), a.IPv6s...) {
+		out[ip] = true
+	}
+	return out
+}
+
+func (m *Model) envWeight(a *cloud.Asset) (string, float64) {
+	for _, key := range m.EnvTags {
+		v := strings.ToLower(tagValue(a.Tags, key))
+		if v == "" {
+			continue
+		}
+		if w, ok := m.EnvWeights[v]; ok {
+			return v, w
+		}
+		return v, m.DefaultEnvWeight
+	}
+	return "unknown", m.DefaultEnvWeight
+}
+
+func (m *Model) criticalityWeight(a *cloud.Asset) (string, float64, bool) {
+	v := strings.ToLower(tagValue(a.Tags, m.CriticalityTag))
+	w, ok := m.CriticalityWeights[v]
+	return v, w, ok
+}
+
+// Sort 按评分从高到低排序，同分按厂商与资源 ID 排序
+func Sort(assets []*cloud.Asset) {
+	sort.SliceStable(assets, func(i, j int) bool {
+		si, sj := scoreOf(assets[i]), scoreOf(assets[j])
+		if si != sj {
+			return si > sj
+		}
+		if assets[i].Provider != assets[j].Provider {
+			return assets[i].Provider < assets[j].Provider
+		}
+		return assets[i].InstanceID < assets[j].InstanceID
+	})
+}
+
+func scoreOf(a *cloud.Asset) int {
+	if a.Risk == nil {
+		return 0
+	}
+	return a.Risk.Score
+}
+
+// points 风险等级对应的基础分
+func points(s finding.Severity) float64 {
+	switch s {
+	case finding.SeverityCritical:
+		return 100
+	case finding.SeverityHigh:
+		return 75
+	case finding.SeverityMedium:
+		return 50
+	case finding.SeverityLow:
+		return 25
+	default:
+		return 10
+	}
+}
+
+// levelOf 评分对应的等级
+func levelOf(score int) string {
+	switch {
+	case score >= 80:
+		return string(finding.SeverityCritical)
+	case score >= 60:
+		return string(finding.SeverityHigh)
+	case score >= 40:
+		return string(finding.SeverityMedium)
+	case score >= 20:
+		return string(finding.SeverityLow)
+	default:
+		return string(finding.SeverityInfo)
+	}
+}
+
+// tagValue 大小写不敏感地读取标签
+func tagValue(tags map[string]string, key string) string {
+	if v, ok := tags[key]; ok {
+		return v
+	}
+	for k, v := range tags {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+func toFloat(v interface{}) float64 {
+	switch t := v.(type) {
+	case float64:
+		return t
+	case int:
+		return float64(t)
+	case int64:
+		return float64(t)
+	case string:
+		f, _ := strconv.ParseFloat(t, 64)
+		return f
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,155 @@
+package tencent
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 各产品 API 版本
+const (
+	versionCVM = "2017-03-12"
+	versionVPC = "2017-03-12"
+	versionCLB = "2018-03-17"
+)
+
+const contentType = "application/json; charset=utf-8"
+
+// Client 腾讯云 API 3.0 客户端，使用 TC3-HMAC-SHA256 签名
+type Client struct {
+	SecretID  string
+	SecretKey string
+	// Endpoints 产品 -> endpoint，支持 {region} 占位符，便于指向本地桩服务
+	Endpoints  map[string]string
+	HTTPClient *http.Client
+}
+
+// APIError 接口返回的 Response.Error
+type APIError struct {
+	Code      string
+	Message   string
+	RequestID string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("tencent api error: %s: %s (request %s)", e.Code, e.Message, e.RequestID)
+}
+
+// Call 调用 service 的 action，Response 字段解析到 out
+func (c *Client) Call(service, version, action, region string, params map[string]interface{}, out interface{}) error {
+	endpoint, ok := c.Endpoints[service]
+	if !ok {
+		return fmt.Errorf("tencent endpoint for %s is not configured", service)
+	}
+	u, err := url.Parse(strings.ReplaceAll(endpoint, "{region}", region))
+	if err != nil {
+		return fmt.Errorf("tencent endpoint %s: %w", endpoint, err)
+	}
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Host", u.Host)
+	req.Header.Set("X-TC-Action", action)
+	req.Header.Set("X-TC-Version", version)
+	req.Header.Set("X-TC-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	if region != "" {
+		req.Header.Set("X-TC-Region", region)
+	}
+	req.Header.Set("Authorization", Sign(c.SecretID, c.SecretKey, service, u.Host, payload, now))
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("tencent %s %s: %w", service, action, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("tencent %s %s: %w", service, action, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("tencent %s %s: http %d", service, action, resp.StatusCode)
+	}
+
+	var envelope struct {
+		Response json.RawMessage `json:"Response"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("tencent %s %s: %w", service, action, err)
+	}
+	var status struct {
+		RequestId string `json:"RequestId"`
+		Error     *struct {
+			Code    string `json:"Code"`
+			Message string `json:"Message"`
+		} `json:"Error"`
+	}
+	if err := json.Unmarshal(envelope.Response, &status); err != nil {
+		return fmt.Errorf("tencent %s %s: %w", service, action, err)
+	}
+	if status.Error != nil {
+		return &APIError{Code: status.Error.Code, Message: status.Error.Message, RequestID: status.RequestId}
+	}
+	return json.Unmarshal(envelope.Response, out)
+}
+
+// Sign 生成 TC3-HMAC-SHA256 Authorization 头，签名头固定为 content-type;host
+func Sign(secretID, secretKey, service, host string, payload []byte, t time.Time) string {
+	const algorithm = "TC3-HMAC-SHA256"
+	const signedHeaders = "content-type;host"
+	date := t.UTC().Format("2006-01-02")
+	scope := date + "/" + service + "/tc3_request"
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodPost,
+		"/",
+		"",
+		"content-type:" + contentType + "\nhost:" + host + "\n",
+		signedHeaders,
+		sha256Hex(payload),
+	}, "\n")
+	stringToSign := strings.Join([]string{
+		algorithm,
+		strconv.FormatInt(t.Unix(), 10),
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	secretDate := hmacSHA256([]byte("TC3"+secretKey), date)
+	secretService := hmacSHA256(secretDate, service)
+	secretSigning := hmacSHA256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(secretSigning, stringToSign))
+
+	return fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, secretID, scope, signedHeaders, signature)
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, msg string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(msg))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,138 @@
+package tencent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 腾讯云 API 3.0 签名文档中 CVM DescribeInstances 的示例，payload 中的中文按文档以 \u 转义
+func TestSignDocumentedExample(t *testing.T) {
+	payload := `{"Limit": 1, "Filters": [{"Values": ["\u672a\u547d\u540d"], "Name": "instance-name"}]}`
+	if got, want := sha256Hex([]byte(payload)), "35e9c5b0e3ae67532d3c9f17ead6c90222632e5b1ff7f6e89887f1398934f064"; got != want {
+		t.Fatalf("payload hash = %s, want %s", got, want)
+	}
+	got := Sign("AKIDz8krbsJ5yKBZQpn74WFkmLPx3EXAMPLE", "Gu5t9xGARNpq86cd98joQYCN3EXAMPLE", "cvm",
+		"cvm.tencentcloudapi.com", []byte(payload), time.Unix(1551113065, 0))
+	want := "TC3-HMAC-SHA256 Credential=AKIDz8krbsJ5yKBZQpn74WFkmLPx3EXAMPLE/2019-02-25/cvm/tc3_request, " +
+		"SignedHeaders=content-type;host, Signature=72e494ea809ad7a8c8f7a4507b9bddcbaa8e581f516e8da2f66e2c5a96525168"
+	if got != want {
+		t.Fatalf("Sign() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParsePorts(t *testing.T) {
+	for in, want := range map[string]string{
+		"ALL":       "[[0 65535]]",
+		"22":        "[[22 22]]",
+		"80,443":    "[[80 80] [443 443]]",
+		"8000-9000": "[[8000 9000]]",
+	} {
+		if got := fmt.Sprint(parsePorts(in)); got != want {
+			t.Errorf("parsePorts(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+// stub 校验签名并按 X-TC-Action 分发的本地桩服务，handle 返回 Response 内容
+func stub(t *testing.T, handle func(action string, params map[string]interface{}) (int, string)) *TencentCloud {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts, _ := strconv.ParseInt(r.Header.Get("X-TC-Timestamp"), 10, 64)
+		service := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")[0]
+		if want := Sign("id", "key", service, r.Host, body, time.Unix(ts, 0)); r.Header.Get("Authorization") != want {
+			t.Errorf("%s: authorization %s, want %s", r.Header.Get("X-TC-Action"), r.Header.Get("Authorization"), want)
+		}
+		if r.Header.Get("X-TC-Region") != "ap-guangzhou" || r.Header.Get("Content-Type") != contentType {
+			t.Errorf("%s: unexpected headers %v", r.Header.Get("X-TC-Action"), r.Header)
+		}
+		var params map[string]interface{}
+		if err := json.Unmarshal(body, &params); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		status, resp := handle(r.Header.Get("X-TC-Action"), params)
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"Response":%s}`, resp)
+	}))
+	t.Cleanup(srv.Close)
+	// 路径中带上产品名，便于桩服务按产品计算签名
+	return &TencentCloud{
+		client: &Client{
+			SecretID:   "id",
+			SecretKey:  "key",
+			Endpoints:  map[string]string{"cvm": srv.URL + "/cvm/", "vpc": srv.URL + "/vpc/", "clb": srv.URL + "/clb/"},
+			HTTPClient: &http.Client{Timeout: 5 * time.Second},
+		},
+		regions: []string{"ap-guangzhou"},
+	}
+}
+
+func TestGetInstancesPaginates(t *testing.T) {
+	const total = 130
+	var offsets []string
+	c := stub(t, func(action string, params map[string]interface{}) (int, string) {
+		switch action {
+		case "DescribeInstances":
+			offset := int(params["Offset"].(float64))
+			offsets = append(offsets, strconv.Itoa(offset))
+			var items []string
+			for i := offset; i < min(offset+pageSize, total); i++ {
+				items = append(items, fmt.Sprintf(`{"InstanceId":"ins-%d","PublicIpAddresses":["1.2.3.%d"],
+					"PrivateIpAddresses":["10.0.0.%d"],"SecurityGroupIds":["sg-1"]}`, i, i%250, i%250))
+			}
+			return http.StatusOK, fmt.Sprintf(`{"TotalCount":%d,"InstanceSet":[%s],"RequestId":"req"}`, total, strings.Join(items, ","))
+		case "DescribeSecurityGroupPolicies":
+			return http.StatusOK, `{"SecurityGroupPolicySet":{"Ingress":[
+				{"PolicyIndex":0,"Protocol":"TCP","Port":"22,3389","CidrBlock":"0.0.0.0/0","Action":"ACCEPT"}]},"RequestId":"req"}`
+		}
+		t.Errorf("unexpected action %s", action)
+		return http.StatusOK, `{"RequestId":"req"}`
+	})
+
+	assets, err := c.GetInstances("ap-guangzhou", newSecurityGroups(c.client, "ap-guangzhou"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != total {
+		t.Fatalf("got %d instances, want %d", len(assets), total)
+	}
+	if strings.Join(offsets, ",") != "0,100" {
+		t.Fatalf("requested offsets %v, want 0,100", offsets)
+	}
+	a := assets[0]
+	if !a.Public || len(a.Rules) != 2 || a.Rules[0].FromPort != 22 || a.Rules[1].FromPort != 3389 {
+		t.Fatalf("unexpected asset %+v", a)
+	}
+}
+
+func TestCallAPIError(t *testing.T) {
+	c := stub(t, func(string, map[string]interface{}) (int, string) {
+		return http.StatusOK, `{"Error":{"Code":"AuthFailure.SignatureFailure","Message":"signature mismatch"},"RequestId":"req-1"}`
+	})
+	_, err := c.GetInstances("ap-guangzhou", newSecurityGroups(c.client, "ap-guangzhou"))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error %v is not an APIError", err)
+	}
+	if apiErr.Code != "AuthFailure.SignatureFailure" || apiErr.RequestID != "req-1" {
+		t.Fatalf("unexpected api error %+v", apiErr)
+	}
+}
+
+func TestCallHTTPError(t *testing.T) {
+	c := stub(t, func(string, map[string]interface{}) (int, string) {
+		return http.StatusServiceUnavailable, `{}`
+	})
+	_, err := c.GetInstances("ap-guangzhou", newSecurityGroups(c.client, "ap-guangzhou"))
+	if err == nil || !strings.Contains(err.Error(), "http 503") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package tencent
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/xidp/protocols"
+)
+
+const ProviderName = "tencent"
+
+const PathInstanceAttackSurface = "/protocols/external-attack-surface/tencent-instance"
+
+// 默认使用就近接入 endpoint，地域通过 X-TC-Region 传递
+var defaultEndpoints = map[string]string{
+	"cvm": "https://cvm.tencentcloudapi.com",
+	"vpc": "https://vpc.tencentcloudapi.com",
+	"clb": "https://clb.tencentcloudapi.com",
+}
+
+type TencentCloud struct {
+	client  *Client
+	regions []string
+}
+
+func init() {
+	cloud.Register(ProviderName, func() cloud.CloudProvider { return NewTencentCloud() })
+}
+
+// NewTencentCloud 从 Tencent 配置段读取凭证、地域与 endpoint
+func NewTencentCloud() *TencentCloud {
+	endpoints := map[string]string{}
+	for service, endpoint := range defaultEndpoints {
+		endpoints[service] = endpoint
+		if v := viper.GetString("Tencent.endpoints." + service); v != "" {
+			endpoints[service] = v
+		}
+	}
+	return &TencentCloud{
+		client: &Client{
+			SecretID:   viper.GetString("Tencent.secret_id"),
+			SecretKey:  viper.GetString("Tencent.secret_key"),
+			Endpoints:  endpoints,
+			HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		},
+		regions: viper.GetStringSlice("Tencent.regions"),
+	}
+}
+
+func (c *TencentCloud) Name() string {
+	return ProviderName
+}
+
+// ListAssets 按地域返回 CVM 实例、CLB 与未绑定的 EIP
+func (c *TencentCloud) ListAssets() ([]*cloud.Asset, error) {
+	var out []*cloud.Asset
+	for _, region := range c.regions {
+		groups := newSecurityGroups(c.client, region)
+		instances, err := c.GetInstances(region, groups)
+		if err != nil {
+			return nil, err
+		}
+		lbs, err := c.GetLoadBalancers(region, groups)
+		if err != nil {
+			return nil, err
+		}
+		eips, err := c.GetUnboundEIPs(region)
+		if err != nil {
+			return nil, err
+		}
+		var exposed int
+		for _, group := range [][]*cloud.Asset{instances, lbs, eips} {
+			for _, a := range group {
+				if cloud.IsWideExposure(a.Exposure) {
+					exposed++
+				}
+			}
+			out = append(out, group...)
+		}
+		logx.Infof("tencent attack surface summary: region=%s, instances=%d, clb=%d, eips=%d, exposed=%d",
+			region, len(instances), len(lbs), len(eips), exposed)
+	}
+	return out, nil
+}
+
+func (c *TencentCloud) PublicIPs() (map[string][]string, error) {
+	assets, err := c.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.PublicIPsOf(assets), nil
+}
+
+func (c *TencentCloud) FirewallRules() (map[string][]cloud.Rule, error) {
+	assets, err := c.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.RulesOf(assets), nil
+}
+
+func (c *TencentCloud) Collect() ([]*protocols.XID, error) {
+	assets, err := c.ListAssets()
+	if err != nil {
+		return nil, err
+	}
+	return cloud.AssetXIDs(assets, PathInstanceAttackSurface), nil
+}
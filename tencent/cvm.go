@@ -0,0 +1,212 @@
+package tencent
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+const pageSize = 100
+
+type cvmInstance struct {
+	InstanceId          string   `json:"InstanceId"`
+	InstanceName        string   `json:"InstanceName"`
+	InstanceState       string   `json:"InstanceState"`
+	PrivateIpAddresses  []string `json:"PrivateIpAddresses"`
+	PublicIpAddresses   []string `json:"PublicIpAddresses"`
+	IPv6Addresses       []string `json:"IPv6Addresses"`
+	SecurityGroupIds    []string `json:"SecurityGroupIds"`
+	VirtualPrivateCloud struct {
+		VpcId    string `json:"VpcId"`
+		SubnetId string `json:"SubnetId"`
+	} `json:"VirtualPrivateCloud"`
+	Tags []struct {
+		Key   string `json:"Key"`
+		Value string `json:"Value"`
+	} `json:"Tags"`
+}
+
+type describeInstancesResponse struct {
+	TotalCount  int           `json:"TotalCount"`
+	InstanceSet []cvmInstance `json:"InstanceSet"`
+}
+
+type securityGroupPolicy struct {
+	PolicyIndex     int    `json:"PolicyIndex"`
+	Protocol        string `json:"Protocol"`
+	Port            string `json:"Port"`
+	CidrBlock       string `json:"CidrBlock"`
+	Ipv6CidrBlock   string `json:"Ipv6CidrBlock"`
+	SecurityGroupId string `json:"SecurityGroupId"`
+	AddressTemplate struct {
+		AddressId      string `json:"AddressId"`
+		AddressGroupId string `json:"AddressGroupId"`
+	} `json:"AddressTemplate"`
+	Action string `json:"Action"`
+}
+
+type describeSecurityGroupPoliciesResponse struct {
+	SecurityGroupPolicySet struct {
+		Ingress []securityGroupPolicy `json:"Ingress"`
+	} `json:"SecurityGroupPolicySet"`
+}
+
+// securityGroups 按需拉取并缓存安全组规则，members 为安全组成员的内网地址
+type securityGroups struct {
+	client  *Client
+	region  string
+	members map[string][]string
+	rules   map[string][]cloud.Rule
+}
+
+func newSecurityGroups(client *Client, region string) *securityGroups {
+	return &securityGroups{
+		client:  client,
+		region:  region,
+		members: map[string][]string{},
+		rules:   map[string][]cloud.Rule{},
+	}
+}
+
+// GetInstances 拉取地域内的 CVM 实例并展开其安全组规则
+func (c *TencentCloud) GetInstances(region string, groups *securityGroups) ([]*cloud.Asset, error) {
+	var instances []cvmInstance
+	for offset := 0; ; offset += pageSize {
+		var resp describeInstancesResponse
+		err := c.client.Call("cvm", versionCVM, "DescribeInstances", region, map[string]interface{}{
+			"Offset": offset,
+			"Limit":  pageSize,
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, resp.InstanceSet...)
+		if len(resp.InstanceSet) < pageSize || len(instances) >= resp.TotalCount {
+			break
+		}
+	}
+	for _, inst := range instances {
+		for _, sg := range inst.SecurityGroupIds {
+			for _, ip := range inst.PrivateIpAddresses {
+				groups.members[sg] = cloud.AppendUnique(groups.members[sg], ip)
+			}
+		}
+	}
+
+	out := make([]*cloud.Asset, 0, len(instances))
+	for _, inst := range instances {
+		as := &cloud.Asset{
+			Provider:     ProviderName,
+			InstanceID:   inst.InstanceId,
+			InstanceName: inst.InstanceName,
+			ResourceType: "cvm",
+			Region:       region,
+			VpcID:        inst.VirtualPrivateCloud.VpcId,
+			SubnetID:     inst.VirtualPrivateCloud.SubnetId,
+			Tags:         map[string]string{},
+			PublicIPs:    append([]string{}, inst.PublicIpAddresses...),
+			PrivateIPs:   inst.PrivateIpAddresses,
+			IPv6s:        inst.IPv6Addresses,
+			GroupIDs:     inst.SecurityGroupIds,
+		}
+		for _, t := range inst.Tags {
+			as.Tags[t.Key] = t.Value
+		}
+		sort.Strings(as.PublicIPs)
+		as.Public = len(as.PublicIPs) > 0 || len(as.IPv6s) > 0
+		for _, sg := range as.GroupIDs {
+			rules, err := groups.Rules(sg)
+			if err != nil {
+				return nil, err
+			}
+			as.Rules = append(as.Rules, rules...)
+		}
+		as.Evaluate()
+		out = append(out, as)
+	}
+	return out, nil
+}
+
+// Rules 解析安全组入站规则，按 PolicyIndex 顺序匹配，先命中者生效
+func (s *securityGroups) Rules(groupID string) ([]cloud.Rule, error) {
+	if rules, ok := s.rules[groupID]; ok {
+		return rules, nil
+	}
+	var resp describeSecurityGroupPoliciesResponse
+	err := s.client.Call("vpc", versionVPC, "DescribeSecurityGroupPolicies", s.region, map[string]interface{}{
+		"SecurityGroupId": groupID,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []cloud.PolicyEntry
+	for _, p := range resp.SecurityGroupPolicySet.Ingress {
+		base := cloud.PolicyEntry{
+			Priority: p.PolicyIndex,
+			Allow:    strings.EqualFold(p.Action, "ACCEPT"),
+			Protocol: normalizeProtocol(p.Protocol),
+		}
+		for _, cidr := range []string{p.CidrBlock, p.Ipv6CidrBlock} {
+			if cidr != "" {
+				base.Iprange = append(base.Iprange, cidr)
+			}
+		}
+		if p.SecurityGroupId != "" {
+			ips, ok := s.members[p.SecurityGroupId]
+			base.SourceGroups = append(base.SourceGroups, cloud.GroupReference{
+				GroupID:  p.SecurityGroupId,
+				Members:  []string{},
+				IPs:      append([]string{}, ips...),
+				Resolved: ok,
+			})
+		}
+		// 参数模板未展开，保留引用以便人工核查
+		if id := cloud.FirstString(p.AddressTemplate.AddressId, p.AddressTemplate.AddressGroupId); id != "" {
+			base.SourceGroups = append(base.SourceGroups, cloud.GroupReference{GroupID: id, Members: []string{}, IPs: []string{}})
+		}
+		for _, pr := range parsePorts(p.Port) {
+			e := base
+			e.FromPort, e.ToPort = pr[0], pr[1]
+			entries = append(entries, e)
+		}
+	}
+	rules := cloud.OrderedRules(groupID, entries)
+	s.rules[groupID] = rules
+	return rules, nil
+}
+
+// parsePorts 解析 "ALL"、"22"、"80,443" 或 "8000-9000"
+func parsePorts(s string) [][2]int {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, "ALL") {
+		return [][2]int{{0, 65535}}
+	}
+	var out [][2]int
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, found := strings.Cut(strings.TrimSpace(part), "-")
+		from, err := strconv.Atoi(lo)
+		if err != nil {
+			continue
+		}
+		to := from
+		if found {
+			if to, err = strconv.Atoi(hi); err != nil {
+				continue
+			}
+		}
+		out = append(out, [2]int{from, to})
+	}
+	return out
+}
+
+func normalizeProtocol(p string) string {
+	switch strings.ToLower(p) {
+	case "", "all":
+		return "-1"
+	default:
+		return strings.ToLower(p)
+	}
+}
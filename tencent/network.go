@@ -0,0 +1,167 @@
+package tencent
+
+import (
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+)
+
+type describeAddressesResponse struct {
+	TotalCount int `json:"TotalCount"`
+	AddressSet []struct {
+		AddressId     string `json:"AddressId"`
+		AddressName   string `json:"AddressName"`
+		AddressIp     string `json:"AddressIp"`
+		AddressStatus string `json:"AddressStatus"`
+		InstanceId    string `json:"InstanceId"`
+	} `json:"AddressSet"`
+}
+
+type clbLoadBalancer struct {
+	LoadBalancerId   string   `json:"LoadBalancerId"`
+	LoadBalancerName string   `json:"LoadBalancerName"`
+	LoadBalancerType string   `json:"LoadBalancerType"`
+	LoadBalancerVips []string `json:"LoadBalancerVips"`
+	VpcId            string   `json:"VpcId"`
+	SubnetId         string   `json:"SubnetId"`
+	SecureGroups     []string `json:"SecureGroups"`
+	Tags             []struct {
+		TagKey   string `json:"TagKey"`
+		TagValue string `json:"TagValue"`
+	} `json:"Tags"`
+}
+
+type describeLoadBalancersResponse struct {
+	TotalCount      int               `json:"TotalCount"`
+	LoadBalancerSet []clbLoadBalancer `json:"LoadBalancerSet"`
+}
+
+type describeListenersResponse struct {
+	Listeners []struct {
+		ListenerId string `json:"ListenerId"`
+		Protocol   string `json:"Protocol"`
+		Port       int    `json:"Port"`
+	} `json:"Listeners"`
+}
+
+// GetUnboundEIPs 返回未绑定实例的 EIP，已绑定的地址随实例输出
+func (c *TencentCloud) GetUnboundEIPs(region string) ([]*cloud.Asset, error) {
+	var out []*cloud.Asset
+	for offset := 0; ; offset += pageSize {
+		var resp describeAddressesResponse
+		err := c.client.Call("vpc", versionVPC, "DescribeAddresses", region, map[string]interface{}{
+			"Offset": offset,
+			"Limit":  pageSize,
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		for _, eip := range resp.AddressSet {
+			if !strings.EqualFold(eip.AddressStatus, "UNBIND") || eip.AddressIp == "" {
+				continue
+			}
+			out = append(out, &cloud.Asset{
+				Provider:     ProviderName,
+				InstanceID:   eip.AddressId,
+				InstanceName: eip.AddressName,
+				ResourceType: "eip",
+				Region:       region,
+				Tags:         map[string]string{},
+				PublicIPs:    []string{eip.AddressIp},
+				Public:       true,
+				Exposure:     cloud.ExposureNone,
+			})
+		}
+		if len(resp.AddressSet) < pageSize || offset+len(resp.AddressSet) >= resp.TotalCount {
+			break
+		}
+	}
+	return out, nil
+}
+
+// GetLoadBalancers 返回 CLB 实例，监听端口受 CLB 绑定的安全组约束，未绑定时对任意来源开放
+func (c *TencentCloud) GetLoadBalancers(region string, groups *securityGroups) ([]*cloud.Asset, error) {
+	var lbs []clbLoadBalancer
+	for offset := 0; ; offset += pageSize {
+		var resp describeLoadBalancersResponse
+		err := c.client.Call("clb", versionCLB, "DescribeLoadBalancers", region, map[string]interface{}{
+			"Offset": offset,
+			"Limit":  pageSize,
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		lbs = append(lbs, resp.LoadBalancerSet...)
+		if len(resp.LoadBalancerSet) < pageSize || len(lbs) >= resp.TotalCount {
+			break
+		}
+	}
+
+	out := make([]*cloud.Asset, 0, len(lbs))
+	for _, lb := range lbs {
+		as := &cloud.Asset{
+			Provider:     ProviderName,
+			InstanceID:   lb.LoadBalancerId,
+			InstanceName: lb.LoadBalancerName,
+			ResourceType: "clb",
+			Region:       region,
+			VpcID:        lb.VpcId,
+			SubnetID:     lb.SubnetId,
+			Tags:         map[string]string{},
+			GroupIDs:     lb.SecureGroups,
+			Public:       strings.EqualFold(lb.LoadBalancerType, "OPEN"),
+		}
+		for _, t := range lb.Tags {
+			as.Tags[t.TagKey] = t.TagValue
+		}
+		if as.Public {
+			as.PublicIPs = lb.LoadBalancerVips
+		} else {
+			as.PrivateIPs = lb.LoadBalancerVips
+		}
+
+		var sgRules []cloud.Rule
+		for _, sg := range lb.SecureGroups {
+			rules, err := groups.Rules(sg)
+			if err != nil {
+				return nil, err
+			}
+			sgRules = append(sgRules, rules...)
+		}
+
+		var resp describeListenersResponse
+		err := c.client.Call("clb", versionCLB, "DescribeListeners", region, map[string]interface{}{
+			"LoadBalancerId": lb.LoadBalancerId,
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range resp.Listeners {
+			proto := "tcp"
+			if strings.EqualFold(l.Protocol, "UDP") {
+				proto = "udp"
+			}
+			if len(lb.SecureGroups) == 0 {
+				as.Rules = append(as.Rules, cloud.Rule{
+					GroupID:  l.ListenerId,
+					FromPort: l.Port,
+					ToPort:   l.Port,
+					Protocol: proto,
+					Iprange:  []string{cloud.ExposureIprange},
+					Exposure: cloud.ExposureInternet,
+				})
+				continue
+			}
+			for _, r := range sgRules {
+				if r.Protocol != "-1" && r.Protocol != proto || !r.Covers(l.Port) {
+					continue
+				}
+				r.FromPort, r.ToPort, r.Protocol = l.Port, l.Port, proto
+				as.Rules = append(as.Rules, r)
+			}
+		}
+		as.Evaluate()
+		out = append(out, as)
+	}
+	return out, nil
+}
@@ -0,0 +1,29 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrInvalidAssignee 工单系统不接受经办人（用户不存在或不可分配），调用方可以去掉经办人后重新建单
+var ErrInvalidAssignee = errors.New("invalid assignee")
+
+// Issue 创建工单所需的字段
+type Issue struct {
+	Project     string
+	Type        string
+	Summary     string
+	Description string
+	Assignee    string
+	Priority    string
+	Labels      []string
+}
+
+// Client 工单系统接口，Jira 之外的系统或测试用的 mock 实现该接口即可
+type Client interface {
+	// Create 创建工单并返回工单号，经办人被拒绝时返回包装了 ErrInvalidAssignee 的错误
+	Create(ctx context.Context, issue *Issue) (string, error)
+	Comment(ctx context.Context, key, body string) error
+	// Transition 按流转名称（如 Done）推进工单状态
+	Transition(ctx context.Context, key, name string) error
+}
@@ -0,0 +1,154 @@
+package ticket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// JiraClient Jira REST API v2 客户端。
+// 设置 Username 时使用 Basic 认证（Jira Cloud 为邮箱 + API token），否则使用 Bearer（Server/DC 的 PAT）
+type JiraClient struct {
+	BaseURL  string
+	Username string
+	Token    string
+	// AssigneeField 经办人字段，Jira Cloud 为 accountId，Server 为 name
+	AssigneeField string
+	HTTP          *http.Client
+}
+
+func (c *JiraClient) Create(ctx context.Context, issue *Issue) (string, error) {
+	fields := map[string]interface{}{
+		"project":     map[string]string{"key": issue.Project},
+		"issuetype":   map[string]string{"name": issue.Type},
+		"summary":     issue.Summary,
+		"description": issue.Description,
+	}
+	if len(issue.Labels) > 0 {
+		fields["labels"] = issue.Labels
+	}
+	if issue.Assignee != "" {
+		field := c.AssigneeField
+		if field == "" {
+			field = "name"
+		}
+		fields["assignee"] = map[string]string{field: issue.Assignee}
+	}
+	if issue.Priority != "" {
+		fields["priority"] = map[string]string{"name": issue.Priority}
+	}
+	var out struct {
+		Key string `json:"key"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/rest/api/2/issue", map[string]interface{}{"fields": fields}, &out); err != nil {
+		var je *jiraError
+		if issue.Assignee != "" && errors.As(err, &je) && je.rejects("assignee") {
+			return "", fmt.Errorf("%w %q: %v", ErrInvalidAssignee, issue.Assignee, err)
+		}
+		return "", err
+	}
+	if out.Key == "" {
+		return "", fmt.Errorf("jira create issue: empty key in response")
+	}
+	return out.Key, nil
+}
+
+func (c *JiraClient) Comment(ctx context.Context, key, body string) error {
+	return c.do(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/comment", map[string]string{"body": body}, nil)
+}
+
+// Transition 先查询可用流转，按流转名或目标状态名匹配（大小写不敏感）
+func (c *JiraClient) Transition(ctx context.Context, key, name string) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+	var list struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return err
+	}
+	for _, t := range list.Transitions {
+		if strings.EqualFold(t.Name, name) || strings.EqualFold(t.To.Name, name) {
+			return c.do(ctx, http.MethodPost, path, map[string]interface{}{"transition": map[string]string{"id": t.ID}}, nil)
+		}
+	}
+	return fmt.Errorf("jira issue %s has no transition %q", key, name)
+}
+
+// do 发送请求，非 2xx 时返回包含响应内容的错误，out 不为空时解析响应
+func (c *JiraClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.BaseURL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Token)
+	} else if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	client := c.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &jiraError{method: method, path: path, status: resp.StatusCode, body: bytes.TrimSpace(data)}
+	}
+	if out != nil && len(data) > 0 {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+// jiraError 非 2xx 响应，body 为 {"errorMessages": [...], "errors": {"字段": "原因"}}
+type jiraError struct {
+	method string
+	path   string
+	status int
+	body   []byte
+}
+
+func (e *jiraError) Error() string {
+	return fmt.Sprintf("jira %s %s: http %d: %s", e.method, e.path, e.status, e.body)
+}
+
+// rejects 请求是否因 field 字段的取值被拒绝
+func (e *jiraError) rejects(field string) bool {
+	var resp struct {
+		Errors map[string]string `json:"errors"`
+	}
+	if e.status != http.StatusBadRequest || json.Unmarshal(e.body, &resp) != nil {
+		return false
+	}
+	_, ok := resp.Errors[field]
+	return ok
+}
@@ -0,0 +1,259 @@
+package ticket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type jiraTransition struct {
+	ID   string
+	Name string
+	To   string
+}
+
+// 模拟的默认工作流，流转名与目标状态名不同
+var defaultWorkflow = []jiraTransition{
+	{ID: "11", Name: "Start Progress", To: "In Progress"},
+	{ID: "21", Name: "Resolve Issue", To: "Done"},
+	{ID: "31", Name: "Reopen Issue", To: "Open"},
+}
+
+type jiraIssue struct {
+	Fields   map[string]interface{}
+	Status   string
+	Comments []string
+}
+
+// jiraMock 本地 Jira REST API v2 桩服务，记录建单、评论与流转
+type jiraMock struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu     sync.Mutex
+	issues map[string]*jiraIssue
+	auth   []string
+	// fail 不为 0 时所有请求返回该状态码
+	fail int
+	// users 不为空时拒绝其中不存在的经办人
+	users map[string]bool
+}
+
+func newJiraMock(t *testing.T) *jiraMock {
+	m := &jiraMock{t: t, issues: map[string]*jiraIssue{}}
+	m.server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *jiraMock) client() *JiraClient {
+	return &JiraClient{BaseURL: m.server.URL + "/", Username: "bot@example.com", Token: "api-token",
+		AssigneeField: "accountId", HTTP: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (m *jiraMock) issue(key string) *jiraIssue {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.issues[key]
+}
+
+func (m *jiraMock) handle(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.auth = append(m.auth, r.Header.Get("Authorization"))
+	if r.Header.Get("Accept") != "application/json" {
+		m.t.Errorf("%s %s: unexpected Accept %q", r.Method, r.URL.Path, r.Header.Get("Accept"))
+	}
+	if m.fail != 0 {
+		w.WriteHeader(m.fail)
+		fmt.Fprint(w, `{"errorMessages":["mock failure"]}`)
+		return
+	}
+	var body map[string]interface{}
+	if r.Method == http.MethodPost {
+		if r.Header.Get("Content-Type") != "application/json" {
+			m.t.Errorf("%s: unexpected Content-Type %q", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			m.t.Errorf("%s: decode body: %v", r.URL.Path, err)
+		}
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue"), "/")
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue":
+		fields, _ := body["fields"].(map[string]interface{})
+		if assignee, ok := fields["assignee"].(map[string]interface{}); ok && m.users != nil {
+			for _, v := range assignee {
+				if !m.users[fmt.Sprint(v)] {
+					w.WriteHeader(http.StatusBadRequest)
+					fmt.Fprintf(w, `{"errorMessages":[],"errors":{"assignee":"User '%v' does not exist."}}`, v)
+					return
+				}
+			}
+		}
+		key := fmt.Sprintf("SEC-%d", len(m.issues)+1)
+		m.issues[key] = &jiraIssue{Fields: fields, Status: "Open"}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":"1000%d","key":%q}`, len(m.issues), key)
+		return
+	case len(parts) == 3:
+		issue := m.issues[parts[1]]
+		if issue == nil {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errorMessages":["Issue does not exist or you do not have permission to see it."]}`)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && parts[2] == "comment":
+			issue.Comments = append(issue.Comments, fmt.Sprint(body["body"]))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":"10100"}`)
+			return
+		case r.Method == http.MethodGet && parts[2] == "transitions":
+			var list []map[string]interface{}
+			for _, tr := range defaultWorkflow {
+				list = append(list, map[string]interface{}{"id": tr.ID, "name": tr.Name, "to": map[string]string{"name": tr.To}})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"transitions": list})
+			return
+		case r.Method == http.MethodPost && parts[2] == "transitions":
+			tr, _ := body["transition"].(map[string]interface{})
+			for _, candidate := range defaultWorkflow {
+				if candidate.ID == tr["id"] {
+					issue.Status = candidate.To
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errorMessages":["Transition id is not valid"]}`)
+			return
+		}
+	}
+	m.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func TestJiraCreate(t *testing.T) {
+	m := newJiraMock(t)
+	key, err := m.client().Create(context.Background(), &Issue{
+		Project: "SEC", Type: "Bug", Summary: "Redis exposed", Description: "6379 open to 0.0.0.0/0",
+		Assignee: "5b10a2844c20165700ede21g", Priority: "Highest", Labels: []string{"attack-surface"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "SEC-1" {
+		t.Fatalf("key = %s, want SEC-1", key)
+	}
+	got, _ := json.Marshal(m.issue(key).Fields)
+	want := `{"assignee":{"accountId":"5b10a2844c20165700ede21g"},"description":"6379 open to 0.0.0.0/0",` +
+		`"issuetype":{"name":"Bug"},"labels":["attack-surface"],"priority":{"name":"Highest"},"project":{"key":"SEC"},"summary":"Redis exposed"}`
+	if string(got) != want {
+		t.Fatalf("fields =\n%s\nwant\n%s", got, want)
+	}
+	if m.auth[0] != "Basic Ym90QGV4YW1wbGUuY29tOmFwaS10b2tlbg==" {
+		t.Fatalf("unexpected authorization %q", m.auth[0])
+	}
+}
+
+func TestJiraBearerAuth(t *testing.T) {
+	m := newJiraMock(t)
+	c := m.client()
+	c.Username = ""
+	c.AssigneeField = ""
+	key, err := c.Create(context.Background(), &Issue{Project: "SEC", Type: "Task", Summary: "s", Assignee: "jdoe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.auth[0] != "Bearer api-token" {
+		t.Fatalf("unexpected authorization %q", m.auth[0])
+	}
+	// Server/DC 默认按 name 指定经办人，未设置的可选字段不发送
+	got, _ := json.Marshal(m.issue(key).Fields)
+	if want := `{"assignee":{"name":"jdoe"},"description":"","issuetype":{"name":"Task"},"project":{"key":"SEC"},"summary":"s"}`; string(got) != want {
+		t.Fatalf("fields = %s, want %s", got, want)
+	}
+}
+
+func TestJiraComment(t *testing.T) {
+	m := newJiraMock(t)
+	c := m.client()
+	key, _ := c.Create(context.Background(), &Issue{Project: "SEC", Type: "Task", Summary: "s"})
+	if err := c.Comment(context.Background(), key, "still exposed"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.issue(key).Comments; len(got) != 1 || got[0] != "still exposed" {
+		t.Fatalf("comments = %v", got)
+	}
+	if err := c.Comment(context.Background(), "SEC-404", "x"); err == nil || !strings.Contains(err.Error(), "http 404") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestJiraTransition(t *testing.T) {
+	for name, tc := range map[string]struct {
+		transition string
+		status     string
+	}{
+		"by name":          {"Resolve Issue", "Done"},
+		"by target status": {"done", "Done"},
+		"case insensitive": {"start progress", "In Progress"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			m := newJiraMock(t)
+			c := m.client()
+			key, _ := c.Create(context.Background(), &Issue{Project: "SEC", Type: "Task", Summary: "s"})
+			if err := c.Transition(context.Background(), key, tc.transition); err != nil {
+				t.Fatal(err)
+			}
+			if got := m.issue(key).Status; got != tc.status {
+				t.Fatalf("status = %s, want %s", got, tc.status)
+			}
+		})
+	}
+}
+
+func TestJiraTransitionNotFound(t *testing.T) {
+	m := newJiraMock(t)
+	c := m.client()
+	key, _ := c.Create(context.Background(), &Issue{Project: "SEC", Type: "Task", Summary: "s"})
+	err := c.Transition(context.Background(), key, "Close Issue")
+	if err == nil || !strings.Contains(err.Error(), `no transition "Close Issue"`) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := m.issue(key).Status; got != "Open" {
+		t.Fatalf("status changed to %s", got)
+	}
+}
+
+func TestJiraInvalidAssignee(t *testing.T) {
+	m := newJiraMock(t)
+	m.users = map[string]bool{}
+	_, err := m.client().Create(context.Background(), &Issue{Project: "SEC", Type: "Task", Summary: "s", Assignee: "alice"})
+	if !errors.Is(err, ErrInvalidAssignee) || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// 其他字段的错误不视为经办人错误
+	_, err = m.client().Create(context.Background(), &Issue{Project: "SEC", Type: "Task", Summary: "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJiraHTTPError(t *testing.T) {
+	m := newJiraMock(t)
+	m.fail = http.StatusUnauthorized
+	_, err := m.client().Create(context.Background(), &Issue{Project: "SEC", Type: "Task", Summary: "s"})
+	if err == nil || !strings.Contains(err.Error(), "http 401") || !strings.Contains(err.Error(), "mock failure") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package ticket
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/xidp/protocols"
+	"github.com/xid-protocol/xidp/xdb"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const PathTicket = "/protocols/external-attack-surface/ticket"
+
+// 默认的工单关联集合
+const defaultCollection = "attack_surface_ticket"
+
+// Link 问题与工单的关联，ID 为 sink 名与问题 ID 的组合
+type Link struct {
+	ID        string    `json:"id" bson:"id"`
+	Sink      string    `json:"sink" bson:"sink"`
+	FindingID string    `json:"findingId" bson:"findingId"`
+	Key       string    `json:"key" bson:"key"`
+	Resolved  bool      `json:"resolved" bson:"resolved"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
+}
+
+// Links 关联存储
+type Links interface {
+	Get(id string) (*Link, error)
+	Put(l *Link) error
+}
+
+// MongoLinks 每条关联为一个 XID
+type MongoLinks struct {
+	*cloud.Store
+}
+
+func NewMongoLinks(collection string) *MongoLinks {
+	return &MongoLinks{Store: cloud.NewStore(cloud.FirstString(collection, defaultCollection))}
+}
+
+// Get 不存在时返回 nil
+func (m *MongoLinks) Get(id string) (*Link, error) {
+	x, err := m.DBClient.GetByXid(m.Ctx, PathTicket, protocols.GenerateXid(id))
+	if err == xdb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	payload := cloud.PayloadOf(x)
+	if payload == nil {
+		return nil, fmt.Errorf("ticket link %s has empty payload", id)
+	}
+	b, err := bson.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var l Link
+	if err := bson.Unmarshal(b, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (m *MongoLinks) Put(l *Link) error {
+	return m.DBClient.Upsert(m.Ctx, cloud.NewAttackSurfaceXID(l.ID, "ticket", PathTicket, l))
+}
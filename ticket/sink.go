@@ -0,0 +1,202 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/colin-404/logx"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+	"github.com/xid-protocol/attack-surface/notify"
+)
+
+// 默认的经办人标签
+var defaultOwnerTags = []string{"owner"}
+
+func init() {
+	notify.Register("jira", newJiraSink)
+}
+
+// Sink 工单通知渠道：新的高危问题建单，状态变化追加评论，问题解决后自动流转工单。
+// 依赖问题的生命周期状态（Alert.enabled），未开启时只会为达到阈值的问题建单一次
+type Sink struct {
+	Client Client
+	Links  Links
+
+	Project   string
+	IssueType string
+	// MinSeverity 建单阈值，默认 critical
+	MinSeverity finding.Severity
+	// OwnerTags 问题没有归属信息时依次查找的经办人标签，大小写不敏感
+	OwnerTags []string
+	// Priorities 严重程度 -> 工单优先级
+	Priorities map[string]string
+	Labels     []string
+	// ResolveTransition 问题解决时的流转，默认 Done；ReopenTransition 为空时重新出现只评论
+	ResolveTransition string
+	ReopenTransition  string
+
+	name string
+}
+
+// newJiraSink 读取 Notify.sinks 中 type 为 jira 的配置
+//
+//	Notify:
+//	  sinks:
+//	    - {name: jira, type: jira, url: https://example.atlassian.net, username: bot@example.com, token: xxx,
+//	       project: SEC, issue_type: Bug, min_severity: critical, owner_tags: [owner, team],
+//	       assignee_field: accountId, resolve_transition: Done, reopen_transition: Reopen,
+//	       priorities: {critical: Highest}, labels: [attack-surface]}
+func newJiraSink(name string, cfg map[string]interface{}) (notify.Sink, error) {
+	client := &JiraClient{
+		BaseURL:       cloud.String(cfg, "url"),
+		Username:      cloud.String(cfg, "username"),
+		Token:         cloud.String(cfg, "token"),
+		AssigneeField: cloud.String(cfg, "assignee_field"),
+	}
+	if client.BaseURL == "" {
+		return nil, errors.New("jira sink requires url")
+	}
+	s := NewSink(name, client, NewMongoLinks(cloud.String(cfg, "collection")))
+	s.Project = cloud.String(cfg, "project")
+	s.IssueType = cloud.FirstString(cloud.String(cfg, "issue_type"), s.IssueType)
+	if v := cloud.String(cfg, "min_severity"); v != "" {
+		if s.MinSeverity = finding.ParseSeverity(v); s.MinSeverity == "" {
+			return nil, fmt.Errorf("unknown min_severity %q", v)
+		}
+	}
+	if tags := cloud.Strings(cfg, "owner_tags"); len(tags) > 0 {
+		s.OwnerTags = tags
+	}
+	s.Priorities = cloud.Tags(cloud.AnyCase(cfg, "priorities"))
+	s.Labels = cloud.Strings(cfg, "labels")
+	s.ResolveTransition = cloud.FirstString(cloud.String(cfg, "resolve_transition"), s.ResolveTransition)
+	s.ReopenTransition = cloud.String(cfg, "reopen_transition")
+	if s.Project == "" {
+		return nil, errors.New("jira sink requires project")
+	}
+	return s, nil
+}
+
+// NewSink 使用任意工单客户端与关联存储创建 sink
+func NewSink(name string, client Client, links Links) *Sink {
+	return &Sink{
+		Client:            client,
+		Links:             links,
+		IssueType:         "Task",
+		MinSeverity:       finding.SeverityCritical,
+		OwnerTags:         defaultOwnerTags,
+		ResolveTransition: "Done",
+		name:              name,
+	}
+}
+
+func (s *Sink) Name() string { return s.name }
+
+// Direct 工单需要逐条处理，摘要模式下不合并
+func (s *Sink) Direct() bool { return true }
+
+// Send 按问题的生命周期状态建单、评论或流转，不带问题的消息（如摘要）忽略
+func (s *Sink) Send(ctx context.Context, msg *notify.Message) error {
+	f := msg.Finding
+	if f == nil {
+		return nil
+	}
+	id := finding.Key(s.name, f.ID)
+	link, err := s.Links.Get(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+
+	switch {
+	case link == nil:
+		if f.State == finding.StateResolved || f.Severity.Rank() < s.MinSeverity.Rank() {
+			return nil
+		}
+		return s.create(ctx, id, f, msg, now)
+	case f.State == finding.StateResolved:
+		if link.Resolved {
+			return nil
+		}
+		if err := s.Client.Comment(ctx, link.Key, fmt.Sprintf("Exposure resolved: %s is no longer detected (last seen %s).",
+			f.Target, f.DetectedAt.Format(time.RFC3339))); err != nil {
+			return err
+		}
+		if s.ResolveTransition != "" {
+			if err := s.Client.Transition(ctx, link.Key, s.ResolveTransition); err != nil {
+				return err
+			}
+		}
+		link.Resolved = true
+	case f.State == finding.StateReopened:
+		if err := s.Client.Comment(ctx, link.Key, "Exposure detected again:\n"+msg.Text); err != nil {
+			return err
+		}
+		if link.Resolved && s.ReopenTransition != "" {
+			if err := s.Client.Transition(ctx, link.Key, s.ReopenTransition); err != nil {
+				return err
+			}
+		}
+		link.Resolved = false
+	case f.State == finding.StateAcknowledged:
+		if err := s.Client.Comment(ctx, link.Key, "Finding acknowledged."); err != nil {
+			return err
+		}
+	default:
+		// 未开启生命周期时每次扫描都会收到同一问题，已建单则不再重复处理
+		return nil
+	}
+	link.UpdatedAt = now
+	return s.Links.Put(link)
+}
+
+// create 建单并保存关联，经办人被拒绝时不带经办人重试一次。关联保存失败时工单已创建，只记录日志避免重试时重复建单
+func (s *Sink) create(ctx context.Context, id string, f *finding.Finding, msg *notify.Message, now time.Time) error {
+	issue := &Issue{
+		Project:     s.Project,
+		Type:        s.IssueType,
+		Summary:     msg.Title,
+		Description: msg.Text,
+		Assignee:    s.owner(f),
+		Priority:    s.Priorities[string(f.Severity)],
+		Labels:      cloud.AppendUnique(append([]string(nil), s.Labels...), f.Rule),
+	}
+	key, err := s.Client.Create(ctx, issue)
+	if errors.Is(err, ErrInvalidAssignee) {
+		// 标签中的负责人不一定是工单系统的用户，去掉经办人重试一次，负责人写入描述
+		logx.Warnf("%v, retry creating ticket for finding %s without assignee", err, f.ID)
+		issue.Description = strings.TrimSpace(issue.Description + "\n\nOwner: " + issue.Assignee)
+		issue.Assignee = ""
+		key, err = s.Client.Create(ctx, issue)
+	}
+	if err != nil {
+		return err
+	}
+	logx.Infof("created ticket %s for finding %s (%s)", key, f.ID, f.Rule)
+	link := &Link{ID: id, Sink: s.name, FindingID: f.ID, Key: key, CreatedAt: now, UpdatedAt: now}
+	if err := s.Links.Put(link); err != nil {
+		logx.Errorf("save ticket link %s -> %s error: %v", f.ID, key, err)
+	}
+	return nil
+}
+
+// owner 优先使用归属解析得到的经办人，其次按 OwnerTags 顺序取第一个非空的标签值
+func (s *Sink) owner(f *finding.Finding) string {
+	if f.Owner != nil {
+		if v := cloud.FirstString(f.Owner.Assignee, f.Owner.Owner); v != "" {
+			return v
+		}
+	}
+	for _, name := range s.OwnerTags {
+		for k, v := range f.Tags {
+			if strings.EqualFold(k, name) && v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,176 @@
+package ticket
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+	"github.com/xid-protocol/attack-surface/notify"
+)
+
+// memLinks 内存中的关联存储
+type memLinks map[string]Link
+
+func (m memLinks) Get(id string) (*Link, error) {
+	l, ok := m[id]
+	if !ok {
+		return nil, nil
+	}
+	return &l, nil
+}
+
+func (m memLinks) Put(l *Link) error {
+	m[l.ID] = *l
+	return nil
+}
+
+func newTestSink(t *testing.T) (*Sink, *jiraMock, memLinks) {
+	m := newJiraMock(t)
+	links := memLinks{}
+	m.users = map[string]bool{"alice": true}
+	s := NewSink("jira", m.client(), links)
+	s.Project = "SEC"
+	s.Priorities = map[string]string{"critical": "Highest"}
+	s.Labels = []string{"attack-surface"}
+	s.ResolveTransition = "Done"
+	s.ReopenTransition = "Reopen Issue"
+	return s, m, links
+}
+
+func testFinding(severity finding.Severity, state finding.State) *finding.Finding {
+	a := &cloud.Asset{Provider: "aws", InstanceID: "i-1", Tags: map[string]string{"Owner": "alice"}}
+	f := finding.New("exposed-port", severity, a, "tcp/6379", "Redis exposed", "")
+	f.State = state
+	return f
+}
+
+func send(t *testing.T, s *Sink, f *finding.Finding) {
+	t.Helper()
+	if err := s.Send(context.Background(), &notify.Message{Title: f.Title, Text: "tcp/6379 open to 0.0.0.0/0", Finding: f}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSinkLifecycle(t *testing.T) {
+	s, m, links := newTestSink(t)
+	f := testFinding(finding.SeverityCritical, finding.StateNew)
+
+	send(t, s, f)
+	issue := m.issue("SEC-1")
+	if issue == nil {
+		t.Fatal("no issue created")
+	}
+	if issue.Fields["summary"] != "Redis exposed" || issue.Fields["assignee"].(map[string]interface{})["accountId"] != "alice" ||
+		issue.Fields["priority"].(map[string]interface{})["name"] != "Highest" {
+		t.Fatalf("unexpected fields %v", issue.Fields)
+	}
+	if labels := issue.Fields["labels"].([]interface{}); len(labels) != 2 || labels[1] != "exposed-port" {
+		t.Fatalf("unexpected labels %v", labels)
+	}
+	id := finding.Key("jira", f.ID)
+	if l := links[id]; l.Key != "SEC-1" || l.Resolved {
+		t.Fatalf("unexpected link %+v", l)
+	}
+
+	// 未开启生命周期时同一问题重复出现，不重复建单
+	f.State = finding.StateOpen
+	send(t, s, f)
+	if len(m.issues) != 1 || len(issue.Comments) != 0 {
+		t.Fatalf("open finding produced %d issues, comments %v", len(m.issues), issue.Comments)
+	}
+
+	f.State = finding.StateAcknowledged
+	send(t, s, f)
+	if len(issue.Comments) != 1 || issue.Comments[0] != "Finding acknowledged." {
+		t.Fatalf("comments after acknowledge: %v", issue.Comments)
+	}
+
+	f.State = finding.StateResolved
+	send(t, s, f)
+	if issue.Status != "Done" || !links[id].Resolved || !strings.HasPrefix(issue.Comments[1], "Exposure resolved: tcp/6379") {
+		t.Fatalf("after resolve: status %s, link %+v, comments %v", issue.Status, links[id], issue.Comments)
+	}
+	// 重复的解决通知不再评论或流转
+	send(t, s, f)
+	if len(issue.Comments) != 2 {
+		t.Fatalf("duplicate resolve commented again: %v", issue.Comments)
+	}
+
+	f.State = finding.StateReopened
+	send(t, s, f)
+	if issue.Status != "Open" || links[id].Resolved || !strings.HasPrefix(issue.Comments[2], "Exposure detected again:") {
+		t.Fatalf("after reopen: status %s, link %+v, comments %v", issue.Status, links[id], issue.Comments)
+	}
+	if len(m.issues) != 1 {
+		t.Fatalf("lifecycle created %d issues, want 1", len(m.issues))
+	}
+}
+
+func TestSinkUnknownAssignee(t *testing.T) {
+	s, m, links := newTestSink(t)
+	f := testFinding(finding.SeverityCritical, finding.StateNew)
+	f.Tags = map[string]string{"owner": "platform-team"}
+	send(t, s, f)
+	issue := m.issue("SEC-1")
+	if issue == nil {
+		t.Fatal("no issue created after assignee was rejected")
+	}
+	if _, ok := issue.Fields["assignee"]; ok {
+		t.Fatalf("assignee still set: %v", issue.Fields)
+	}
+	if desc := issue.Fields["description"].(string); !strings.HasSuffix(desc, "Owner: platform-team") {
+		t.Fatalf("owner missing from description %q", desc)
+	}
+	if links[finding.Key("jira", f.ID)].Key != "SEC-1" {
+		t.Fatal("link not saved")
+	}
+}
+
+func TestSinkReopenWithoutTransition(t *testing.T) {
+	s, m, _ := newTestSink(t)
+	s.ReopenTransition = ""
+	f := testFinding(finding.SeverityCritical, finding.StateNew)
+	send(t, s, f)
+	f.State = finding.StateResolved
+	send(t, s, f)
+	f.State = finding.StateReopened
+	send(t, s, f)
+	// 未配置重新打开的流转时只评论，工单保持已解决
+	if issue := m.issue("SEC-1"); issue.Status != "Done" || len(issue.Comments) != 2 {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+}
+
+func TestSinkSkips(t *testing.T) {
+	s, m, links := newTestSink(t)
+	for _, f := range []*finding.Finding{
+		testFinding(finding.SeverityHigh, finding.StateNew),
+		testFinding(finding.SeverityCritical, finding.StateResolved),
+	} {
+		send(t, s, f)
+	}
+	if err := s.Send(context.Background(), &notify.Message{Title: "digest", Text: "3 findings"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.issues) != 0 || len(links) != 0 {
+		t.Fatalf("created %d issues, %d links", len(m.issues), len(links))
+	}
+}
+
+func TestSinkTransitionError(t *testing.T) {
+	s, _, links := newTestSink(t)
+	s.ResolveTransition = "Close Issue"
+	f := testFinding(finding.SeverityCritical, finding.StateNew)
+	send(t, s, f)
+	f.State = finding.StateResolved
+	err := s.Send(context.Background(), &notify.Message{Finding: f})
+	if err == nil || !strings.Contains(err.Error(), "Close Issue") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// 流转失败时关联保持未解决，下次通知会重试
+	if links[finding.Key("jira", f.ID)].Resolved {
+		t.Fatal("link marked resolved after failed transition")
+	}
+}
@@ -0,0 +1,301 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"golang.org/x/time/rate"
+)
+
+// 宽端口范围（如全部端口放行）只探测这些常见端口
+var commonPorts = []int{
+	21, 22, 23, 25, 53, 80, 110, 135, 139, 143, 389, 443, 445, 465, 587, 636, 993, 995,
+	1433, 1521, 2049, 2375, 2376, 3000, 3306, 3389, 5000, 5432, 5601, 5900, 5984, 6379,
+	6443, 7001, 8000, 8080, 8081, 8443, 8888, 9000, 9090, 9200, 9300, 10250, 11211, 15672, 27017,
+}
+
+type Config struct {
+	// Scope 允许探测的 CIDR，必填，范围外的地址不会发起连接
+	Scope []string
+	// Timeout 单次连接超时
+	Timeout time.Duration
+	// Rate 全局每秒最多发起的连接数
+	Rate int
+	// Workers 全局并发数
+	Workers int
+	// PerHost 单个目标 IP 的并发上限
+	PerHost int
+	// MaxRange 端口范围超过该长度时只探测常见端口
+	MaxRange int
+}
+
+// ConfigFromViper 读取 Verify 配置段，未配置的项使用保守的默认值
+func ConfigFromViper() Config {
+	cfg := Config{
+		Scope:    viper.GetStringSlice("Verify.scope"),
+		Timeout:  time.Duration(viper.GetInt("Verify.timeout_ms")) * time.Millisecond,
+		Rate:     viper.GetInt("Verify.rate"),
+		Workers:  viper.GetInt("Verify.workers"),
+		PerHost:  viper.GetInt("Verify.per_host"),
+		MaxRange: viper.GetInt("Verify.max_range"),
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 3 * time.Second
+	}
+	if cfg.Rate <= 0 {
+		cfg.Rate = 50
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 32
+	}
+	if cfg.PerHost <= 0 {
+		cfg.PerHost = 4
+	}
+	if cfg.MaxRange <= 0 {
+		cfg.MaxRange = 32
+	}
+	return cfg
+}
+
+// Target 待探测的 IP 与端口
+type Target struct {
+	IP   string
+	Port int
+}
+
+type Verifier struct {
+	cfg     Config
+	scope   []netip.Prefix
+	limiter *rate.Limiter
+	dialer  *net.Dialer
+
+	mu    sync.Mutex
+	hosts map[string]chan struct{}
+}
+
+// NewVerifier 创建探测器，scope 为空或无法解析时返回错误
+func NewVerifier(cfg Config) (*Verifier, error) {
+	if len(cfg.Scope) == 0 {
+		return nil, errors.New("verify scope is required")
+	}
+	v := &Verifier{
+		cfg:     cfg,
+		limiter: rate.NewLimiter(rate.Limit(cfg.Rate), 1),
+		dialer:  &net.Dialer{Timeout: cfg.Timeout},
+		hosts:   map[string]chan struct{}{},
+	}
+	for _, s := range cfg.Scope {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			addr, aerr := netip.ParseAddr(s)
+			if aerr != nil {
+				return nil, fmt.Errorf("invalid verify scope %q: %w", s, err)
+			}
+			p = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		v.scope = append(v.scope, p.Masked())
+	}
+	return v, nil
+}
+
+// InScope 判断地址是否在允许探测的范围内
+func (v *Verifier) InScope(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range v.scope {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check 并发探测目标，范围外的目标被跳过，结果按 IP、端口排序
+func (v *Verifier) Check(ctx context.Context, targets []Target) []cloud.PortCheck {
+	jobs := make(chan Target)
+	results := make(chan cloud.PortCheck)
+	var wg sync.WaitGroup
+	for i := 0; i < v.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range jobs {
+				if r, ok := v.probe(ctx, t); ok {
+					results <- r
+				}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, t := range targets {
+			if !v.InScope(t.IP) {
+				continue
+			}
+			select {
+			case jobs <- t:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var out []cloud.PortCheck
+	for r := range results {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].IP != out[j].IP {
+			return out[i].IP < out[j].IP
+		}
+		return out[i].Port < out[j].Port
+	})
+	return out
+}
+
+// Acquire 占用目标 IP 的并发名额并等待全局速率，成功后需调用 release 归还名额；
+// 服务识别等后续连接也经此限速，与端口探测共用上限
+func (v *Verifier) Acquire(ctx context.Context, ip string) (release func(), err error) {
+	sem := v.hostSemaphore(ip)
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if err := v.limiter.Wait(ctx); err != nil {
+		<-sem
+		return nil, err
+	}
+	return func() { <-sem }, nil
+}
+
+func (v *Verifier) probe(ctx context.Context, t Target) (cloud.PortCheck, bool) {
+	release, err := v.Acquire(ctx, t.IP)
+	if err != nil {
+		return cloud.PortCheck{}, false
+	}
+	defer release()
+
+	r := cloud.PortCheck{IP: t.IP, Port: t.Port, Protocol: "tcp", CheckedAt: time.Now().UTC()}
+	start := time.Now()
+	conn, err := v.dialer.DialContext(ctx, "tcp", net.JoinHostPort(t.IP, strconv.Itoa(t.Port)))
+	r.LatencyMs = time.Since(start).Milliseconds()
+	switch {
+	case err == nil:
+		conn.Close()
+		r.State = cloud.PortOpen
+	case errors.Is(err, syscall.ECONNREFUSED):
+		r.State = cloud.PortClosed
+	default:
+		// 超时、不可达等均视为被过滤
+		r.State = cloud.PortFiltered
+		r.Error = err.Error()
+	}
+	return r, true
+}
+
+func (v *Verifier) hostSemaphore(ip string) chan struct{} {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	sem, ok := v.hosts[ip]
+	if !ok {
+		sem = make(chan struct{}, v.cfg.PerHost)
+		v.hosts[ip] = sem
+	}
+	return sem
+}
+
+// VerifyAssets 探测资产公网地址上按规则对外开放的 TCP 端口，结果写回 Asset.Verification
+func (v *Verifier) VerifyAssets(ctx context.Context, assets []*cloud.Asset) {
+	var targets []Target
+	owners := map[Target][]*cloud.Asset{}
+	var skipped int
+	for _, a := range assets {
+		if a.Exposure == cloud.ExposureNone || a.Exposure == cloud.ExposurePrivate {
+			continue
+		}
+		ports := v.Ports(a)
+		for _, ip := range append(append([]string{}, a.PublicIPs...), a.IPv6s...) {
+			if !v.InScope(ip) {
+				skipped++
+				continue
+			}
+			for _, p := range ports {
+				t := Target{IP: ip, Port: p}
+				if _, ok := owners[t]; !ok {
+					targets = append(targets, t)
+				}
+				owners[t] = append(owners[t], a)
+			}
+		}
+	}
+
+	results := v.Check(ctx, targets)
+	var open int
+	for _, r := range results {
+		if r.State == cloud.PortOpen {
+			open++
+		}
+		for _, a := range owners[Target{IP: r.IP, Port: r.Port}] {
+			a.Verification = append(a.Verification, r)
+		}
+	}
+	logx.Infof("verify summary: targets=%d, open=%d, outOfScopeIPs=%d", len(targets), open, skipped)
+}
+
+// Ports 返回资产对公网放行的 TCP 端口，过宽的范围收敛为常见端口
+func (v *Verifier) Ports(a *cloud.Asset) []int {
+	seen := map[int]struct{}{}
+	add := func(p int) {
+		if p > 0 && p <= 65535 {
+			seen[p] = struct{}{}
+		}
+	}
+	for _, r := range a.Rules {
+		if r.Exposure == cloud.ExposureNone || r.Exposure == cloud.ExposurePrivate {
+			continue
+		}
+		if r.Protocol != "tcp" && r.Protocol != "6" && r.Protocol != "-1" && r.Protocol != "all" {
+			continue
+		}
+		from, to := r.FromPort, r.ToPort
+		if (r.Protocol == "-1" || r.Protocol == "all") && from <= 0 && to <= 0 {
+			from, to = 0, 65535
+		}
+		if to-from+1 > v.cfg.MaxRange {
+			for _, p := range commonPorts {
+				if p >= from && p <= to {
+					add(p)
+				}
+			}
+			continue
+		}
+		for p := from; p <= to; p++ {
+			add(p)
+		}
+	}
+	out := make([]int, 0, len(seen))
+	for p := range seen {
+		out = append(out, p)
+	}
+	sort.Ints(out)
+	return out
+}
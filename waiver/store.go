@@ -0,0 +1,85 @@
+package waiver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+	"github.com/xid-protocol/xidp/protocols"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const PathWaiver = "/protocols/external-attack-surface/waiver"
+
+// 默认的豁免集合
+const defaultCollection = "attack_surface_waiver"
+
+// Store 豁免记录存储，每条豁免为一个 XID
+type Store struct {
+	*cloud.Store
+}
+
+// NewStoreFromViper 集合名读取 Waiver.collection
+func NewStoreFromViper() *Store {
+	return &Store{Store: cloud.NewStore(cloud.FirstString(viper.GetString("Waiver.collection"), defaultCollection))}
+}
+
+// List 返回全部豁免，格式不正确的记录跳过
+func (s *Store) List() ([]*Waiver, error) {
+	records, err := s.ListByPath(PathWaiver)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", PathWaiver, err)
+	}
+	var out []*Waiver
+	for _, record := range records {
+		w, err := decode(cloud.PayloadOf(record))
+		if err != nil {
+			logx.Errorf("decode waiver error: %v", err)
+			continue
+		}
+		if err := w.Validate(); err != nil {
+			logx.Errorf("skip waiver %s: %v", w.ID, err)
+			continue
+		}
+		out = append(out, w)
+	}
+	return out, nil
+}
+
+// Save 新建或更新豁免，未指定 ID 时按范围与创建时间生成
+func (s *Store) Save(w *Waiver) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = time.Now().UTC()
+	}
+	if w.ID == "" {
+		w.ID = finding.Key(fmt.Sprintf("%+v", w.Scope), w.CreatedAt.Format(time.RFC3339Nano))
+	}
+	return s.DBClient.Upsert(s.Ctx, cloud.NewAttackSurfaceXID(w.ID, "waiver", PathWaiver, w))
+}
+
+// Delete 软删除豁免
+func (s *Store) Delete(id string) error {
+	return s.DBClient.DeleteSoft(s.Ctx, PathWaiver, protocols.GenerateXid(id))
+}
+
+// decode 经 BSON 往返把 payload 还原为豁免结构
+func decode(payload map[string]interface{}) (*Waiver, error) {
+	if payload == nil {
+		return nil, fmt.Errorf("empty payload")
+	}
+	b, err := bson.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var w Waiver
+	if err := bson.Unmarshal(b, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
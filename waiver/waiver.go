@@ -0,0 +1,186 @@
+package waiver
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/colin-404/logx"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// Scope 豁免范围，所有非空字段都需匹配；Rules 为空时匹配任意规则，支持 "tls-*" 形式的前缀
+type Scope struct {
+	Provider   string            `json:"provider,omitempty" bson:"provider,omitempty"`
+	Account    string            `json:"account,omitempty" bson:"account,omitempty"`
+	InstanceID string            `json:"instanceId,omitempty" bson:"instanceId,omitempty"`
+	GroupID    string            `json:"groupId,omitempty" bson:"groupId,omitempty"`
+	Port       int               `json:"port,omitempty" bson:"port,omitempty"`
+	CIDR       string            `json:"cidr,omitempty" bson:"cidr,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty" bson:"tags,omitempty"`
+	Rules      []string          `json:"rules,omitempty" bson:"rules,omitempty"`
+}
+
+// Waiver 风险接受记录，过期后命中的问题重新打开
+// path /protocols/external-attack-surface/waiver
+type Waiver struct {
+	ID            string    `json:"id" bson:"id"`
+	Scope         Scope     `json:"scope" bson:"scope"`
+	Justification string    `json:"justification" bson:"justification"`
+	Approver      string    `json:"approver" bson:"approver"`
+	Ticket        string    `json:"ticket,omitempty" bson:"ticket,omitempty"`
+	ExpiresAt     time.Time `json:"expiresAt" bson:"expiresAt"`
+	CreatedBy     string    `json:"createdBy,omitempty" bson:"createdBy,omitempty"`
+	CreatedAt     time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+// Validate 豁免必须限定范围并写明理由、审批人与过期时间
+func (w *Waiver) Validate() error {
+	s := w.Scope
+	if s.Provider == "" && s.Account == "" && s.InstanceID == "" && s.GroupID == "" && s.Port == 0 && s.CIDR == "" && len(s.Tags) == 0 && len(s.Rules) == 0 {
+		return errors.New("waiver scope is empty")
+	}
+	if s.CIDR != "" && !validCIDR(s.CIDR) {
+		return errors.New("invalid waiver cidr: " + s.CIDR)
+	}
+	if strings.TrimSpace(w.Justification) == "" {
+		return errors.New("waiver justification is required")
+	}
+	if strings.TrimSpace(w.Approver) == "" {
+		return errors.New("waiver approver is required")
+	}
+	if w.ExpiresAt.IsZero() {
+		return errors.New("waiver expiry is required")
+	}
+	return nil
+}
+
+// Active 是否在有效期内
+func (w *Waiver) Active(now time.Time) bool {
+	return now.Before(w.ExpiresAt)
+}
+
+// Matches 判断问题是否落在豁免范围内
+func (s Scope) Matches(f *finding.Finding) bool {
+	if s.Provider != "" && !strings.EqualFold(s.Provider, f.Provider) {
+		return false
+	}
+	if s.Account != "" && s.Account != cloud.FirstString(f.Account, cloud.AccountOf(f.ResourceID)) {
+		return false
+	}
+	if s.InstanceID != "" && s.InstanceID != f.ResourceID && s.InstanceID != f.ResourceName {
+		return false
+	}
+	if s.GroupID != "" && s.GroupID != f.GroupID {
+		return false
+	}
+	if s.Port != 0 && !coversPort(f, s.Port) {
+		return false
+	}
+	if s.CIDR != "" && !coversAddress(s.CIDR, f) {
+		return false
+	}
+	for k, v := range s.Tags {
+		tv, ok := f.Tags[k]
+		if !ok || (v != "*" && v != tv) {
+			return false
+		}
+	}
+	if len(s.Rules) > 0 && !finding.MatchRule(s.Rules, f.Rule) {
+		return false
+	}
+	return true
+}
+
+// Apply 按豁免更新问题状态：命中有效豁免为 waived，仅命中过期豁免为 reopened，其余为 open
+// 同时命中多个豁免时取过期时间最晚的一个
+func Apply(findings []*finding.Finding, waivers []*Waiver, now time.Time) {
+	var waived, reopened int
+	for _, f := range findings {
+		var active, expired *Waiver
+		for _, w := range waivers {
+			if !w.Scope.Matches(f) {
+				continue
+			}
+			if w.Active(now) {
+				if active == nil || w.ExpiresAt.After(active.ExpiresAt) {
+					active = w
+				}
+			} else if expired == nil || w.ExpiresAt.After(expired.ExpiresAt) {
+				expired = w
+			}
+		}
+		switch {
+		case active != nil:
+			f.Status, f.Waiver = finding.StatusWaived, ref(active)
+			waived++
+		case expired != nil:
+			f.Status, f.Waiver = finding.StatusReopened, ref(expired)
+			reopened++
+		default:
+			f.Status, f.Waiver = finding.StatusOpen, nil
+		}
+	}
+	logx.Infof("waiver summary: waivers=%d, findings=%d, waived=%d, reopened=%d", len(waivers), len(findings), waived, reopened)
+}
+
+func ref(w *Waiver) *finding.WaiverRef {
+	return &finding.WaiverRef{ID: w.ID, Approver: w.Approver, Ticket: w.Ticket, ExpiresAt: w.ExpiresAt}
+}
+
+// coversPort 问题的端口范围需完全落在豁免端口内，即只放行该端口；
+// 包含该端口的更大范围（如全端口 0-65535、协议 -1 的 0-0）不会被单个端口的豁免覆盖
+func coversPort(f *finding.Finding, port int) bool {
+	if f.FromPort <= 0 && f.ToPort <= 0 {
+		return false
+	}
+	return f.FromPort == port && f.ToPort == port
+}
+
+// coversAddress 来源地址全部落在 CIDR 内，或问题目标地址落在 CIDR 内
+func coversAddress(cidr string, f *finding.Finding) bool {
+	if len(f.Sources) > 0 {
+		all := true
+		for _, src := range f.Sources {
+			if !cloud.CIDRCovers(cidr, src) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	if ip := targetIP(f.Target); ip != "" {
+		return cloud.CIDRCovers(cidr, ip)
+	}
+	return false
+}
+
+// targetIP 从 ip:port、URL 或 "sg-1 tcp/22-22" 形式的目标中取出 IP
+func targetIP(target string) string {
+	host := target
+	if strings.Contains(target, "://") {
+		if u, err := url.Parse(target); err == nil {
+			host = u.Hostname()
+		}
+	} else if h, _, err := net.SplitHostPort(target); err == nil {
+		host = h
+	}
+	if _, err := netip.ParseAddr(host); err != nil {
+		return ""
+	}
+	return host
+}
+
+func validCIDR(s string) bool {
+	if _, err := netip.ParsePrefix(s); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddr(s)
+	return err == nil
+}
@@ -0,0 +1,59 @@
+package webscan
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// Evaluate 命中的敏感路径逐条告警，缺失的安全响应头合并为一条
+func (s *Scanner) Evaluate(a *cloud.Asset, ws cloud.WebSurface) []*finding.Finding {
+	var out []*finding.Finding
+	port := portOf(ws.FinalURL)
+	for _, p := range ws.Paths {
+		sev := finding.ParseSeverity(p.Severity)
+		if sev == "" {
+			sev = finding.SeverityMedium
+		}
+		target := p.Path
+		if u, err := url.Parse(ws.FinalURL); err == nil {
+			target = (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: p.Path}).String()
+		}
+		f := finding.New(RuleSensitivePath, sev, a, target, "Sensitive web path exposed",
+			fmt.Sprintf("%s responded with status %d", target, p.Status))
+		f.FromPort, f.ToPort = port, port
+		f.Evidence["status"] = strconv.Itoa(p.Status)
+		f.Evidence["length"] = strconv.Itoa(p.Length)
+		if ws.Server != "" {
+			f.Evidence["server"] = ws.Server
+		}
+		out = append(out, f)
+	}
+	if len(ws.MissingHeaders) > 0 && ws.Status >= 200 && ws.Status < 400 {
+		f := finding.New(RuleMissingHeaders, finding.SeverityLow, a, ws.FinalURL, "Missing HTTP security headers",
+			"missing "+strings.Join(ws.MissingHeaders, ", "))
+		f.FromPort, f.ToPort = port, port
+		f.Evidence["missing"] = strings.Join(ws.MissingHeaders, ",")
+		out = append(out, f)
+	}
+	return out
+}
+
+// portOf 取 URL 中的端口，未写明时按协议默认端口
+func portOf(raw string) int {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return 0
+	}
+	if p, err := strconv.Atoi(u.Port()); err == nil {
+		return p
+	}
+	if u.Scheme == "https" {
+		return 443
+	}
+	return 80
+}
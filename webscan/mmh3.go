@@ -0,0 +1,59 @@
+package webscan
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"math/bits"
+	"strings"
+)
+
+// faviconHash 与 Shodan http.favicon.hash 一致：按 76 字符换行的 base64 编码后计算 mmh3 32 位有符号值
+func faviconHash(data []byte) int32 {
+	enc := base64.StdEncoding.EncodeToString(data)
+	var b strings.Builder
+	for i := 0; i < len(enc); i += 76 {
+		end := min(i+76, len(enc))
+		b.WriteString(enc[i:end])
+		b.WriteByte('\n')
+	}
+	return int32(murmur3([]byte(b.String()), 0))
+}
+
+// murmur3 MurmurHash3 x86 32 位实现
+func murmur3(data []byte, seed uint32) uint32 {
+	const c1, c2 = 0xcc9e2d51, 0x1b873593
+	h := seed
+	n := len(data) / 4
+	for i := 0; i < n; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+	tail := data[n*4:]
+	var k uint32
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
@@ -0,0 +1,396 @@
+package webscan
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/colin-404/logx"
+	"github.com/spf13/viper"
+	"github.com/xid-protocol/attack-surface/cloud"
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// Web 相关的问题规则
+const (
+	RuleSensitivePath  = "web-sensitive-path"
+	RuleMissingHeaders = "web-missing-security-headers"
+)
+
+// 未做服务识别时按端口判断是否为 Web 服务，值为优先尝试的协议
+var webPorts = map[int]string{
+	80: "http", 81: "http", 3000: "http", 5000: "http", 5601: "http", 8000: "http", 8008: "http",
+	8080: "http", 8081: "http", 8888: "http", 9000: "http", 9090: "http", 9200: "http",
+	443: "https", 6443: "https", 8443: "https", 9443: "https",
+}
+
+// securityHeaders 记录的安全响应头，HSTS 只对 https 要求
+var securityHeaders = []string{
+	"Strict-Transport-Security",
+	"Content-Security-Policy",
+	"X-Frame-Options",
+	"X-Content-Type-Options",
+}
+
+var (
+	titleRe   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	linkRe    = regexp.MustCompile(`(?is)<link\s[^>]*>`)
+	relIconRe = regexp.MustCompile(`(?i)rel\s*=\s*["']?[^"'>]*icon`)
+	hrefRe    = regexp.MustCompile(`(?i)href\s*=\s*["']?([^"'\s>]+)`)
+)
+
+// 单个响应最多读取的字节数
+const maxBody = 256 << 10
+
+type Scanner struct {
+	Timeout      time.Duration
+	Workers      int
+	MaxRedirects int
+	Wordlist     []Entry
+}
+
+// NewScannerFromViper 读取 Webscan 配置段，wordlist 未配置或读取失败时使用内置字典
+func NewScannerFromViper() *Scanner {
+	s := &Scanner{
+		Timeout:      time.Duration(viper.GetInt("Webscan.timeout_ms")) * time.Millisecond,
+		Workers:      viper.GetInt("Webscan.workers"),
+		MaxRedirects: viper.GetInt("Webscan.max_redirects"),
+		Wordlist:     defaultWordlist,
+	}
+	if path := viper.GetString("Webscan.wordlist"); path != "" {
+		entries, err := LoadWordlist(path)
+		if err != nil {
+			logx.Errorf("load webscan wordlist %s error: %v", path, err)
+		} else {
+			s.Wordlist = entries
+		}
+	}
+	if s.Timeout <= 0 {
+		s.Timeout = 5 * time.Second
+	}
+	if s.Workers <= 0 {
+		s.Workers = 8
+	}
+	if s.MaxRedirects <= 0 {
+		s.MaxRedirects = 5
+	}
+	return s
+}
+
+// ScanAssets 探测已验证开放的 HTTP(S) 端口，结果写回 Asset.Web 并返回发现的问题
+func (s *Scanner) ScanAssets(ctx context.Context, assets []*cloud.Asset) []*finding.Finding {
+	type job struct {
+		asset   *cloud.Asset
+		ip      string
+		port    int
+		schemes []string
+	}
+	jobs := make(chan job)
+	var out []*finding.Finding
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	var scanned int
+	for i := 0; i < s.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				host := hostFor(j.asset, j.ip)
+				var ws cloud.WebSurface
+				for _, scheme := range j.schemes {
+					ws = s.Scan(ctx, j.ip, j.port, scheme, host)
+					if ws.Error == "" {
+						break
+					}
+				}
+				if ws.Status == 0 {
+					continue
+				}
+				findings := s.Evaluate(j.asset, ws)
+				lock.Lock()
+				j.asset.Web = append(j.asset.Web, ws)
+				out = append(out, findings...)
+				scanned++
+				lock.Unlock()
+			}
+		}()
+	}
+	for _, a := range assets {
+		for _, c := range a.Verification {
+			if c.State != cloud.PortOpen {
+				continue
+			}
+			if schemes := webSchemes(a, c); len(schemes) > 0 {
+				jobs <- job{asset: a, ip: c.IP, port: c.Port, schemes: schemes}
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	finding.Sort(out)
+	logx.Infof("web surface summary: endpoints=%d, findings=%d", scanned, len(out))
+	return out
+}
+
+// webSchemes 服务识别结果优先，未识别时按常见 Web 端口尝试 http/https
+func webSchemes(a *cloud.Asset, c cloud.PortCheck) []string {
+	for _, fp := range a.Fingerprints {
+		if fp.IP != c.IP || fp.Port != c.Port {
+			continue
+		}
+		switch fp.Service {
+		case "http", "https", "elasticsearch":
+			if fp.TLS {
+				return []string{"https"}
+			}
+			return []string{"http"}
+		}
+		return nil
+	}
+	switch webPorts[c.Port] {
+	case "http":
+		return []string{"http", "https"}
+	case "https":
+		return []string{"https", "http"}
+	}
+	return nil
+}
+
+// hostFor endpoint 为域名时作为 Host 头，否则直接使用 IP
+func hostFor(a *cloud.Asset, ip string) string {
+	if a.Endpoint != "" {
+		if _, err := netip.ParseAddr(a.Endpoint); err != nil {
+			return strings.ToLower(a.Endpoint)
+		}
+	}
+	return ip
+}
+
+// Scan 请求首页并跟随同主机的跳转，再采集 favicon 与字典路径
+// 连接始终发往 ip，跳转到其他主机时只记录不请求
+func (s *Scanner) Scan(ctx context.Context, ip string, port int, scheme, host string) cloud.WebSurface {
+	start := &url.URL{Scheme: scheme, Host: joinHost(scheme, host, port), Path: "/"}
+	ws := cloud.WebSurface{URL: start.String(), SecurityHeaders: map[string]string{}, CheckedAt: time.Now().UTC()}
+	client := s.client(ip)
+	defer client.CloseIdleConnections()
+
+	current := start
+	resp, body, err := s.get(ctx, client, current)
+	for err == nil && isRedirect(resp.StatusCode) && len(ws.RedirectChain) < s.MaxRedirects {
+		loc, perr := current.Parse(resp.Header.Get("Location"))
+		if perr != nil || resp.Header.Get("Location") == "" {
+			break
+		}
+		ws.RedirectChain = append(ws.RedirectChain, loc.String())
+		if !strings.EqualFold(loc.Hostname(), host) || (loc.Scheme != "http" && loc.Scheme != "https") {
+			break
+		}
+		next, nbody, nerr := s.get(ctx, client, loc)
+		if nerr != nil {
+			break
+		}
+		current, resp, body = loc, next, nbody
+	}
+	if err != nil {
+		ws.Error = err.Error()
+		return ws
+	}
+
+	ws.FinalURL = current.String()
+	ws.Status = resp.StatusCode
+	ws.Server = resp.Header.Get("Server")
+	ws.Title = extractTitle(body)
+	for _, h := range securityHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			ws.SecurityHeaders[h] = v
+		} else if h != "Strict-Transport-Security" || current.Scheme == "https" {
+			ws.MissingHeaders = append(ws.MissingHeaders, h)
+		}
+	}
+
+	base := &url.URL{Scheme: current.Scheme, Host: current.Host, Path: "/"}
+	if data := s.favicon(ctx, client, base, current, body); len(data) > 0 {
+		h := faviconHash(data)
+		sum := sha256.Sum256(data)
+		ws.FaviconHash = &h
+		ws.FaviconSHA256 = hex.EncodeToString(sum[:])
+	}
+	ws.Paths = s.probePaths(ctx, client, base)
+	return ws
+}
+
+// favicon 优先使用页面中声明的图标，同主机之外的地址不请求
+func (s *Scanner) favicon(ctx context.Context, client *http.Client, base, page *url.URL, body []byte) []byte {
+	candidates := []*url.URL{}
+	for _, link := range linkRe.FindAll(body, -1) {
+		if !relIconRe.Match(link) {
+			continue
+		}
+		if m := hrefRe.FindSubmatch(link); m != nil {
+			if u, err := page.Parse(string(m[1])); err == nil && u.Host == base.Host {
+				candidates = append(candidates, u)
+			}
+		}
+		break
+	}
+	candidates = append(candidates, base.ResolveReference(&url.URL{Path: "/favicon.ico"}))
+	for _, u := range candidates {
+		resp, data, err := s.get(ctx, client, u)
+		if err != nil || resp.StatusCode != http.StatusOK || len(data) == 0 {
+			continue
+		}
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+			continue
+		}
+		return data
+	}
+	return nil
+}
+
+// probePaths 逐个请求字典路径，先请求随机路径作为基线以排除统一返回 200 的站点
+func (s *Scanner) probePaths(ctx context.Context, client *http.Client, base *url.URL) []cloud.WebPath {
+	baseline, baseBody, err := s.get(ctx, client, base.ResolveReference(&url.URL{Path: "/" + randomPath()}))
+	if err != nil {
+		return nil
+	}
+	baseLen := bodyLength(baseline, baseBody)
+
+	var out []cloud.WebPath
+	for _, e := range s.Wordlist {
+		if ctx.Err() != nil {
+			break
+		}
+		u, err := base.Parse(e.Path)
+		if err != nil {
+			continue
+		}
+		resp, body, err := s.get(ctx, client, u)
+		if err != nil {
+			continue
+		}
+		length := bodyLength(resp, body)
+		hit := false
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			// 带 Match 的路径同样要求与基线不同，SPA 对任意路径返回同一页面时关键字也可能命中
+			hit = resp.StatusCode != baseline.StatusCode || !similarLength(length, baseLen)
+			if hit && e.Match != "" {
+				hit = strings.Contains(strings.ToLower(string(body)), strings.ToLower(e.Match))
+			}
+		case resp.StatusCode == http.StatusUnauthorized && e.Match == "":
+			// 需要认证的管理入口同样属于暴露面
+			hit = baseline.StatusCode != http.StatusUnauthorized
+		}
+		if hit {
+			out = append(out, cloud.WebPath{Path: e.Path, Status: resp.StatusCode, Length: length, Severity: string(e.Severity)})
+		}
+	}
+	return out
+}
+
+// client 所有连接都发往 ip，端口沿用请求地址中的端口，证书不做校验
+func (s *Scanner) client(ip string) *http.Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			_, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			d := net.Dialer{Timeout: s.Timeout}
+			return d.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		},
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		MaxIdleConns:    2,
+	}
+	return &http.Client{
+		Timeout:   s.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (s *Scanner) get(ctx context.Context, client *http.Client, u *url.URL) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", "attack-surface-webscan")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	return resp, body, nil
+}
+
+// joinHost 默认端口不写入 URL
+func joinHost(scheme, host string, port int) string {
+	if (scheme == "http" && port == 80) || (scheme == "https" && port == 443) {
+		if strings.Contains(host, ":") {
+			return "[" + host + "]"
+		}
+		return host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// extractTitle 取 <title> 内容并合并空白，最长 200 字符
+func extractTitle(body []byte) string {
+	m := titleRe.FindSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	title := strings.Join(strings.Fields(string(m[1])), " ")
+	if r := []rune(title); len(r) > 200 {
+		title = string(r[:200])
+	}
+	return title
+}
+
+func bodyLength(resp *http.Response, body []byte) int {
+	if resp.ContentLength > 0 {
+		return int(resp.ContentLength)
+	}
+	return len(body)
+}
+
+// similarLength 长度差在 10% 以内视为相同页面
+func similarLength(a, b int) bool {
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= max(a, b)/10
+}
+
+func randomPath() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("nonexistent-%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
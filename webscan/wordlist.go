@@ -0,0 +1,78 @@
+package webscan
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/xid-protocol/attack-surface/finding"
+)
+
+// Entry 探测路径，Match 非空时响应体须包含该内容才算命中，用于排除统一返回 200 的站点
+type Entry struct {
+	Path     string
+	Match    string
+	Severity finding.Severity
+}
+
+// defaultWordlist 常见的管理、调试与泄露路径
+var defaultWordlist = []Entry{
+	{Path: "/.git/HEAD", Match: "ref:", Severity: finding.SeverityHigh},
+	{Path: "/.git/config", Match: "[core]", Severity: finding.SeverityHigh},
+	{Path: "/.env", Match: "=", Severity: finding.SeverityHigh},
+	{Path: "/.svn/entries", Severity: finding.SeverityHigh},
+	{Path: "/.DS_Store", Severity: finding.SeverityLow},
+	{Path: "/actuator", Match: "_links", Severity: finding.SeverityMedium},
+	{Path: "/actuator/env", Match: "propertySources", Severity: finding.SeverityHigh},
+	{Path: "/actuator/heapdump", Severity: finding.SeverityCritical},
+	{Path: "/actuator/health", Match: "status", Severity: finding.SeverityLow},
+	{Path: "/env", Match: "propertySources", Severity: finding.SeverityHigh},
+	{Path: "/metrics", Match: "# HELP", Severity: finding.SeverityMedium},
+	{Path: "/debug/pprof/", Match: "profiles", Severity: finding.SeverityHigh},
+	{Path: "/debug/vars", Match: "memstats", Severity: finding.SeverityMedium},
+	{Path: "/server-status", Match: "Server Status", Severity: finding.SeverityMedium},
+	{Path: "/phpinfo.php", Match: "phpinfo()", Severity: finding.SeverityMedium},
+	{Path: "/swagger-ui.html", Match: "swagger", Severity: finding.SeverityLow},
+	{Path: "/swagger/index.html", Match: "swagger", Severity: finding.SeverityLow},
+	{Path: "/v2/api-docs", Match: "swagger", Severity: finding.SeverityLow},
+	{Path: "/v3/api-docs", Match: "openapi", Severity: finding.SeverityLow},
+	{Path: "/console", Severity: finding.SeverityMedium},
+	{Path: "/admin", Severity: finding.SeverityMedium},
+	{Path: "/manager/html", Severity: finding.SeverityHigh},
+	{Path: "/jmx-console", Severity: finding.SeverityHigh},
+	{Path: "/solr/", Match: "Solr", Severity: finding.SeverityMedium},
+	{Path: "/_cat/indices", Severity: finding.SeverityHigh},
+	{Path: "/graphql", Severity: finding.SeverityLow},
+}
+
+// LoadWordlist 读取路径字典，每行 "path [match] [severity]"，以 # 开头的行为注释
+func LoadWordlist(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []Entry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		e := Entry{Path: fields[0], Severity: finding.SeverityMedium}
+		if !strings.HasPrefix(e.Path, "/") {
+			e.Path = "/" + e.Path
+		}
+		if len(fields) > 1 && fields[1] != "-" {
+			e.Match = fields[1]
+		}
+		if len(fields) > 2 {
+			if sev := finding.ParseSeverity(fields[2]); sev != "" {
+				e.Severity = sev
+			}
+		}
+		out = append(out, e)
+	}
+	return out, sc.Err()
+}